@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIKeys authenticates requests by a static key sent in the X-API-Key header
+// or as "Authorization: ApiKey <key>".
+//
+// Keys are held by their SHA256 digest rather than in plain text. Looking up
+// a digest also means the comparison doesn't leak how many leading bytes of a
+// guessed key were right.
+type APIKeys struct {
+	owners map[[sha256.Size]byte]string
+}
+
+// NewAPIKeys builds an authenticator from a key -> owner map. The owner
+// becomes the Principal ID. It panics if a key is empty or only spaces,
+// since a header with no key would match it.
+func NewAPIKeys(keys map[string]string) *APIKeys {
+	a := &APIKeys{owners: make(map[[sha256.Size]byte]string, len(keys))}
+	for key, owner := range keys {
+		if strings.TrimSpace(key) == "" {
+			panic(fmt.Sprintf("auth: empty API key for %q", owner))
+		}
+		a.owners[sha256.Sum256([]byte(key))] = owner
+	}
+	return a
+}
+
+func (a *APIKeys) Authenticate(req *http.Request) (*Principal, error) {
+	key := req.Header.Get("X-API-Key")
+	if key == "" {
+		scheme, value, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "ApiKey") {
+			return nil, ErrNoCredentials
+		}
+		key = strings.TrimSpace(value)
+	}
+	owner, ok := a.owners[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, errors.New("invalid API key")
+	}
+	return &Principal{ID: owner, Method: "apikey"}, nil
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIKeys(t *testing.T) {
+	keys := NewAPIKeys(map[string]string{"k-123": "ann"})
+	var tests = []struct {
+		header, value string
+		owner         string
+		err           error // nil, ErrNoCredentials, or errAny for any other error
+	}{
+		{"X-API-Key", "k-123", "ann", nil},
+		{"Authorization", "ApiKey k-123", "ann", nil},
+		{"Authorization", "apikey  k-123 ", "ann", nil},
+		{"X-API-Key", "k-12", "", errAny},
+		{"Authorization", "ApiKey k-1234", "", errAny},
+		{"Authorization", "Bearer k-123", "", ErrNoCredentials},
+		{"", "", "", ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		p, err := keys.Authenticate(req)
+		checkErr(t, tt.header+": "+tt.value, err, tt.err)
+		if err == nil && (p.ID != tt.owner || p.Method != "apikey") {
+			t.Errorf("%s: %s: got %+v, want owner %s", tt.header, tt.value, p, tt.owner)
+		}
+	}
+}
+
+// errAny stands for "some error other than ErrNoCredentials" in test tables.
+var errAny = errors.New("any error")
+
+func checkErr(t *testing.T, name string, got, want error) {
+	t.Helper()
+	switch {
+	case want == nil && got != nil:
+		t.Errorf("%s: unexpected error %v", name, got)
+	case want == errAny && (got == nil || errors.Is(got, ErrNoCredentials)):
+		t.Errorf("%s: got %v, want a verification error", name, got)
+	case want != nil && want != errAny && !errors.Is(got, want):
+		t.Errorf("%s: got %v, want %v", name, got, want)
+	}
+}
+
+func TestAPIKeysRejectsEmptyKey(t *testing.T) {
+	for _, key := range []string{"", "  "} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewAPIKeys with key %q: no panic", key)
+				}
+			}()
+			NewAPIKeys(map[string]string{key: "ann"})
+		}()
+	}
+}
@@ -0,0 +1,88 @@
+// Package auth verifies who is calling an HTTP handler.
+//
+// HTTPServer.go's /headers handler echoes whatever headers arrive without
+// checking any of them. Middleware runs one or more Authenticators in front
+// of a handler instead: static API keys, HMAC-SHA256 signed requests and
+// HS256 JWTs. The first one that recognises the request's credentials
+// decides, and on success the caller's Principal is stored in the request
+// context for the handler to read with FromContext.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Principal is the authenticated caller.
+type Principal struct {
+	ID     string         // key owner, signing key ID or JWT subject
+	Method string         // "apikey", "hmac" or "jwt"
+	Claims map[string]any // JWT claims; nil for other methods
+}
+
+// ErrNoCredentials is returned by an Authenticator when the request carries
+// none of the credentials it understands, so the next one should be tried.
+var ErrNoCredentials = errors.New("auth: no credentials")
+
+// Authenticator checks one kind of credential.
+type Authenticator interface {
+	Authenticate(req *http.Request) (*Principal, error)
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal Middleware stored for the request.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
+
+// Middleware rejects requests that none of the authenticators accept with
+// 401 Unauthorized, and passes the rest to next with the principal attached
+// to their context. The 401 doesn't say what was wrong with the
+// credentials, which would help someone trying to forge them.
+func Middleware(next http.Handler, authenticators ...Authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(req)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				unauthorized(w, "invalid credentials")
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(WithPrincipal(req.Context(), p)))
+			return
+		}
+		unauthorized(w, "credentials required")
+	})
+}
+
+func unauthorized(w http.ResponseWriter, reason string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	http.Error(w, "unauthorized: "+reason, http.StatusUnauthorized)
+}
+
+// clock returns now() when set, time.Now otherwise. Authenticators take an
+// optional Now func so expiry can be checked against a fixed time.
+func clock(now func() time.Time) time.Time {
+	if now != nil {
+		return now()
+	}
+	return time.Now()
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type stubAuth struct {
+	p   *Principal
+	err error
+}
+
+func (s stubAuth) Authenticate(*http.Request) (*Principal, error) { return s.p, s.err }
+
+func TestMiddleware(t *testing.T) {
+	var tests = []struct {
+		name   string
+		auths  []Authenticator
+		status int
+		body   string
+	}{
+		{"none apply", []Authenticator{stubAuth{err: ErrNoCredentials}}, 401, "unauthorized: credentials required\n"},
+		{"falls through", []Authenticator{stubAuth{err: ErrNoCredentials}, stubAuth{p: &Principal{ID: "ann"}}}, 200, "ann"},
+		{"first decides", []Authenticator{stubAuth{p: &Principal{ID: "ann"}}, stubAuth{err: errors.New("boom")}}, 200, "ann"},
+		{"hides reason", []Authenticator{stubAuth{err: errors.New("token signature mismatch")}}, 401, "unauthorized: invalid credentials\n"},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		p, _ := FromContext(req.Context())
+		w.Write([]byte(p.ID))
+	})
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		Middleware(next, tt.auths...).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if rec.Code != tt.status || rec.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q, want %d %q", tt.name, rec.Code, rec.Body, tt.status, tt.body)
+		}
+		if tt.status == 401 && !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer") {
+			t.Errorf("%s: missing WWW-Authenticate", tt.name)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampHeader carries the Unix time a signed request was made at.
+const TimestampHeader = "X-Signature-Timestamp"
+
+// HMACSigner authenticates requests signed with a shared secret. A signed
+// request carries
+//
+//	Authorization: HMAC-SHA256 keyId="<id>", signature="<base64>"
+//	X-Signature-Timestamp: <unix seconds>
+//
+// where the signature is HMAC-SHA256 over the method, the request URI, the
+// timestamp and the hex SHA256 of the body, separated by newlines. Hashing the
+// body works just like the sha256.New / h.Write / h.Sum steps in
+// SHA256Hash.go.
+type HMACSigner struct {
+	// Secrets maps key IDs to their shared secrets.
+	Secrets map[string][]byte
+	// MaxSkew is how far the timestamp may be from the server's clock. It
+	// bounds how long a captured request can be replayed.
+	MaxSkew time.Duration
+	// MaxBody caps how much of the body is read to verify its hash.
+	MaxBody int64
+	Now     func() time.Time
+}
+
+// NewHMACSigner returns a signer allowing five minutes of clock skew and
+// bodies up to 10 MiB. It panics if a secret is empty: anyone can sign with
+// the empty key.
+func NewHMACSigner(secrets map[string][]byte) *HMACSigner {
+	for id, secret := range secrets {
+		if len(secret) == 0 {
+			panic(fmt.Sprintf("auth: empty HMAC secret for key ID %q", id))
+		}
+	}
+	return &HMACSigner{Secrets: secrets, MaxSkew: 5 * time.Minute, MaxBody: 10 << 20}
+}
+
+func (s *HMACSigner) Authenticate(req *http.Request) (*Principal, error) {
+	scheme, params, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "HMAC-SHA256") {
+		return nil, ErrNoCredentials
+	}
+	fields := parseParams(params)
+	keyID, sig := fields["keyId"], fields["signature"]
+	if keyID == "" || sig == "" {
+		return nil, errors.New("malformed HMAC authorization")
+	}
+	secret, ok := s.Secrets[keyID]
+	if !ok {
+		return nil, errors.New("unknown key ID")
+	}
+	if len(secret) == 0 {
+		// Secrets was changed after NewHMACSigner checked it.
+		return nil, errors.New("empty secret for key ID")
+	}
+	mac, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, errors.New("malformed signature")
+	}
+
+	ts := req.Header.Get(TimestampHeader)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, errors.New("missing or invalid " + TimestampHeader)
+	}
+	if abs(clock(s.Now).Sub(time.Unix(unix, 0))) > s.MaxSkew {
+		return nil, errors.New("request timestamp outside allowed skew")
+	}
+
+	bodyHash, err := hashBody(req, s.MaxBody)
+	if err != nil {
+		return nil, err
+	}
+	want := sign(secret, req.Method, req.URL.RequestURI(), ts, bodyHash)
+	if !hmac.Equal(mac, want) {
+		return nil, errors.New("signature mismatch")
+	}
+	return &Principal{ID: keyID, Method: "hmac"}, nil
+}
+
+// SignRequest adds the headers HMACSigner checks. It reads and restores the
+// request body, so call it after the body is set.
+func SignRequest(req *http.Request, keyID string, secret []byte, now time.Time) error {
+	bodyHash, err := hashBody(req, -1)
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	mac := sign(secret, req.Method, req.URL.RequestURI(), ts, bodyHash)
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set("Authorization", fmt.Sprintf(`HMAC-SHA256 keyId="%s", signature="%s"`,
+		keyID, base64.StdEncoding.EncodeToString(mac)))
+	return nil
+}
+
+func sign(secret []byte, method, uri, ts, bodyHash string) []byte {
+	h := hmac.New(sha256.New, secret)
+	io.WriteString(h, method+"\n"+uri+"\n"+ts+"\n"+bodyHash)
+	return h.Sum(nil)
+}
+
+// hashBody returns the hex SHA256 of the request body and puts an unread copy
+// back in its place. A negative limit means no limit.
+func hashBody(req *http.Request, limit int64) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	var r io.Reader = req.Body
+	if limit >= 0 {
+		r = io.LimitReader(req.Body, limit+1)
+	}
+	body, err := io.ReadAll(r)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	if limit >= 0 && int64(len(body)) > limit {
+		return "", errors.New("request body too large to verify")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parseParams splits `a="1", b="2"` into a map.
+func parseParams(s string) map[string]string {
+	m := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			m[strings.TrimSpace(k)] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	return m
+}
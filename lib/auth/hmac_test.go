@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	secret := []byte("s3cret")
+	s := NewHMACSigner(map[string][]byte{"svc": secret})
+	s.Now = func() time.Time { return now }
+	s.MaxBody = 16
+
+	signed := func(body string, at time.Time) *http.Request {
+		req := httptest.NewRequest("POST", "/orders?id=7", strings.NewReader(body))
+		if err := SignRequest(req, "svc", secret, at); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	var tests = []struct {
+		name string
+		req  func() *http.Request
+		err  error
+	}{
+		{"valid", func() *http.Request { return signed(`{"n":1}`, now) }, nil},
+		{"skew inside", func() *http.Request { return signed("", now.Add(-4*time.Minute)) }, nil},
+		{"skew past", func() *http.Request { return signed("", now.Add(-6*time.Minute)) }, errAny},
+		{"skew future", func() *http.Request { return signed("", now.Add(6*time.Minute)) }, errAny},
+		{"body changed", func() *http.Request {
+			req := signed(`{"n":1}`, now)
+			req.Body = http.NoBody
+			return req
+		}, errAny},
+		{"body too large", func() *http.Request { return signed(strings.Repeat("x", 17), now) }, errAny},
+		{"uri changed", func() *http.Request {
+			req := signed("", now)
+			req.URL.RawQuery = "id=8"
+			return req
+		}, errAny},
+		{"wrong secret", func() *http.Request {
+			req := httptest.NewRequest("GET", "/", nil)
+			SignRequest(req, "svc", []byte("guess"), now)
+			return req
+		}, errAny},
+		{"unknown key", func() *http.Request {
+			req := httptest.NewRequest("GET", "/", nil)
+			SignRequest(req, "other", secret, now)
+			return req
+		}, errAny},
+		{"bad base64", func() *http.Request {
+			req := signed("", now)
+			req.Header.Set("Authorization", `HMAC-SHA256 keyId="svc", signature="!!"`)
+			return req
+		}, errAny},
+		{"no timestamp", func() *http.Request {
+			req := signed("", now)
+			req.Header.Del(TimestampHeader)
+			return req
+		}, errAny},
+		{"other scheme", func() *http.Request { return httptest.NewRequest("GET", "/", nil) }, ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		req := tt.req()
+		p, err := s.Authenticate(req)
+		checkErr(t, tt.name, err, tt.err)
+		if err == nil && (p.ID != "svc" || p.Method != "hmac") {
+			t.Errorf("%s: got %+v", tt.name, p)
+		}
+	}
+}
+
+func TestHMACBodyStillReadable(t *testing.T) {
+	secret := []byte("s3cret")
+	req := httptest.NewRequest("POST", "/", strings.NewReader("payload"))
+	now := time.Now()
+	SignRequest(req, "svc", secret, now)
+	if _, err := NewHMACSigner(map[string][]byte{"svc": secret}).Authenticate(req); err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil || string(body) != "payload" {
+		t.Errorf("got body %q, %v, want %q", body, err, "payload")
+	}
+}
+
+func TestHMACEmptySecret(t *testing.T) {
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("NewHMACSigner with an empty secret: no panic")
+			}
+		}()
+		NewHMACSigner(map[string][]byte{"svc": nil})
+	}()
+
+	// Set after construction, an empty secret still mustn't verify.
+	s := NewHMACSigner(map[string][]byte{})
+	s.Secrets["svc"] = []byte{}
+	req := httptest.NewRequest("GET", "/", nil)
+	SignRequest(req, "svc", nil, time.Now())
+	if p, err := s.Authenticate(req); err == nil {
+		t.Errorf("signed with the empty key: got %+v", p)
+	}
+}
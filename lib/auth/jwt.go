@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JWT authenticates "Authorization: Bearer <token>" requests carrying an
+// HS256-signed JSON Web Token. Only HS256 is accepted; in particular a token
+// whose header says "none" is rejected rather than trusted.
+type JWT struct {
+	Secret []byte
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// RequireExp rejects tokens without an exp claim.
+	RequireExp bool
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+	Now    func() time.Time
+}
+
+// NewJWT returns an authenticator that requires exp and allows 30 seconds of
+// clock skew. It panics if secret is empty: anyone can compute an HMAC with
+// the empty key, so every token would verify.
+func NewJWT(secret []byte) *JWT {
+	if len(secret) == 0 {
+		panic("auth: empty JWT secret")
+	}
+	return &JWT{Secret: secret, RequireExp: true, Leeway: 30 * time.Second}
+}
+
+var b64 = base64.RawURLEncoding
+
+// errNoSecret guards a JWT built without NewJWT and left without a secret.
+var errNoSecret = errors.New("auth: empty JWT secret")
+
+func (j *JWT) Authenticate(req *http.Request) (*Principal, error) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+	claims, err := j.Verify(strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, errors.New("token has no subject")
+	}
+	return &Principal{ID: sub, Method: "jwt", Claims: claims}, nil
+}
+
+// Verify checks a token's signature and registered claims and returns all of
+// its claims.
+func (j *JWT) Verify(token string) (map[string]any, error) {
+	if len(j.Secret) == 0 {
+		return nil, errNoSecret
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("token header: %w", err)
+	}
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	mac := hmac.New(sha256.New, j.Secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errors.New("token signature mismatch")
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("token claims: %w", err)
+	}
+	if err := j.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (j *JWT) checkClaims(claims map[string]any) error {
+	now := clock(j.Now)
+
+	exp, hasExp, err := numericDate(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !hasExp && j.RequireExp {
+		return errors.New("token has no expiry")
+	}
+	if hasExp && now.After(exp.Add(j.Leeway)) {
+		return errors.New("token expired")
+	}
+	nbf, hasNbf, err := numericDate(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if hasNbf && now.Add(j.Leeway).Before(nbf) {
+		return errors.New("token not valid yet")
+	}
+	iat, hasIat, err := numericDate(claims, "iat")
+	if err != nil {
+		return err
+	}
+	if hasIat && now.Add(j.Leeway).Before(iat) {
+		return errors.New("token issued in the future")
+	}
+
+	if j.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != j.Issuer {
+			return errors.New("token issuer mismatch")
+		}
+	}
+	if j.Audience != "" && !hasAudience(claims["aud"], j.Audience) {
+		return errors.New("token audience mismatch")
+	}
+	return nil
+}
+
+// Sign issues an HS256 token for claims. Time claims should be Unix seconds,
+// for example time.Now().Add(time.Hour).Unix().
+func (j *JWT) Sign(claims map[string]any) (string, error) {
+	if len(j.Secret) == 0 {
+		return "", errNoSecret
+	}
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signing := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	mac := hmac.New(sha256.New, j.Secret)
+	mac.Write([]byte(signing))
+	return signing + "." + b64.EncodeToString(mac.Sum(nil)), nil
+}
+
+func decodeSegment(seg string, v any) error {
+	raw, err := b64.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// numericDate reads a NumericDate claim (seconds since the epoch, possibly
+// fractional).
+func numericDate(claims map[string]any, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("token claim %s is not a number", name)
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true, nil
+}
+
+// hasAudience handles aud being either a single string or a list.
+func hasAudience(aud any, want string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == want
+	case []any:
+		return slices.ContainsFunc(a, func(v any) bool { return v == want })
+	}
+	return false
+}
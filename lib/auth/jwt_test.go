@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWT(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	j := NewJWT([]byte("s3cret"))
+	j.Audience = "api"
+	j.Issuer = "login"
+	j.Now = func() time.Time { return now }
+
+	claims := func(over map[string]any) map[string]any {
+		c := map[string]any{"sub": "ann", "iss": "login", "aud": "api", "exp": now.Add(time.Hour).Unix()}
+		for k, v := range over {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	sign := func(c map[string]any) string {
+		tok, err := j.Sign(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tok
+	}
+	// forge builds a token with the given header, signed (when alg is
+	// HS256) with the wrong key or not at all.
+	forge := func(header string, c map[string]any) string {
+		tok := sign(c)
+		payload := tok[strings.IndexByte(tok, '.')+1 : strings.LastIndexByte(tok, '.')]
+		return b64.EncodeToString([]byte(header)) + "." + payload + "."
+	}
+
+	var tests = []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", sign(claims(nil)), nil},
+		{"aud list", sign(claims(map[string]any{"aud": []string{"web", "api"}})), nil},
+		{"alg none", forge(`{"alg":"none","typ":"JWT"}`, claims(nil)), errAny},
+		{"alg none lower", forge(`{"alg":"None"}`, claims(nil)), errAny},
+		{"wrong alg", forge(`{"alg":"RS256"}`, claims(nil)), errAny},
+		{"bad signature", sign(claims(nil))[:len(sign(claims(nil)))-2] + "AA", errAny},
+		{"expired", sign(claims(map[string]any{"exp": now.Add(-time.Minute).Unix()})), errAny},
+		{"expired within leeway", sign(claims(map[string]any{"exp": now.Add(-10 * time.Second).Unix()})), nil},
+		{"no exp", sign(claims(map[string]any{"exp": nil})), errAny},
+		{"nbf future", sign(claims(map[string]any{"nbf": now.Add(time.Minute).Unix()})), errAny},
+		{"nbf within leeway", sign(claims(map[string]any{"nbf": now.Add(10 * time.Second).Unix()})), nil},
+		{"exp not a number", sign(claims(map[string]any{"exp": "soon"})), errAny},
+		{"wrong aud", sign(claims(map[string]any{"aud": "admin"})), errAny},
+		{"no aud", sign(claims(map[string]any{"aud": nil})), errAny},
+		{"wrong iss", sign(claims(map[string]any{"iss": "evil"})), errAny},
+		{"no sub", sign(claims(map[string]any{"sub": nil})), errAny},
+		{"empty sub", sign(claims(map[string]any{"sub": ""})), errAny},
+		{"malformed", "a.b", errAny},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		p, err := j.Authenticate(req)
+		checkErr(t, tt.name, err, tt.err)
+		if err == nil && (p.ID != "ann" || p.Method != "jwt") {
+			t.Errorf("%s: got %+v", tt.name, p)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Basic abc")
+	if _, err := j.Authenticate(req); err != ErrNoCredentials {
+		t.Errorf("Basic: got %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestJWTEmptySecret(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewJWT(nil) did not panic")
+		}
+	}()
+	NewJWT(nil)
+}
+
+func TestJWTLiteralWithoutSecret(t *testing.T) {
+	j := &JWT{}
+	if _, err := j.Sign(map[string]any{"sub": "ann"}); err == nil {
+		t.Error("Sign with empty secret: got nil error")
+	}
+	// An HS256 token signed with the empty key must not verify either.
+	tok, _ := (&JWT{Secret: []byte("x")}).Sign(map[string]any{"sub": "ann"})
+	if _, err := j.Verify(tok); err == nil {
+		t.Error("Verify with empty secret: got nil error")
+	}
+}
@@ -0,0 +1,25 @@
+// Package calc holds the square-root calculation from Interfaces2.go and its
+// custom error type.
+package calc
+
+import "math"
+
+// CalculationErr is returned for input the calculation can't handle.
+type CalculationErr struct {
+	msg string
+}
+
+func (ce CalculationErr) Error() string {
+	return ce.msg
+}
+
+// PerformCalculation returns the square root of val, or a CalculationErr when
+// val is negative.
+func PerformCalculation(val float64) (float64, error) {
+	if val < 0 {
+		return 0, CalculationErr{
+			msg: "Invalid input",
+		}
+	}
+	return math.Sqrt(val), nil
+}
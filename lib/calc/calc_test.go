@@ -0,0 +1,88 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestPerformCalculation(t *testing.T) {
+	var tests = []struct {
+		val     float64
+		want    float64
+		wantErr bool
+	}{
+		{0, 0, false},
+		{1, 1, false},
+		{4, 2, false},
+		{20, math.Sqrt(20), false},
+		{0.25, 0.5, false},
+		{math.Inf(1), math.Inf(1), false},
+		{-1, 0, true},
+		{math.Inf(-1), 0, true},
+	}
+
+	for _, tt := range tests {
+		testname := fmt.Sprintf("%g", tt.val)
+		t.Run(testname, func(t *testing.T) {
+			ans, err := PerformCalculation(tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ans != tt.want {
+				t.Errorf("got %g, want %g", ans, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculationErr(t *testing.T) {
+	_, err := PerformCalculation(-4)
+	var ce CalculationErr
+	if !errors.As(err, &ce) {
+		t.Fatalf("err = %#v, want a CalculationErr", err)
+	}
+	if ce.Error() != "Invalid input" {
+		t.Errorf("got %q, want %q", ce.Error(), "Invalid input")
+	}
+}
+
+// FuzzPerformCalculation checks that negative input is rejected and that the
+// result squared gives back the input.
+func FuzzPerformCalculation(f *testing.F) {
+	f.Add(0.0)
+	f.Add(20.0)
+	f.Add(-1.0)
+	f.Add(1e300)
+	f.Fuzz(func(t *testing.T, val float64) {
+		ans, err := PerformCalculation(val)
+		if val < 0 {
+			if err == nil {
+				t.Errorf("PerformCalculation(%g) = %g, want an error", val, ans)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("PerformCalculation(%g): %v", val, err)
+		}
+		if math.IsNaN(val) || math.IsInf(val, 1) {
+			return
+		}
+		if ans < 0 || math.Abs(ans*ans-val) > 1e-12*math.Max(val, 1) {
+			t.Errorf("PerformCalculation(%g) = %g, squared %g", val, ans, ans*ans)
+		}
+	})
+}
+
+func BenchmarkPerformCalculation(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		PerformCalculation(20)
+	}
+}
+
+func BenchmarkPerformCalculationErr(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		PerformCalculation(-20)
+	}
+}
@@ -0,0 +1,311 @@
+// Package calendar does date arithmetic in working days and working hours.
+//
+// Switch.go spots the weekend with an inline "case time.Saturday,
+// time.Sunday", and Time.go only adds and subtracts raw durations. A Calendar
+// knows which weekdays are worked, which dates are holidays (loaded from a
+// file, see ReadHolidays) and the hours of the working day in a given
+// location, and answers questions like "what is 3 business days after this
+// order" or "how many working hours did this ticket wait". Working hours are
+// laid out on the wall clock of the Calendar's Location, so they stay 9 to 5
+// across daylight saving changes, and durations are real elapsed time.
+package calendar
+
+import (
+	"time"
+)
+
+// Calendar is a working week, a set of holidays and the hours of the working
+// day. Create one with New and adjust its fields before use.
+type Calendar struct {
+	// Location is where the working day's hours are on the wall clock, and
+	// which day a time falls on.
+	Location *time.Location
+	// WorkingDays, indexed by time.Weekday, says which days are worked.
+	WorkingDays [7]bool
+	// DayStart and DayEnd bound the working hours as offsets from midnight
+	// on the wall clock: 9*time.Hour and 17*time.Hour for nine to five.
+	// DayEnd must be after DayStart and at most 24 hours.
+	DayStart, DayEnd time.Duration
+
+	holidays map[date]string
+	yearly   map[monthDay]string
+}
+
+// New returns a calendar for time.Local with a Monday to Friday week, nine
+// to five, and no holidays.
+func New() *Calendar {
+	c := &Calendar{
+		Location: time.Local,
+		DayStart: 9 * time.Hour,
+		DayEnd:   17 * time.Hour,
+	}
+	for d := time.Monday; d <= time.Friday; d++ {
+		c.WorkingDays[d] = true
+	}
+	return c
+}
+
+// date is a day on the calendar, independent of any location.
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+type monthDay struct {
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{y, m, d}
+}
+
+// add returns the date n days after d. The arithmetic is done in UTC, where
+// every day is 24 hours long.
+func (d date) add(n int) date {
+	return dateOf(time.Date(d.year, d.month, d.day+n, 0, 0, 0, 0, time.UTC))
+}
+
+func (d date) weekday() time.Weekday {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC).Weekday()
+}
+
+func (d date) before(e date) bool {
+	if d.year != e.year {
+		return d.year < e.year
+	}
+	if d.month != e.month {
+		return d.month < e.month
+	}
+	return d.day < e.day
+}
+
+// at returns the time on d at offset from midnight on the wall clock of
+// loc. A wall time that a spring-forward skips comes out as the moment of
+// the jump, when the clock first shows a later time.
+func (d date) at(offset time.Duration, loc *time.Location) time.Time {
+	h, m := int(offset/time.Hour), int(offset%time.Hour/time.Minute)
+	ns := int(offset % time.Minute)
+	t := time.Date(d.year, d.month, d.day, h, m, 0, ns, loc)
+	if h < 24 && (t.Hour() != h || t.Minute() != m) {
+		// time.Date moved it back by the size of the gap, into the
+		// zone before the jump; that zone ends at the jump.
+		_, t = t.ZoneBounds()
+	}
+	return t
+}
+
+func (c *Calendar) loc() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// AddHoliday makes a single date a holiday.
+func (c *Calendar) AddHoliday(year int, month time.Month, day int, name string) {
+	if c.holidays == nil {
+		c.holidays = make(map[date]string)
+	}
+	c.holidays[date{year, month, day}] = name
+}
+
+// AddYearlyHoliday makes a date a holiday every year.
+func (c *Calendar) AddYearlyHoliday(month time.Month, day int, name string) {
+	if c.yearly == nil {
+		c.yearly = make(map[monthDay]string)
+	}
+	c.yearly[monthDay{month, day}] = name
+}
+
+func (c *Calendar) holiday(d date) (string, bool) {
+	if name, ok := c.holidays[d]; ok {
+		return name, true
+	}
+	name, ok := c.yearly[monthDay{d.month, d.day}]
+	return name, ok
+}
+
+// Holiday returns the name of the holiday t falls on, in the calendar's
+// location, and whether there is one.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	return c.holiday(dateOf(t.In(c.loc())))
+}
+
+func (c *Calendar) working(d date) bool {
+	if !c.WorkingDays[d.weekday()] {
+		return false
+	}
+	_, holiday := c.holiday(d)
+	return !holiday
+}
+
+// IsWorkingDay reports whether t falls on a working day that isn't a
+// holiday.
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	return c.working(dateOf(t.In(c.loc())))
+}
+
+// IsWorkingTime reports whether t is within the working hours of a working
+// day.
+func (c *Calendar) IsWorkingTime(t time.Time) bool {
+	t = t.In(c.loc())
+	d := dateOf(t)
+	if !c.working(d) {
+		return false
+	}
+	start, end := c.hours(d)
+	return !t.Before(start) && t.Before(end)
+}
+
+// hours returns the working hours of day d.
+func (c *Calendar) hours(d date) (start, end time.Time) {
+	return d.at(c.DayStart, c.loc()), d.at(c.DayEnd, c.loc())
+}
+
+func (c *Calendar) mustHaveWorkingDays() {
+	for _, w := range c.WorkingDays {
+		if w {
+			return
+		}
+	}
+	panic("calendar: no working days")
+}
+
+// mustHaveWorkingHours also checks the working day isn't empty, which would
+// leave AddWorkingHours looking for working time forever.
+func (c *Calendar) mustHaveWorkingHours() {
+	c.mustHaveWorkingDays()
+	if c.DayEnd <= c.DayStart {
+		panic("calendar: DayEnd is not after DayStart")
+	}
+}
+
+// AddBusinessDays returns the time n working days after t, at the same time
+// of day on the wall clock. Days that aren't worked are skipped, so one
+// business day after a Friday, or a Saturday, is the Monday. A negative n
+// counts back. It panics if the calendar has no working days.
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	c.mustHaveWorkingDays()
+	t = t.In(c.loc())
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	d := dateOf(t)
+	for n > 0 {
+		d = d.add(step)
+		if c.working(d) {
+			n--
+		}
+	}
+	h, m, s := t.Clock()
+	wall := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+	return d.at(wall, c.loc())
+}
+
+// BusinessDaysBetween counts the working days that AddBusinessDays steps
+// onto going from from's date to to's date: those after from's date up to
+// and including to's, or, when to is before from, those from to's date up to
+// but not including from's, negated. So BusinessDaysBetween(t,
+// AddBusinessDays(t, n)) is n.
+func (c *Calendar) BusinessDaysBetween(from, to time.Time) int {
+	a, b := dateOf(from.In(c.loc())), dateOf(to.In(c.loc()))
+	if b.before(a) {
+		n := 0
+		for d := b; d.before(a); d = d.add(1) {
+			if c.working(d) {
+				n--
+			}
+		}
+		return n
+	}
+	n := 0
+	for d := a.add(1); !b.before(d); d = d.add(1) {
+		if c.working(d) {
+			n++
+		}
+	}
+	return n
+}
+
+// WorkingHours returns how much working time lies between from and to:
+// the elapsed time, within working hours of working days, that they have in
+// common. A working day that a daylight saving change shortens or lengthens
+// counts for what it really lasted. It is negative when to is before from.
+func (c *Calendar) WorkingHours(from, to time.Time) time.Duration {
+	if to.Before(from) {
+		return -c.WorkingHours(to, from)
+	}
+	var total time.Duration
+	last := dateOf(to.In(c.loc()))
+	for d := dateOf(from.In(c.loc())); !last.before(d); d = d.add(1) {
+		if !c.working(d) {
+			continue
+		}
+		start, end := c.hours(d)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// AddWorkingHours returns when d of working time will have passed after t:
+// the deadline of an SLA measured in working hours. The clock only runs
+// during working hours, so the result is always within them, or at the
+// close of a working day. A d of zero or less returns t. It panics if the
+// calendar has no working days or DayEnd is not after DayStart.
+func (c *Calendar) AddWorkingHours(t time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return t
+	}
+	c.mustHaveWorkingHours()
+	t = t.In(c.loc())
+	for day := dateOf(t); ; day = day.add(1) {
+		if !c.working(day) {
+			continue
+		}
+		start, end := c.hours(day)
+		if start.Before(t) {
+			start = t
+		}
+		if !end.After(start) {
+			continue
+		}
+		left := end.Sub(start)
+		if d <= left {
+			return start.Add(d)
+		}
+		d -= left
+	}
+}
+
+// NextWorkingTime returns t if it is within working hours, or else the start
+// of the next working hours after it. It panics if the calendar has no
+// working days or DayEnd is not after DayStart.
+func (c *Calendar) NextWorkingTime(t time.Time) time.Time {
+	c.mustHaveWorkingHours()
+	t = t.In(c.loc())
+	for day := dateOf(t); ; day = day.add(1) {
+		if !c.working(day) {
+			continue
+		}
+		start, end := c.hours(day)
+		if t.Before(start) {
+			return start
+		}
+		if t.Before(end) {
+			return t
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package calendar
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoad(t testing.TB, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+// newYork is nine to five in New York, with Independence Day 2024 (a
+// Thursday) off.
+func newYork(t testing.TB) (*Calendar, func(m time.Month, d, h, min int) time.Time) {
+	ny := mustLoad(t, "America/New_York")
+	c := New()
+	c.Location = ny
+	c.AddHoliday(2024, time.July, 4, "Independence Day")
+	return c, func(m time.Month, d, h, min int) time.Time {
+		return time.Date(2024, m, d, h, min, 0, 0, ny)
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	c, at := newYork(t)
+	var tests = []struct {
+		from time.Time
+		n    int
+		want time.Time
+	}{
+		{at(7, 1, 10, 0), 0, at(7, 1, 10, 0)},
+		{at(7, 1, 10, 0), 1, at(7, 2, 10, 0)},
+		{at(7, 3, 10, 0), 1, at(7, 5, 10, 0)}, // over the 4th
+		{at(7, 5, 10, 0), 1, at(7, 8, 10, 0)}, // Friday to Monday
+		{at(7, 6, 10, 0), 1, at(7, 8, 10, 0)}, // Saturday to Monday
+		{at(7, 1, 10, 0), 10, at(7, 16, 10, 0)},
+		{at(7, 8, 10, 0), -1, at(7, 5, 10, 0)},
+		{at(7, 5, 10, 0), -2, at(7, 2, 10, 0)},
+		{at(3, 8, 9, 0), 1, at(3, 11, 9, 0)}, // over a DST change: still 9am
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s%+d", tt.from.Format("Mon Jan 2"), tt.n), func(t *testing.T) {
+			if got := c.AddBusinessDays(tt.from, tt.n); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusinessDaysBetweenInvertsAdd(t *testing.T) {
+	c, at := newYork(t)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		from := at(1, 1, 12, 0).AddDate(0, 0, r.Intn(365))
+		n := r.Intn(61) - 30
+		to := c.AddBusinessDays(from, n)
+		if got := c.BusinessDaysBetween(from, to); got != n {
+			t.Fatalf("BusinessDaysBetween(%v, AddBusinessDays(_, %d) = %v) = %d", from, n, to, got)
+		}
+	}
+}
+
+func TestWorkingHours(t *testing.T) {
+	c, at := newYork(t)
+	var tests = []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{"within a day", at(7, 1, 10, 0), at(7, 1, 12, 30), 150 * time.Minute},
+		{"before opening to after closing", at(7, 1, 6, 0), at(7, 1, 20, 0), 8 * time.Hour},
+		{"overnight", at(7, 1, 16, 0), at(7, 2, 10, 0), 2 * time.Hour},
+		{"over a weekend", at(7, 5, 16, 0), at(7, 8, 10, 0), 2 * time.Hour},
+		{"over the holiday", at(7, 3, 9, 0), at(7, 5, 17, 0), 16 * time.Hour},
+		{"a whole week", at(7, 8, 0, 0), at(7, 15, 0, 0), 40 * time.Hour},
+		{"backwards", at(7, 1, 12, 30), at(7, 1, 10, 0), -150 * time.Minute},
+		{"weekend only", at(7, 6, 9, 0), at(7, 7, 17, 0), 0},
+		{"across spring forward", at(3, 8, 9, 0), at(3, 11, 17, 0), 16 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.WorkingHours(tt.from, tt.to); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDSTDays covers a round-the-clock calendar, where the working day is
+// the whole day and so shrinks and grows with the clock changes.
+func TestDSTDays(t *testing.T) {
+	c, at := newYork(t)
+	c.DayStart, c.DayEnd = 0, 24*time.Hour
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		c.WorkingDays[d] = true
+	}
+	var tests = []struct {
+		day  time.Time
+		want time.Duration
+	}{
+		{at(3, 10, 0, 0), 23 * time.Hour},
+		{at(3, 11, 0, 0), 24 * time.Hour},
+		{at(11, 3, 0, 0), 25 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := c.WorkingHours(tt.day, tt.day.AddDate(0, 0, 1)); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.day.Format("Jan 2"), got, tt.want)
+		}
+	}
+
+	// A day that starts in the hour the clocks skip starts at the jump.
+	c.DayStart, c.DayEnd = 2*time.Hour+30*time.Minute, 4*time.Hour
+	if got, want := c.NextWorkingTime(at(3, 10, 0, 0)), at(3, 10, 3, 0); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := c.WorkingHours(at(3, 10, 0, 0), at(3, 11, 0, 0)); got != time.Hour {
+		t.Errorf("got %v, want %v", got, time.Hour)
+	}
+}
+
+func TestAddWorkingHours(t *testing.T) {
+	c, at := newYork(t)
+	var tests = []struct {
+		from time.Time
+		d    time.Duration
+		want time.Time
+	}{
+		{at(7, 1, 10, 0), 2 * time.Hour, at(7, 1, 12, 0)},
+		{at(7, 1, 10, 0), 7 * time.Hour, at(7, 1, 17, 0)}, // at closing, not the next morning
+		{at(7, 1, 10, 0), 8 * time.Hour, at(7, 2, 10, 0)},
+		{at(7, 1, 6, 0), time.Hour, at(7, 1, 10, 0)},
+		{at(7, 5, 16, 0), 2 * time.Hour, at(7, 8, 10, 0)},
+		{at(7, 3, 16, 0), 4 * time.Hour, at(7, 5, 12, 0)},
+		{at(7, 6, 12, 0), 0, at(7, 6, 12, 0)},
+		{at(3, 8, 16, 0), 4 * time.Hour, at(3, 11, 12, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s+%v", tt.from.Format("Mon Jan 2 15:04"), tt.d), func(t *testing.T) {
+			got := c.AddWorkingHours(tt.from, tt.d)
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.d > 0 {
+				if wh := c.WorkingHours(tt.from, got); wh != tt.d {
+					t.Errorf("WorkingHours(from, result) = %v", wh)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkingTime(t *testing.T) {
+	c, at := newYork(t)
+	var tests = []struct {
+		t       time.Time
+		working bool
+		next    time.Time
+	}{
+		{at(7, 1, 8, 59), false, at(7, 1, 9, 0)},
+		{at(7, 1, 9, 0), true, at(7, 1, 9, 0)},
+		{at(7, 1, 16, 59), true, at(7, 1, 16, 59)},
+		{at(7, 1, 17, 0), false, at(7, 2, 9, 0)},
+		{at(7, 3, 18, 0), false, at(7, 5, 9, 0)},
+		{at(7, 6, 12, 0), false, at(7, 8, 9, 0)},
+	}
+	for _, tt := range tests {
+		if got := c.IsWorkingTime(tt.t); got != tt.working {
+			t.Errorf("IsWorkingTime(%v): got %v, want %v", tt.t, got, tt.working)
+		}
+		if got := c.NextWorkingTime(tt.t); !got.Equal(tt.next) {
+			t.Errorf("NextWorkingTime(%v): got %v, want %v", tt.t, got, tt.next)
+		}
+	}
+	// The same instant is a different day in another zone.
+	utcMidnight := time.Date(2024, 7, 5, 1, 0, 0, 0, time.UTC) // 9pm on the 4th in New York
+	if c.IsWorkingDay(utcMidnight) {
+		t.Error("the evening of the 4th in New York counted as a working day")
+	}
+	if name, ok := c.Holiday(utcMidnight); !ok || name != "Independence Day" {
+		t.Errorf("Holiday() = %q, %v", name, ok)
+	}
+}
+
+func TestNoWorkingDaysPanics(t *testing.T) {
+	c := New()
+	c.WorkingDays = [7]bool{}
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+	c.AddBusinessDays(time.Now(), 1)
+}
+
+func TestEmptyWorkingDayPanics(t *testing.T) {
+	var tests = []struct {
+		start, end time.Duration
+	}{
+		{17 * time.Hour, 9 * time.Hour},
+		{9 * time.Hour, 9 * time.Hour},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		c := New()
+		c.DayStart, c.DayEnd = tt.start, tt.end
+		for name, f := range map[string]func(){
+			"AddWorkingHours": func() { c.AddWorkingHours(time.Now(), time.Hour) },
+			"NextWorkingTime": func() { c.NextWorkingTime(time.Now()) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s with hours %v-%v: no panic", name, tt.start, tt.end)
+					}
+				}()
+				f()
+			}()
+		}
+	}
+}
+
+func BenchmarkWorkingHoursYear(b *testing.B) {
+	c, at := newYork(b)
+	from, to := at(1, 1, 0, 0), at(12, 31, 0, 0)
+	for i := 0; i < b.N; i++ {
+		c.WorkingHours(from, to)
+	}
+}
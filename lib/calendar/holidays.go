@@ -0,0 +1,69 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// LoadHolidays reads a holidays file into the calendar. See ReadHolidays.
+func (c *Calendar) LoadHolidays(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.ReadHolidays(f)
+}
+
+// ReadHolidays reads holidays, one per line: a date, then optionally the
+// holiday's name. A full date is a holiday that year only; a month and day
+// is one every year. Blank lines and lines starting with # are ignored:
+//
+//	# India, 2024
+//	2024-01-26  Republic Day
+//	2024-03-25  Holi
+//	08-15       Independence Day
+//
+// Nothing is added if any line is bad.
+func (c *Calendar) ReadHolidays(r io.Reader) error {
+	type holiday struct {
+		d      date
+		yearly bool
+		name   string
+	}
+	var read []holiday
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		field := strings.Fields(text)[0]
+		name := strings.TrimSpace(strings.TrimPrefix(text, field))
+		if t, err := time.Parse("2006-01-02", field); err == nil {
+			read = append(read, holiday{dateOf(t), false, name})
+			continue
+		}
+		// Parsed in a leap year so that 02-29 is allowed.
+		if t, err := time.Parse("2006-01-02", "2000-"+field); err == nil {
+			read = append(read, holiday{dateOf(t), true, name})
+			continue
+		}
+		return fmt.Errorf("calendar: holidays line %d: bad date %q, want YYYY-MM-DD or MM-DD", line, field)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	for _, h := range read {
+		if h.yearly {
+			c.AddYearlyHoliday(h.d.month, h.d.day, h.name)
+		} else {
+			c.AddHoliday(h.d.year, h.d.month, h.d.day, h.name)
+		}
+	}
+	return nil
+}
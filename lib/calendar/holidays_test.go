@@ -0,0 +1,80 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const indiaHolidays = `# India, 2024
+2024-01-26  Republic Day
+2024-03-25	Holi
+
+08-15       Independence Day
+10-02
+`
+
+func TestReadHolidays(t *testing.T) {
+	c := New()
+	c.Location = time.UTC
+	if err := c.ReadHolidays(strings.NewReader(indiaHolidays)); err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		day  time.Time
+		name string
+		ok   bool
+	}{
+		{time.Date(2024, 1, 26, 12, 0, 0, 0, time.UTC), "Republic Day", true},
+		{time.Date(2025, 1, 26, 12, 0, 0, 0, time.UTC), "", false},
+		{time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC), "Holi", true},
+		{time.Date(2024, 8, 15, 12, 0, 0, 0, time.UTC), "Independence Day", true},
+		{time.Date(2031, 8, 15, 12, 0, 0, 0, time.UTC), "Independence Day", true},
+		{time.Date(2024, 10, 2, 12, 0, 0, 0, time.UTC), "", true},
+		{time.Date(2024, 10, 3, 12, 0, 0, 0, time.UTC), "", false},
+	}
+	for _, tt := range tests {
+		name, ok := c.Holiday(tt.day)
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("Holiday(%s): got %q, %v, want %q, %v", tt.day.Format("2006-01-02"), name, ok, tt.name, tt.ok)
+		}
+	}
+}
+
+func TestReadHolidaysErrors(t *testing.T) {
+	for _, in := range []string{
+		"2024-02-30 Not a day\n",
+		"13-01\n",
+		"Christmas 12-25\n",
+		"2024-01-01 New Year\n25/12\n",
+	} {
+		c := New()
+		err := c.ReadHolidays(strings.NewReader(in))
+		if err == nil {
+			t.Errorf("%q: no error", in)
+			continue
+		}
+		if _, ok := c.Holiday(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)); ok {
+			t.Errorf("%q: holidays added despite the error", in)
+		}
+	}
+}
+
+func TestLoadHolidays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.txt")
+	if err := os.WriteFile(path, []byte(indiaHolidays), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := New()
+	if err := c.LoadHolidays(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Holiday(time.Date(2024, 3, 25, 12, 0, 0, 0, time.Local)); !ok {
+		t.Error("Holi not loaded")
+	}
+	if err := c.LoadHolidays(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
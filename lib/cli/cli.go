@@ -0,0 +1,353 @@
+// Package cli builds command-line tools with nested subcommands.
+//
+// CmdSubCommands.go switches on os.Args[1] between a "foo" and a "bar"
+// flag.FlagSet and prints "expected 'foo' or 'bar' subcommands" for anything
+// else. Here each subcommand is a Command in a tree. A command has its own
+// flags, and persistent flags that its subcommands accept too. It can say
+// how many arguments it takes. Execute finds the command the arguments name,
+// parses its flags and runs it. Help and usage are generated from the tree,
+// and a mistyped command gets "did you mean" suggestions. The tree also
+// drives shell completion for bash, zsh and fish; see CompletionCommand.
+//
+//	root := &cli.Command{Name: "app", Short: "Does things"}
+//	verbose := root.PersistentFlags().Bool("v", false, "verbose output")
+//	foo := &cli.Command{Name: "foo", Short: "Runs foo", Args: cli.MinArgs(1),
+//		Run: func(cmd *cli.Command, args []string) error { ... }}
+//	name := foo.Flags().String("name", "", "who to greet")
+//	foo.Flags().Short('n', "name")
+//	root.Add(foo, cli.CompletionCommand())
+//	root.Main()
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/prashant1k99/GoLearn/lib/gnuflag"
+)
+
+// Command is a node in the command tree.
+type Command struct {
+	// Name is what selects the command on the command line.
+	Name    string
+	Aliases []string
+	// Short is the one-line description shown in command lists.
+	Short string
+	// Long is the description at the top of the command's help. Short is
+	// used if it is empty.
+	Long string
+	// ArgsUsage describes the arguments in the usage line, e.g. "<file>...".
+	ArgsUsage string
+	// Args validates the arguments left after flags. Nil accepts any.
+	Args Args
+	// Run does the command's work. A command without Run only groups
+	// subcommands.
+	Run func(cmd *Command, args []string) error
+	// Complete, if set, suggests arguments for shell completion.
+	Complete func(cmd *Command, args []string, toComplete string) []string
+	// Hidden commands work but are left out of help and completion.
+	Hidden bool
+
+	// Out and Err receive help and errors. Nil means the parent's, and at
+	// the root, os.Stdout and os.Stderr.
+	Out, Err io.Writer
+
+	parent     *Command
+	children   []*Command
+	flags      *gnuflag.FlagSet
+	persistent *gnuflag.FlagSet
+	ctx        context.Context
+}
+
+// Add makes cmds subcommands of c.
+func (c *Command) Add(cmds ...*Command) {
+	for _, cmd := range cmds {
+		if cmd.parent != nil {
+			panic(fmt.Sprintf("cli: %s is already a subcommand of %s", cmd.Name, cmd.parent.Name))
+		}
+		cmd.parent = c
+		c.children = append(c.children, cmd)
+	}
+}
+
+// Commands returns c's subcommands, in the order they were added.
+func (c *Command) Commands() []*Command { return c.children }
+
+// Parent returns the command c is a subcommand of, or nil for the root.
+func (c *Command) Parent() *Command { return c.parent }
+
+// Root returns the top of c's tree.
+func (c *Command) Root() *Command {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// Path returns the names from the root to c, e.g. "app remote add".
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Flags returns the flags c alone accepts. Short gives one of them a
+// one-letter name as well.
+func (c *Command) Flags() *gnuflag.FlagSet {
+	if c.flags == nil {
+		c.flags = newFlagSet(c.Name)
+	}
+	return c.flags
+}
+
+// PersistentFlags returns the flags c and all of its subcommands accept.
+func (c *Command) PersistentFlags() *gnuflag.FlagSet {
+	if c.persistent == nil {
+		c.persistent = newFlagSet(c.Name)
+	}
+	return c.persistent
+}
+
+func newFlagSet(name string) *gnuflag.FlagSet {
+	fs := gnuflag.New(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	return fs
+}
+
+// Context returns the context passed to ExecuteContext, or
+// context.Background.
+func (c *Command) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+// OutOrStdout returns where c writes output.
+func (c *Command) OutOrStdout() io.Writer {
+	for x := c; x != nil; x = x.parent {
+		if x.Out != nil {
+			return x.Out
+		}
+	}
+	return os.Stdout
+}
+
+// ErrOrStderr returns where c writes errors.
+func (c *Command) ErrOrStderr() io.Writer {
+	for x := c; x != nil; x = x.parent {
+		if x.Err != nil {
+			return x.Err
+		}
+	}
+	return os.Stderr
+}
+
+// UsageError is a mistake on the command line, as opposed to a failure of
+// the command itself.
+type UsageError struct {
+	Cmd *Command
+	Err error
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+func (e *UsageError) Unwrap() error { return e.Err }
+
+func usageErrorf(c *Command, format string, args ...any) error {
+	return &UsageError{Cmd: c, Err: fmt.Errorf(format, args...)}
+}
+
+// Execute runs the command args select, args being the command line
+// without the program name. See ExecuteContext.
+func (c *Command) Execute(args []string) error {
+	return c.ExecuteContext(context.Background(), args)
+}
+
+// ExecuteContext finds the subcommand args name, parses its flags and
+// persistent flags, GNU style, so they may come before or after its
+// arguments, validates the arguments that remain and runs it. "-h",
+// "--help" and "help <command>" print help instead. Mistakes in the
+// arguments are returned as *UsageError.
+func (c *Command) ExecuteContext(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == completeCmd {
+		return c.complete(args[1:])
+	}
+	if len(args) > 0 && args[0] == "help" && c.child("help") == nil {
+		cmd, rest := c.find(args[1:])
+		if len(rest) > 0 && len(cmd.visibleChildren()) > 0 {
+			return cmd.unknown(rest[0])
+		}
+		return cmd.Help()
+	}
+
+	cmd, rest := c.find(args)
+	cmd.ctx = ctx
+	fs := cmd.flagSet()
+	if err := fs.Parse(rest); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return cmd.Help()
+		}
+		return &UsageError{Cmd: cmd, Err: err}
+	}
+	rest = fs.Args()
+
+	if cmd.Run == nil {
+		if len(rest) > 0 {
+			return cmd.unknown(rest[0])
+		}
+		if len(cmd.children) > 0 {
+			return usageErrorf(cmd, "%s: missing command", cmd.Path())
+		}
+		return cmd.Help()
+	}
+	if cmd.Args != nil {
+		if err := cmd.Args(cmd, rest); err != nil {
+			return &UsageError{Cmd: cmd, Err: fmt.Errorf("%s: %w", cmd.Path(), err)}
+		}
+	}
+	return cmd.Run(cmd, rest)
+}
+
+// Main executes the root command with os.Args and exits: with status 0 on
+// success, 2 after printing a usage error, and 1 after printing any other
+// error.
+func (c *Command) Main() {
+	err := c.Execute(os.Args[1:])
+	if err == nil {
+		os.Exit(0)
+	}
+	fmt.Fprintln(c.ErrOrStderr(), "Error:", err)
+	var ue *UsageError
+	if errors.As(err, &ue) {
+		fmt.Fprintf(c.ErrOrStderr(), "Run '%s -h' for usage.\n", ue.Cmd.Path())
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
+
+// child returns the subcommand called name, by name or alias.
+func (c *Command) child(name string) *Command {
+	for _, ch := range c.children {
+		if ch.Name == name {
+			return ch
+		}
+		for _, a := range ch.Aliases {
+			if a == name {
+				return ch
+			}
+		}
+	}
+	return nil
+}
+
+// find walks down the tree along the command names in args, stepping over
+// flags, and returns the deepest command named and the args that are left:
+// its flags and arguments. Flags are read as the command found so far would
+// parse them, so in "-vn 7 sub" the 7 is -n's value, not a command name.
+func (c *Command) find(args []string) (*Command, []string) {
+	cmd := c
+	fs := cmd.flagSet()
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if strings.HasPrefix(a, "-") && len(a) > 1 {
+			rest = append(rest, a)
+			// A flag that takes a value and was given it separately.
+			if i+1 < len(args) && fs.TakesValue(a) {
+				i++
+				rest = append(rest, args[i])
+			}
+			continue
+		}
+		next := cmd.child(a)
+		if next == nil {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		cmd = next
+		fs = cmd.flagSet()
+	}
+	return cmd, rest
+}
+
+// flagSet returns the flags c accepts: its own, its persistent ones and
+// its ancestors' persistent ones, with their short names. A flag or short
+// name defined nearer c hides one of the same name further up. The flags
+// share their Values with the sets they were defined in.
+func (c *Command) flagSet() *gnuflag.FlagSet {
+	fs := newFlagSet(c.Path())
+	copyFlags(fs, c.flags, nil)
+	for x := c; x != nil; x = x.parent {
+		copyFlags(fs, x.persistent, nil)
+	}
+	return fs
+}
+
+// Args validates a command's arguments.
+type Args func(cmd *Command, args []string) error
+
+// NoArgs accepts no arguments.
+func NoArgs(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+	return nil
+}
+
+// ExactArgs accepts exactly n arguments.
+func ExactArgs(n int) Args {
+	return func(cmd *Command, args []string) error {
+		if len(args) != n {
+			return fmt.Errorf("accepts %s, got %d", plural(n, "argument"), len(args))
+		}
+		return nil
+	}
+}
+
+// MinArgs accepts at least n arguments.
+func MinArgs(n int) Args {
+	return func(cmd *Command, args []string) error {
+		if len(args) < n {
+			return fmt.Errorf("needs at least %s, got %d", plural(n, "argument"), len(args))
+		}
+		return nil
+	}
+}
+
+// MaxArgs accepts at most n arguments.
+func MaxArgs(n int) Args {
+	return func(cmd *Command, args []string) error {
+		if len(args) > n {
+			return fmt.Errorf("accepts at most %s, got %d", plural(n, "argument"), len(args))
+		}
+		return nil
+	}
+}
+
+// RangeArgs accepts between min and max arguments, inclusive.
+func RangeArgs(min, max int) Args {
+	return func(cmd *Command, args []string) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("accepts %d to %d arguments, got %d", min, max, len(args))
+		}
+		return nil
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
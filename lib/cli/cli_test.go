@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// app builds the tree the tests share, after CmdSubCommands.go's foo and
+// bar with a nested group added, and records what ran.
+type app struct {
+	root, foo, bar, remote, add *Command
+
+	verbose *bool
+	jobs    *int
+	enable  *bool
+	name    *string
+	level   *int
+	url     *string
+
+	ran  string
+	args []string
+	out  bytes.Buffer
+}
+
+func newApp() *app {
+	a := &app{}
+	record := func(cmd *Command, args []string) error {
+		a.ran, a.args = cmd.Path(), args
+		return nil
+	}
+	a.root = &Command{Name: "app", Short: "A test program"}
+	a.root.Out, a.root.Err = &a.out, &a.out
+	a.verbose = a.root.PersistentFlags().Bool("v", false, "verbose output")
+	a.jobs = a.root.PersistentFlags().Int("jobs", 1, "parallel jobs")
+	a.root.PersistentFlags().Short('j', "jobs")
+
+	a.foo = &Command{Name: "foo", Short: "Runs foo", Aliases: []string{"f"}, Run: record}
+	a.enable = a.foo.Flags().Bool("enable", false, "enable")
+	a.name = a.foo.Flags().String("name", "", "name")
+	a.foo.Flags().Short('n', "name")
+
+	a.bar = &Command{Name: "bar", Short: "Runs bar", Args: MaxArgs(2), Run: record}
+	a.level = a.bar.Flags().Int("level", 0, "level")
+
+	a.remote = &Command{Name: "remote", Short: "Manages remotes"}
+	a.url = a.remote.PersistentFlags().String("url", "", "remote URL")
+	a.add = &Command{Name: "add", Short: "Adds a remote", ArgsUsage: "<name>", Args: ExactArgs(1), Run: record}
+	a.remote.Add(a.add)
+
+	a.root.Add(a.foo, a.bar, a.remote, &Command{Name: "secret", Hidden: true, Run: record})
+	return a
+}
+
+func TestExecute(t *testing.T) {
+	var tests = []struct {
+		args  []string
+		ran   string
+		rest  []string
+		check func(a *app) bool
+	}{
+		{[]string{"foo", "-enable", "-name=joe", "a1", "a2"}, "app foo", []string{"a1", "a2"},
+			func(a *app) bool { return *a.enable && *a.name == "joe" }},
+		{[]string{"f", "-name", "joe"}, "app foo", []string{},
+			func(a *app) bool { return *a.name == "joe" }},
+		{[]string{"-v", "bar", "-level", "8", "x"}, "app bar", []string{"x"},
+			func(a *app) bool { return *a.verbose && *a.level == 8 }},
+		{[]string{"bar", "-v"}, "app bar", []string{},
+			func(a *app) bool { return *a.verbose }},
+		{[]string{"remote", "-url", "u", "add", "origin"}, "app remote add", []string{"origin"},
+			func(a *app) bool { return *a.url == "u" && !*a.verbose }},
+		{[]string{"remote", "add", "origin", "--url", "u", "-v"}, "app remote add", []string{"origin"},
+			func(a *app) bool { return *a.url == "u" && *a.verbose }},
+		{[]string{"remote", "add", "-url=u", "-v", "origin"}, "app remote add", []string{"origin"},
+			func(a *app) bool { return *a.url == "u" && *a.verbose }},
+		{[]string{"foo", "--", "-name"}, "app foo", []string{"-name"},
+			func(a *app) bool { return *a.name == "" }},
+		{[]string{"foo", "bar"}, "app foo", []string{"bar"}, nil},
+		// Short names, alone and combined, before and after the command.
+		{[]string{"foo", "-n", "joe", "x"}, "app foo", []string{"x"},
+			func(a *app) bool { return *a.name == "joe" }},
+		{[]string{"-vj", "7", "bar", "x"}, "app bar", []string{"x"},
+			func(a *app) bool { return *a.verbose && *a.jobs == 7 }},
+		{[]string{"-j", "3", "remote", "add", "origin"}, "app remote add", []string{"origin"},
+			func(a *app) bool { return *a.jobs == 3 }},
+		{[]string{"-vj7", "foo", "-vn", "ann"}, "app foo", []string{},
+			func(a *app) bool { return *a.verbose && *a.jobs == 7 && *a.name == "ann" }},
+		{[]string{"--jobs", "2", "foo"}, "app foo", []string{},
+			func(a *app) bool { return *a.jobs == 2 }},
+		{[]string{"secret"}, "app secret", []string{}, nil},
+	}
+	for _, tt := range tests {
+		a := newApp()
+		if err := a.root.Execute(tt.args); err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		if a.ran != tt.ran || !slices.Equal(a.args, tt.rest) {
+			t.Errorf("%q: ran %q with %q, want %q with %q", tt.args, a.ran, a.args, tt.ran, tt.rest)
+		}
+		if tt.check != nil && !tt.check(a) {
+			t.Errorf("%q: flags not set as expected", tt.args)
+		}
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	var tests = []struct {
+		args []string
+		cmd  string
+		want string
+	}{
+		{nil, "app", "app: missing command"},
+		{[]string{"baz"}, "app", `unknown command "baz" for "app"`},
+		{[]string{"fo"}, "app", "Did you mean this?\n\tfoo"},
+		{[]string{"remote"}, "app remote", "app remote: missing command"},
+		{[]string{"remote", "ad"}, "app remote", "Did you mean this?\n\tadd"},
+		{[]string{"remote", "add"}, "app remote add", "accepts 1 argument, got 0"},
+		{[]string{"bar", "a", "b", "c"}, "app bar", "accepts at most 2 arguments, got 3"},
+		{[]string{"foo", "-wat"}, "app foo", "flag provided but not defined: -wat"},
+		{[]string{"foo", "-vx"}, "app foo", "flag provided but not defined: -x (in -vx)"},
+		{[]string{"bar", "-level", "x"}, "app bar", `invalid value "x" for flag -level`},
+		{[]string{"help", "nope"}, "app", `unknown command "nope"`},
+	}
+	for _, tt := range tests {
+		a := newApp()
+		err := a.root.Execute(tt.args)
+		var ue *UsageError
+		if !errors.As(err, &ue) {
+			t.Errorf("%q: got %v, want a UsageError", tt.args, err)
+			continue
+		}
+		if ue.Cmd.Path() != tt.cmd || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %q from %q, want %q from %q", tt.args, err, ue.Cmd.Path(), tt.want, tt.cmd)
+		}
+		if a.ran != "" {
+			t.Errorf("%q: ran %q", tt.args, a.ran)
+		}
+	}
+}
+
+func TestRunError(t *testing.T) {
+	boom := errors.New("boom")
+	root := &Command{Name: "app", Run: func(*Command, []string) error { return boom }}
+	err := root.Execute(nil)
+	var ue *UsageError
+	if !errors.Is(err, boom) || errors.As(err, &ue) {
+		t.Errorf("got %v, want boom as it is", err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "v")
+	var got any
+	root := &Command{Name: "app"}
+	root.Add(&Command{Name: "sub", Run: func(cmd *Command, _ []string) error {
+		got = cmd.Context().Value(key{})
+		return nil
+	}})
+	if err := root.ExecuteContext(ctx, []string{"sub"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "v" {
+		t.Errorf("got %v, want v", got)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	var tests = []struct {
+		name string
+		args Args
+		n    int
+		ok   bool
+	}{
+		{"NoArgs", NoArgs, 0, true},
+		{"NoArgs", NoArgs, 1, false},
+		{"ExactArgs(2)", ExactArgs(2), 2, true},
+		{"ExactArgs(2)", ExactArgs(2), 1, false},
+		{"ExactArgs(2)", ExactArgs(2), 3, false},
+		{"MinArgs(1)", MinArgs(1), 0, false},
+		{"MinArgs(1)", MinArgs(1), 5, true},
+		{"MaxArgs(1)", MaxArgs(1), 1, true},
+		{"MaxArgs(1)", MaxArgs(1), 2, false},
+		{"RangeArgs(1, 3)", RangeArgs(1, 3), 0, false},
+		{"RangeArgs(1, 3)", RangeArgs(1, 3), 2, true},
+		{"RangeArgs(1, 3)", RangeArgs(1, 3), 4, false},
+	}
+	for _, tt := range tests {
+		err := tt.args(nil, make([]string, tt.n))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s with %d: got %v, want ok %v", tt.name, tt.n, err, tt.ok)
+		}
+	}
+}
+
+func TestAddTwicePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+	sub := &Command{Name: "sub"}
+	(&Command{Name: "a"}).Add(sub)
+	(&Command{Name: "b"}).Add(sub)
+}
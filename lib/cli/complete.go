@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// completeCmd is the hidden command the completion scripts call back into:
+//
+//	app __complete <words before the cursor>... <word at the cursor>
+//
+// It prints one candidate per line, a name and a description separated by
+// a tab, and the shell script does the rest.
+const completeCmd = "__complete"
+
+func (c *Command) complete(args []string) error {
+	toComplete := ""
+	if len(args) > 0 {
+		toComplete, args = args[len(args)-1], args[:len(args)-1]
+	}
+	out := c.OutOrStdout()
+	for _, s := range c.Candidates(args, toComplete) {
+		fmt.Fprintln(out, s)
+	}
+	return nil
+}
+
+// Candidates returns what the word toComplete could be, after the words
+// args, as "name\tdescription" lines: flags if it starts with "-", and
+// otherwise subcommands and whatever the command's Complete suggests.
+// Nothing is suggested for the value of a flag.
+func (c *Command) Candidates(args []string, toComplete string) []string {
+	cmd, rest := c.find(args)
+	fs := cmd.flagSet()
+
+	var out []string
+	if strings.HasPrefix(toComplete, "-") {
+		name := strings.TrimLeft(toComplete, "-")
+		fs.VisitAll(func(f *flag.Flag) {
+			if strings.HasPrefix(f.Name, name) {
+				out = append(out, "-"+f.Name+"\t"+firstLine(f.Usage))
+			}
+		})
+		return out
+	}
+	if n := len(rest); n > 0 && fs.TakesValue(rest[n-1]) {
+		return nil
+	}
+	if err := fs.Parse(rest); err != nil {
+		return nil
+	}
+	positional := fs.Args()
+
+	if len(positional) == 0 {
+		for _, ch := range cmd.visibleChildren() {
+			if strings.HasPrefix(ch.Name, toComplete) {
+				out = append(out, ch.Name+"\t"+ch.Short)
+			}
+		}
+	}
+	if cmd.Complete != nil {
+		for _, s := range cmd.Complete(cmd, positional, toComplete) {
+			if strings.HasPrefix(s, toComplete) {
+				out = append(out, s+"\t")
+			}
+		}
+	}
+	return out
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// Shells lists the shells GenCompletion writes scripts for.
+var Shells = []string{"bash", "zsh", "fish"}
+
+// GenCompletion writes a completion script for the tree c is the root of.
+// The script calls the program back to ask what to complete, so it keeps
+// up with new commands and flags without being regenerated.
+func (c *Command) GenCompletion(w io.Writer, shell string) error {
+	name := c.Root().Name
+	fn := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	var script string
+	switch shell {
+	case "bash":
+		script = bashScript
+	case "zsh":
+		script = zshScript
+	case "fish":
+		script = fishScript
+	default:
+		return fmt.Errorf("cli: no completion for shell %q; want one of %s", shell, strings.Join(Shells, ", "))
+	}
+	r := strings.NewReplacer("{{name}}", name, "{{fn}}", fn, "{{complete}}", completeCmd)
+	_, err := io.WriteString(w, r.Replace(script))
+	return err
+}
+
+// CompletionCommand returns a "completion" command that prints the
+// completion script for the shell it is given. Add it to the root:
+//
+//	root.Add(cli.CompletionCommand())
+//
+// and users load it with, for example, "source <(app completion bash)".
+func CompletionCommand() *Command {
+	return &Command{
+		Name:      "completion",
+		Short:     "Print a shell completion script",
+		Long:      "Print a completion script for bash, zsh or fish. For example:\n\n  source <(app completion bash)",
+		ArgsUsage: strings.Join(Shells, "|"),
+		Args:      ExactArgs(1),
+		Complete: func(cmd *Command, args []string, toComplete string) []string {
+			if len(args) > 0 {
+				return nil
+			}
+			return Shells
+		},
+		Run: func(cmd *Command, args []string) error {
+			if err := cmd.Root().GenCompletion(cmd.OutOrStdout(), args[0]); err != nil {
+				return &UsageError{Cmd: cmd, Err: err}
+			}
+			return nil
+		},
+	}
+}
+
+const bashScript = `# bash completion for {{name}}
+{{fn}}_complete() {
+    local IFS=$'\n'
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    COMPREPLY=($({{name}} {{complete}} "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null | cut -f1))
+}
+complete -o default -F {{fn}}_complete {{name}}
+`
+
+const zshScript = `#compdef {{name}}
+# zsh completion for {{name}}
+{{fn}}() {
+    local -a completions
+    local line name
+    for line in "${(@f)$({{name}} {{complete}} "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}"; do
+        [[ -z $line ]] && continue
+        name=${line%%$'\t'*}
+        completions+=("${name//:/\\:}:${line#*$'\t'}")
+    done
+    if (( ${#completions} )); then
+        _describe '{{name}}' completions
+    else
+        _files
+    fi
+}
+compdef {{fn}} {{name}}
+`
+
+const fishScript = `# fish completion for {{name}}
+function {{fn}}_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    {{name}} {{complete}} $words (commandline -ct) 2>/dev/null
+end
+complete -c {{name}} -f -a '({{fn}}_complete)'
+`
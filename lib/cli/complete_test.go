@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCandidates(t *testing.T) {
+	var tests = []struct {
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		{nil, "", []string{"foo\tRuns foo", "bar\tRuns bar", "remote\tManages remotes", "completion\tPrint a shell completion script"}},
+		{nil, "b", []string{"bar\tRuns bar"}},
+		{nil, "-", []string{"-jobs\tparallel jobs", "-v\tverbose output"}},
+		{[]string{"foo"}, "-", []string{"-enable\tenable", "-jobs\tparallel jobs", "-name\tname", "-v\tverbose output"}},
+		{[]string{"foo"}, "--n", []string{"-name\tname"}},
+		{[]string{"foo", "-name"}, "", nil},
+		{[]string{"-vj"}, "", nil},
+		{[]string{"-vj", "2"}, "r", []string{"remote\tManages remotes"}},
+		{[]string{"-v", "remote"}, "", []string{"add\tAdds a remote"}},
+		{[]string{"remote", "-url", "x"}, "a", []string{"add\tAdds a remote"}},
+		{[]string{"completion"}, "", []string{"bash\t", "zsh\t", "fish\t"}},
+		{[]string{"completion"}, "z", []string{"zsh\t"}},
+		{[]string{"completion", "bash"}, "", nil},
+		{[]string{"nope"}, "", nil},
+	}
+	for _, tt := range tests {
+		a := newApp()
+		a.root.Add(CompletionCommand())
+		got := a.root.Candidates(tt.args, tt.toComplete)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q %q: got %q, want %q", tt.args, tt.toComplete, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteCommand(t *testing.T) {
+	a := newApp()
+	if err := a.root.Execute([]string{"__complete", "remote", ""}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.out.String(), "add\tAdds a remote\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGenCompletion(t *testing.T) {
+	a := newApp()
+	a.root.Name = "my-app"
+	a.root.Add(CompletionCommand())
+	for _, shell := range Shells {
+		a.out.Reset()
+		if err := a.root.Execute([]string{"completion", shell}); err != nil {
+			t.Fatalf("%s: %v", shell, err)
+		}
+		got := a.out.String()
+		if !strings.Contains(got, "my-app __complete") || !strings.Contains(got, "_my_app") || strings.Contains(got, "{{") {
+			t.Errorf("%s script:\n%s", shell, got)
+		}
+	}
+	if err := a.root.GenCompletion(&bytes.Buffer{}, "tcsh"); err == nil {
+		t.Error("tcsh: no error")
+	}
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/prashant1k99/GoLearn/lib/gnuflag"
+)
+
+// Help writes c's generated help to its output.
+func (c *Command) Help() error {
+	c.WriteHelp(c.OutOrStdout())
+	return nil
+}
+
+// WriteHelp writes c's help to w: its description, usage line, subcommands,
+// its own flags and the persistent flags it inherits.
+func (c *Command) WriteHelp(w io.Writer) {
+	desc := c.Long
+	if desc == "" {
+		desc = c.Short
+	}
+	if desc != "" {
+		fmt.Fprintf(w, "%s\n\n", strings.TrimSpace(desc))
+	}
+	fmt.Fprintf(w, "Usage:\n  %s\n", c.UseLine())
+	if len(c.visibleChildren()) > 0 && c.Run != nil {
+		fmt.Fprintf(w, "  %s <command>\n", c.Path())
+	}
+
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases:\n  %s\n", strings.Join(append([]string{c.Name}, c.Aliases...), ", "))
+	}
+
+	if children := c.visibleChildren(); len(children) > 0 {
+		fmt.Fprintf(w, "\nCommands:\n")
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		for _, ch := range children {
+			fmt.Fprintf(tw, "  %s\t%s\n", ch.Name, ch.Short)
+		}
+		tw.Flush()
+	}
+
+	local := newFlagSet(c.Name)
+	copyFlags(local, c.flags, nil)
+	copyFlags(local, c.persistent, nil)
+	if hasFlags(local) {
+		fmt.Fprintf(w, "\nFlags:\n")
+		writeFlags(w, local)
+	}
+	global := newFlagSet(c.Name)
+	for x := c.parent; x != nil; x = x.parent {
+		copyFlags(global, x.persistent, local)
+	}
+	if hasFlags(global) {
+		fmt.Fprintf(w, "\nGlobal Flags:\n")
+		writeFlags(w, global)
+	}
+
+	if len(c.visibleChildren()) > 0 {
+		fmt.Fprintf(w, "\nRun '%s <command> -h' for more about a command.\n", c.Path())
+	}
+}
+
+// UseLine returns c's usage line, e.g. "app remote add [flags] <name> <url>".
+func (c *Command) UseLine() string {
+	line := c.Path()
+	if hasFlags(c.flagSet()) {
+		line += " [flags]"
+	}
+	switch {
+	case c.ArgsUsage != "":
+		line += " " + c.ArgsUsage
+	case c.Run == nil && len(c.visibleChildren()) > 0:
+		line += " <command>"
+	}
+	return line
+}
+
+func (c *Command) visibleChildren() []*Command {
+	var cs []*Command
+	for _, ch := range c.children {
+		if !ch.Hidden {
+			cs = append(cs, ch)
+		}
+	}
+	return cs
+}
+
+// copyFlags defines src's flags in dst, sharing their values, except for
+// names dst or hide already has. A copied flag keeps its short name unless
+// the letter is taken there too. src and hide may be nil.
+func copyFlags(dst, src, hide *gnuflag.FlagSet) {
+	if src == nil {
+		return
+	}
+	src.VisitAll(func(f *flag.Flag) {
+		if dst.Lookup(f.Name) != nil || hide != nil && hide.Lookup(f.Name) != nil {
+			return
+		}
+		dst.Var(f.Value, f.Name, f.Usage)
+		dst.Lookup(f.Name).DefValue = f.DefValue
+		s, ok := src.ShortFor(f.Name)
+		if !ok || dst.LookupShort(s) != nil || hide != nil && hide.LookupShort(s) != nil {
+			return
+		}
+		dst.Short(s, f.Name)
+	})
+}
+
+func hasFlags(fs *gnuflag.FlagSet) bool {
+	n := 0
+	fs.VisitAll(func(*flag.Flag) { n++ })
+	return n > 0
+}
+
+func writeFlags(w io.Writer, fs *gnuflag.FlagSet) {
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+}
+
+// unknown returns the error for a command name c has no subcommand for,
+// suggesting the ones it might have meant.
+func (c *Command) unknown(name string) error {
+	if len(c.children) == 0 {
+		return usageErrorf(c, "%s: unexpected argument %q", c.Path(), name)
+	}
+	msg := fmt.Sprintf("unknown command %q for %q", name, c.Path())
+	if s := c.suggest(name); len(s) > 0 {
+		msg += "\n\nDid you mean this?\n\t" + strings.Join(s, "\n\t")
+	}
+	return usageErrorf(c, "%s", msg)
+}
+
+// suggest returns the visible subcommands whose name or alias is within two
+// edits of name or starts with it, closest first.
+func (c *Command) suggest(name string) []string {
+	type match struct {
+		name string
+		dist int
+	}
+	var ms []match
+	for _, ch := range c.visibleChildren() {
+		best := -1
+		for _, n := range append([]string{ch.Name}, ch.Aliases...) {
+			d := levenshtein(strings.ToLower(name), strings.ToLower(n))
+			if strings.HasPrefix(strings.ToLower(n), strings.ToLower(name)) {
+				d = 0
+			}
+			if d <= 2 && (best < 0 || d < best) {
+				best = d
+			}
+		}
+		if best >= 0 {
+			ms = append(ms, match{ch.Name, best})
+		}
+	}
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].dist < ms[j].dist })
+	names := make([]string, len(ms))
+	for i, m := range ms {
+		names[i] = m.name
+	}
+	return names
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHelp(t *testing.T) {
+	var tests = []struct {
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{[]string{"-h"}, []string{
+			"A test program\n\nUsage:\n  app [flags] <command>\n",
+			"Commands:\n  foo      Runs foo\n  bar      Runs bar\n  remote   Manages remotes\n",
+			"Flags:\n  -j, --jobs int\n    \tparallel jobs (default 1)\n  -v\tverbose output\n",
+			"Run 'app <command> -h' for more about a command.",
+		}, []string{"secret", "Global Flags"}},
+		{[]string{"help"}, []string{"Commands:"}, nil},
+		{[]string{"foo", "--help"}, []string{
+			"Usage:\n  app foo [flags]\n",
+			"Aliases:\n  foo, f\n",
+			"Flags:\n  --enable\n    \tenable\n  -n, --name string\n",
+			"Global Flags:\n  -j, --jobs int\n    \tparallel jobs (default 1)\n  -v\tverbose output\n",
+		}, []string{"Commands:"}},
+		{[]string{"help", "remote", "add"}, []string{
+			"Adds a remote\n\nUsage:\n  app remote add [flags] <name>\n",
+			"Global Flags:\n  -j, --jobs int\n    \tparallel jobs (default 1)\n  --url string\n    \tremote URL\n  -v\tverbose output\n",
+		}, nil},
+		{[]string{"remote", "-h"}, []string{"Usage:\n  app remote [flags] <command>\n", "Flags:\n  --url string"}, nil},
+	}
+	for _, tt := range tests {
+		a := newApp()
+		if err := a.root.Execute(tt.args); err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		got := a.out.String()
+		for _, w := range tt.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%q: help lacks %q:\n%s", tt.args, w, got)
+			}
+		}
+		for _, w := range tt.notWant {
+			if strings.Contains(got, w) {
+				t.Errorf("%q: help has %q:\n%s", tt.args, w, got)
+			}
+		}
+		if a.ran != "" {
+			t.Errorf("%q: ran %q", tt.args, a.ran)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	root := &Command{Name: "app"}
+	for _, name := range []string{"status", "stash", "start", "commit"} {
+		root.Add(&Command{Name: name})
+	}
+	root.Add(&Command{Name: "checkout", Aliases: []string{"co"}}, &Command{Name: "stat", Hidden: true})
+	var tests = []struct {
+		name string
+		want []string
+	}{
+		{"stats", []string{"status", "stash", "start"}},
+		{"sta", []string{"status", "stash", "start"}},
+		{"comit", []string{"commit"}},
+		{"cp", []string{"checkout"}},
+		{"xyzzy", nil},
+	}
+	for _, tt := range tests {
+		if got := root.suggest(tt.name); !slices.Equal(got, tt.want) {
+			t.Errorf("suggest(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	var tests = []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"foo", "fo", 1},
+		{"héllo", "hello", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package clock puts time behind an interface so code that waits can be
+// tested without waiting.
+//
+// Time.go, Timers.go, Tickers.go, Epoch.go and the RateLimiting examples call
+// time.Now, time.Sleep, time.Tick and time.After directly, so checking that a
+// limiter lets through one request every 200ms takes a second of real time.
+// Code written against a Clock takes Real in production and a Fake in tests,
+// where Advance moves time forward and fires every timer, ticker and After
+// channel due on the way, in order, in microseconds.
+package clock
+
+import "time"
+
+// Clock is the part of the time package that depends on the current time.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	Tick(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) *Timer
+	NewTicker(d time.Duration) *Ticker
+	AfterFunc(d time.Duration, f func()) *Timer
+}
+
+// Timer is a time.Timer from either kind of clock.
+type Timer struct {
+	C <-chan time.Time
+
+	real *time.Timer
+	fake *waiter
+}
+
+// Stop prevents the timer from firing and reports whether it did so. As with
+// time.Timer since Go 1.23, no stale value is received from C afterwards.
+func (t *Timer) Stop() bool {
+	if t.real != nil {
+		return t.real.Stop()
+	}
+	return t.fake.stop()
+}
+
+// Reset changes the timer to fire after d and reports whether it had been
+// pending.
+func (t *Timer) Reset(d time.Duration) bool {
+	if t.real != nil {
+		return t.real.Reset(d)
+	}
+	return t.fake.reset(d, 0)
+}
+
+// Ticker is a time.Ticker from either kind of clock.
+type Ticker struct {
+	C <-chan time.Time
+
+	real *time.Ticker
+	fake *waiter
+}
+
+// Stop turns the ticker off.
+func (t *Ticker) Stop() {
+	if t.real != nil {
+		t.real.Stop()
+		return
+	}
+	t.fake.stop()
+}
+
+// Reset stops the ticker and restarts it with period d. It panics if d is
+// not positive.
+func (t *Ticker) Reset(d time.Duration) {
+	if t.real != nil {
+		t.real.Reset(d)
+		return
+	}
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.fake.reset(d, d)
+}
+
+// Real is the system clock: every method calls its namesake in package time.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) Until(t time.Time) time.Duration        { return time.Until(t) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Tick(d time.Duration) <-chan time.Time  { return time.Tick(d) }
+
+func (realClock) NewTimer(d time.Duration) *Timer {
+	t := time.NewTimer(d)
+	return &Timer{C: t.C, real: t}
+}
+
+func (realClock) NewTicker(d time.Duration) *Ticker {
+	t := time.NewTicker(d)
+	return &Ticker{C: t.C, real: t}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) *Timer {
+	return &Timer{real: time.AfterFunc(d, f)}
+}
@@ -0,0 +1,29 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReal(t *testing.T) {
+	c := Real
+	start := c.Now()
+	c.Sleep(time.Millisecond)
+	if c.Since(start) < time.Millisecond {
+		t.Errorf("Since() = %v after sleeping 1ms", c.Since(start))
+	}
+
+	timer := c.NewTimer(time.Hour)
+	if !timer.Stop() {
+		t.Error("Stop() = false for a pending timer")
+	}
+	<-c.After(time.Millisecond)
+
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C
+	ticker.Stop()
+
+	done := make(chan struct{})
+	c.AfterFunc(time.Millisecond, func() { close(done) })
+	<-done
+}
@@ -0,0 +1,258 @@
+package clock
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Its zero value is not
+// usable; create one with NewFake.
+//
+// Timers, tickers and sleepers wait on the fake until Advance or Set moves
+// it past their deadline. Their channels behave like the time package's: a
+// timer's or ticker's C holds one value, and a ticker drops ticks its reader
+// is too slow for. AfterFunc functions are called in the goroutine calling
+// Advance, so they have returned by the time Advance does.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond // signalled when waiters are added
+	now     time.Time
+	waiters waiterHeap
+	seq     uint64
+}
+
+// NewFake returns a fake clock reading now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+// waiter is a pending timer, ticker or sleeper.
+type waiter struct {
+	f      *Fake
+	when   time.Time
+	period time.Duration // non-zero for tickers
+	seq    uint64        // breaks ties in the order waiters were added
+	c      chan time.Time
+	fn     func()
+	index  int // in the heap, or -1
+}
+
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if !h[i].when.Equal(h[j].when) {
+		return h[i].when.Before(h[j].when)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	w.index = -1
+	return w
+}
+
+// Now returns the fake's current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration { return f.Now().Sub(t) }
+
+// Until returns the fake time left until t.
+func (f *Fake) Until(t time.Time) time.Duration { return t.Sub(f.Now()) }
+
+// Sleep blocks until the fake has been advanced by d.
+func (f *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-f.After(d)
+}
+
+// After returns a channel that receives the fake time once it has advanced
+// by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time { return f.NewTimer(d).C }
+
+// Tick returns the channel of a ticker that is never stopped, or nil if d
+// is not positive, as time.Tick does.
+func (f *Fake) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	return f.NewTicker(d).C
+}
+
+// NewTimer returns a timer that sends the fake time on its C once the fake
+// has advanced by d. A timer for d <= 0 fires at once.
+func (f *Fake) NewTimer(d time.Duration) *Timer {
+	c := make(chan time.Time, 1)
+	w := &waiter{f: f, c: c, index: -1}
+	w.reset(d, 0)
+	return &Timer{C: c, fake: w}
+}
+
+// NewTicker returns a ticker that sends the fake time on its C every time
+// the fake passes another multiple of d. It panics if d is not positive.
+func (f *Fake) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	c := make(chan time.Time, 1)
+	w := &waiter{f: f, c: c, index: -1}
+	w.reset(d, d)
+	return &Ticker{C: c, fake: w}
+}
+
+// AfterFunc calls f once the fake has advanced by d, in the goroutine that
+// advances it. For d <= 0, f is called at once in a goroutine of its own.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) *Timer {
+	w := &waiter{f: f, fn: fn, index: -1}
+	w.reset(d, 0)
+	return &Timer{fake: w}
+}
+
+// Advance moves the fake forward by d, firing everything due on the way in
+// deadline order. While each fires, Now reads its deadline.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the fake to t, firing everything due by then as Advance does.
+// Moving backwards fires nothing.
+func (f *Fake) Set(t time.Time) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) == 0 || f.waiters[0].when.After(t) {
+			f.now = t
+			f.mu.Unlock()
+			return
+		}
+		w := f.waiters[0]
+		if w.when.After(f.now) {
+			f.now = w.when
+		}
+		now := f.now
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+			f.seq++
+			w.seq = f.seq
+			heap.Fix(&f.waiters, 0)
+		} else {
+			heap.Pop(&f.waiters)
+		}
+		if w.fn == nil {
+			// Sent under the lock, so a Stop or Reset that follows can't
+			// miss the value and leave it stale in the channel.
+			w.send(now)
+			f.mu.Unlock()
+			continue
+		}
+		f.mu.Unlock()
+		w.fn()
+	}
+}
+
+// Pending returns the number of timers, tickers and sleepers waiting on the
+// fake.
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n timers, tickers and sleepers are waiting
+// on the fake. Tests call it before Advance to be sure the goroutine under
+// test has reached its Sleep or select.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.changed.Wait()
+	}
+}
+
+// send delivers a tick or timeout, dropping it if the last one hasn't been
+// received.
+func (w *waiter) send(now time.Time) {
+	select {
+	case w.c <- now:
+	default:
+	}
+}
+
+// reset (re)schedules w for d from now, repeating every period if that is
+// non-zero, and reports whether it was pending.
+func (w *waiter) reset(d, period time.Duration) bool {
+	f := w.f
+	f.mu.Lock()
+	pending := w.index >= 0
+	if pending {
+		heap.Remove(&f.waiters, w.index)
+	}
+	w.drain()
+	w.period = period
+	w.when = f.now.Add(d)
+	if d <= 0 && period == 0 {
+		// Due already: fire without waiting for the next Advance.
+		if w.fn != nil {
+			go w.fn()
+		} else {
+			w.send(f.now)
+		}
+		f.mu.Unlock()
+		return pending
+	}
+	f.seq++
+	w.seq = f.seq
+	heap.Push(&f.waiters, w)
+	f.changed.Broadcast()
+	f.mu.Unlock()
+	return pending
+}
+
+func (w *waiter) stop() bool {
+	f := w.f
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.drain()
+	if w.index < 0 {
+		return false
+	}
+	heap.Remove(&f.waiters, w.index)
+	return true
+}
+
+// drain empties w's channel so Stop and Reset leave no stale value behind.
+func (w *waiter) drain() {
+	if w.c == nil {
+		return
+	}
+	select {
+	case <-w.c:
+	default:
+	}
+}
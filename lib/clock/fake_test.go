@@ -0,0 +1,210 @@
+package clock
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 8, 18, 14, 52, 23, 0, time.UTC)
+
+func TestFakeFiresInOrder(t *testing.T) {
+	f := NewFake(epoch)
+	var got []string
+	at := func(name string) func() {
+		return func() { got = append(got, fmt.Sprintf("%s@%v", name, f.Since(epoch))) }
+	}
+	f.AfterFunc(3*time.Second, at("c"))
+	f.AfterFunc(time.Second, at("a"))
+	f.AfterFunc(2*time.Second, at("b1"))
+	f.AfterFunc(2*time.Second, at("b2")) // same deadline: order added
+	stopped := f.AfterFunc(2500*time.Millisecond, at("stopped"))
+	if !stopped.Stop() {
+		t.Error("Stop() = false for a pending timer")
+	}
+
+	f.Advance(1500 * time.Millisecond)
+	if want := []string{"a@1s"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	f.Advance(10 * time.Second)
+	want := []string{"a@1s", "b1@2s", "b2@2s", "c@3s"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := f.Since(epoch), 11500*time.Millisecond; got != want {
+		t.Errorf("Since() = %v, want %v", got, want)
+	}
+	if f.Pending() != 0 {
+		t.Errorf("Pending() = %d", f.Pending())
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Minute)
+	f.Advance(59 * time.Second)
+	select {
+	case <-timer.C:
+		t.Fatal("fired early")
+	default:
+	}
+	f.Advance(5 * time.Second)
+	if got, want := <-timer.C, epoch.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if timer.Stop() {
+		t.Error("Stop() = true for a fired timer")
+	}
+
+	// Reset after firing but before receiving leaves no stale value.
+	f.Advance(0)
+	timer.Reset(time.Second)
+	f.Advance(time.Second)
+	if timer.Reset(time.Second) {
+		t.Error("Reset() = true for a fired timer")
+	}
+	select {
+	case <-timer.C:
+		t.Error("stale value after Reset")
+	default:
+	}
+	f.Advance(time.Second)
+	<-timer.C
+
+	select {
+	case <-f.After(0):
+	default:
+		t.Error("After(0) didn't fire at once")
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(500 * time.Millisecond)
+	var ticks []time.Duration
+	for i := 0; i < 3; i++ {
+		f.Advance(500 * time.Millisecond)
+		ticks = append(ticks, (<-ticker.C).Sub(epoch))
+	}
+	want := []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond}
+	if !slices.Equal(ticks, want) {
+		t.Errorf("got %v, want %v", ticks, want)
+	}
+
+	// A slow reader gets one tick, the first one, and the rest are dropped.
+	f.Advance(2 * time.Second)
+	if got, want := (<-ticker.C).Sub(epoch), 2*time.Second; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	select {
+	case <-ticker.C:
+		t.Error("a dropped tick was delivered")
+	default:
+	}
+
+	ticker.Reset(time.Second)
+	f.Advance(500 * time.Millisecond)
+	select {
+	case <-ticker.C:
+		t.Error("ticked on the old period after Reset")
+	default:
+	}
+	ticker.Stop()
+	f.Advance(time.Hour)
+	select {
+	case <-ticker.C:
+		t.Error("ticked after Stop")
+	default:
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := NewFake(epoch)
+	woke := make(chan time.Time)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			f.Sleep(time.Duration(i) * time.Second)
+			woke <- f.Now()
+		}()
+	}
+	f.BlockUntil(3)
+	f.Advance(2 * time.Second)
+	got := []time.Time{<-woke, <-woke}
+	select {
+	case <-woke:
+		t.Fatal("the 3s sleeper woke after 2s")
+	default:
+	}
+	f.Advance(time.Second)
+	got = append(got, <-woke)
+	for _, w := range got {
+		if w.Before(epoch.Add(time.Second)) {
+			t.Errorf("woke at %v", w)
+		}
+	}
+}
+
+// TestRateLimiting is RateLimiting.go on a fake clock: requests are served
+// one per 200ms tick, except for a burst of three up front, and the test
+// takes no real time.
+func TestRateLimiting(t *testing.T) {
+	f := NewFake(epoch)
+	limiter := f.Tick(200 * time.Millisecond)
+
+	served := make(chan time.Duration)
+	go func() {
+		for i := 0; i < 5; i++ {
+			<-limiter
+			served <- f.Since(epoch)
+		}
+	}()
+	for i := 1; i <= 5; i++ {
+		f.BlockUntil(1)
+		f.Advance(200 * time.Millisecond)
+		if got, want := <-served, time.Duration(i)*200*time.Millisecond; got != want {
+			t.Errorf("request %d served at %v, want %v", i, got, want)
+		}
+	}
+
+	bursty := make(chan time.Time, 3)
+	for i := 0; i < 3; i++ {
+		bursty <- f.Now()
+	}
+	refill := f.NewTicker(200 * time.Millisecond)
+	defer refill.Stop()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2; i++ {
+			bursty <- <-refill.C
+		}
+	}()
+	start := f.Now()
+	var at []time.Duration
+	for i := 0; i < 5; i++ {
+		if len(bursty) == 0 {
+			f.Advance(200 * time.Millisecond)
+		}
+		at = append(at, (<-bursty).Sub(start))
+	}
+	wg.Wait()
+	want := []time.Duration{0, 0, 0, 200 * time.Millisecond, 400 * time.Millisecond}
+	if !slices.Equal(at, want) {
+		t.Errorf("got %v, want %v", at, want)
+	}
+}
+
+func BenchmarkFakeAdvance(b *testing.B) {
+	f := NewFake(epoch)
+	for i := 0; i < 1000; i++ {
+		f.NewTicker(time.Duration(i+1) * time.Millisecond)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Advance(time.Millisecond)
+	}
+}
@@ -0,0 +1,123 @@
+// Command counterbench measures the mutex, atomic and channel-owned counters
+// from the concurrency examples at several goroutine counts and GOMAXPROCS
+// values, and prints ns/op, allocations and throughput.
+//
+//	counterbench                                # the default sweep, as a table
+//	counterbench -goroutines 1,8,64 -procs 1,4  # a smaller sweep
+//	counterbench -reads 90 -csv results.csv     # read-heavy, CSV saved as well
+//	counterbench -format csv > results.csv      # CSV only
+//
+// For the same measurements through go test, see BenchmarkCounters in the
+// counters package.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/counters"
+)
+
+func main() {
+	impls := flag.String("impl", "mutex,atomic,channel", "comma-separated implementations to measure")
+	goroutines := flag.String("goroutines", "1,4,16,64", "comma-separated goroutine counts")
+	procs := flag.String("procs", defaultProcs(), "comma-separated GOMAXPROCS values")
+	reads := flag.Int("reads", 0, "percentage of operations that read the counter instead of incrementing it")
+	benchtime := flag.Duration("benchtime", 500*time.Millisecond, "minimum duration of each measurement")
+	format := flag.String("format", "table", "output format: table or csv")
+	csvPath := flag.String("csv", "", "also write the results as CSV to this file")
+	verbose := flag.Bool("v", false, "report each measurement on stderr as it finishes")
+	flag.Parse()
+
+	var selected []counters.Impl
+	for _, name := range strings.Split(*impls, ",") {
+		impl, ok := counters.Find(strings.TrimSpace(name))
+		if !ok {
+			fatal(fmt.Errorf("unknown implementation %q", name))
+		}
+		selected = append(selected, impl)
+	}
+	gs, err := parseInts(*goroutines)
+	if err != nil {
+		fatal(fmt.Errorf("-goroutines: %w", err))
+	}
+	ps, err := parseInts(*procs)
+	if err != nil {
+		fatal(fmt.Errorf("-procs: %w", err))
+	}
+	if *format != "table" && *format != "csv" {
+		fatal(fmt.Errorf("unknown format %q", *format))
+	}
+
+	var done func(counters.Result)
+	if *verbose {
+		done = func(r counters.Result) {
+			fmt.Fprintf(os.Stderr, "%-8s goroutines=%-4d gomaxprocs=%-3d %10.2f ns/op\n", r.Impl, r.Goroutines, r.Procs, r.NsPerOp)
+		}
+	}
+	results, err := counters.Sweep(selected, gs, ps, *reads, *benchtime, done)
+	if err != nil {
+		fatal(err)
+	}
+
+	if *format == "csv" {
+		err = counters.WriteCSV(os.Stdout, results)
+	} else {
+		err = counters.WriteTable(os.Stdout, results)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if *csvPath != "" {
+		if err := writeCSVFile(*csvPath, results); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// defaultProcs is 1, 2, 4, ... up to the number of CPUs, which is always
+// included.
+func defaultProcs() string {
+	var ps []string
+	for p := 1; p < runtime.NumCPU(); p *= 2 {
+		ps = append(ps, strconv.Itoa(p))
+	}
+	return strings.Join(append(ps, strconv.Itoa(runtime.NumCPU())), ",")
+}
+
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("%d is less than 1", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func writeCSVFile(path string, results []counters.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := counters.WriteCSV(f, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,193 @@
+// Command examples lists, builds and runs the example programs in the
+// repository root, each in a temp module of its own.
+//
+//	examples list
+//	examples build [-o file] WorkerPool
+//	examples run [-timeout 10s] [-stdin file] WorkerPool [args...]
+//	examples all [-timeout 10s]
+//
+// The examples are looked up in -dir, which defaults to the nearest parent
+// directory that holds Learning.md.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/examples"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: examples [-dir path] list | build [-o file] NAME | run [-timeout d] [-stdin file] NAME [args...] | all [-timeout d]")
+	os.Exit(2)
+}
+
+func main() {
+	dir := flag.String("dir", "", "directory holding the examples (default: nearest parent with Learning.md)")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+	}
+
+	root := *dir
+	if root == "" {
+		var err error
+		if root, err = examples.FindRoot("."); err != nil {
+			fatal(err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// As in CmdSubCommands.go, every subcommand gets a FlagSet of its own.
+	args := flag.Args()
+	switch args[0] {
+	case "list":
+		list(root)
+	case "build":
+		cmd := flag.NewFlagSet("build", flag.ExitOnError)
+		out := cmd.String("o", "", "output file (default: the example name)")
+		cmd.Parse(args[1:])
+		if cmd.NArg() != 1 {
+			usage()
+		}
+		if err := build(ctx, root, cmd.Arg(0), *out); err != nil {
+			fatal(err)
+		}
+	case "run":
+		cmd := flag.NewFlagSet("run", flag.ExitOnError)
+		timeout := cmd.Duration("timeout", 10*time.Second, "kill the example after this long")
+		stdin := cmd.String("stdin", "", "file to feed the example on stdin")
+		cmd.Parse(args[1:])
+		if cmd.NArg() < 1 {
+			usage()
+		}
+		code, err := run(ctx, root, cmd.Arg(0), cmd.Args()[1:], *timeout, *stdin)
+		if err != nil {
+			fatal(err)
+		}
+		os.Exit(code)
+	case "all":
+		cmd := flag.NewFlagSet("all", flag.ExitOnError)
+		timeout := cmd.Duration("timeout", 5*time.Second, "kill each example after this long")
+		cmd.Parse(args[1:])
+		if !all(ctx, root, *timeout) {
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+func list(root string) {
+	list, err := examples.List(root)
+	if err != nil {
+		fatal(err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, ex := range list {
+		fmt.Fprintf(w, "%s\t%s\n", ex.Name, ex.Kind)
+	}
+	w.Flush()
+}
+
+// build and run return their errors rather than calling fatal, so their
+// deferred cleanup, such as removing the temp module, runs before the exit.
+func build(ctx context.Context, root, name, out string) error {
+	ex, err := examples.Find(root, name)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		out = ex.Name
+	}
+	m, err := examples.NewModule(root, ex, "")
+	if err != nil {
+		return err
+	}
+	defer m.Remove()
+	bin, err := m.Build(ctx, out)
+	if err != nil {
+		return err
+	}
+	fmt.Println(bin)
+	return nil
+}
+
+func run(ctx context.Context, root, name string, args []string, timeout time.Duration, stdin string) (int, error) {
+	ex, err := examples.Find(root, name)
+	if err != nil {
+		return 0, err
+	}
+	opts := examples.Options{Timeout: timeout, Args: args}
+	if stdin != "" {
+		f, err := os.Open(stdin)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		opts.Stdin = f
+	}
+	res, err := examples.Run(ctx, root, ex, opts)
+	if err != nil {
+		return 0, err
+	}
+	os.Stdout.Write(res.Stdout)
+	os.Stderr.Write(res.Stderr)
+	if res.TimedOut {
+		fmt.Fprintf(os.Stderr, "examples: %s killed after %v\n", ex.Name, timeout)
+	}
+	return res.ExitCode, nil
+}
+
+// all runs every example and prints one line per example, so the whole
+// collection can be checked in one go. It reports whether every example
+// built and exited cleanly; timing out counts as passing, since the servers
+// among the examples never exit on their own.
+func all(ctx context.Context, root string, timeout time.Duration) bool {
+	list, err := examples.List(root)
+	if err != nil {
+		fatal(err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "EXAMPLE\tRESULT\tTIME\tDETAIL")
+	failed := 0
+	for _, ex := range list {
+		if ctx.Err() != nil {
+			break
+		}
+		res, err := examples.Run(ctx, root, ex, examples.Options{Timeout: timeout})
+		var buildErr *examples.BuildError
+		switch {
+		case errors.As(err, &buildErr):
+			failed++
+			fmt.Fprintf(w, "%s\tbuild failed\t-\t%s\n", ex.Name, examples.FirstLine([]byte(buildErr.Output)))
+		case err != nil:
+			failed++
+			fmt.Fprintf(w, "%s\terror\t-\t%v\n", ex.Name, err)
+		case res.TimedOut:
+			fmt.Fprintf(w, "%s\ttimed out\t%v\t%s\n", ex.Name, res.Duration.Round(time.Millisecond), examples.FirstLine(res.Stdout))
+		case res.ExitCode != 0:
+			failed++
+			fmt.Fprintf(w, "%s\texit %d\t%v\t%s\n", ex.Name, res.ExitCode, res.Duration.Round(time.Millisecond), examples.FirstLine(res.Stderr))
+		default:
+			fmt.Fprintf(w, "%s\tok\t%v\t%s\n", ex.Name, res.Duration.Round(time.Millisecond), examples.FirstLine(res.Stdout))
+		}
+		w.Flush()
+	}
+	fmt.Printf("\n%d of %d examples failed\n", failed, len(list))
+	return failed == 0 && ctx.Err() == nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
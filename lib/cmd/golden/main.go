@@ -0,0 +1,165 @@
+// Command golden runs the examples that document their output in an
+// "Output:" block and reports any drift between that block and what the
+// example really prints.
+//
+//	golden                      # check every example with an output block
+//	golden -run 'Rate|Worker'   # only examples whose name matches
+//	golden -list                # show the extracted cases without running
+//
+// Normalization rules, unordered lines and skips live in golden.json, which
+// is built in; -config points at a different file.
+package main
+
+import (
+	"context"
+	_ "embed"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/examples"
+	"github.com/prashant1k99/GoLearn/lib/golden"
+)
+
+//go:embed golden.json
+var defaultConfig []byte
+
+func main() {
+	dir := flag.String("dir", "", "directory holding the examples (default: nearest parent with Learning.md)")
+	configPath := flag.String("config", "", "JSON config file (default: the built-in golden.json)")
+	run := flag.String("run", "", "only check examples whose name matches this regexp")
+	list := flag.Bool("list", false, "print the extracted cases and exit")
+	verbose := flag.Bool("v", false, "print passing cases too")
+	flag.Parse()
+
+	root := *dir
+	if root == "" {
+		var err error
+		if root, err = examples.FindRoot("."); err != nil {
+			fatal(err)
+		}
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fatal(err)
+	}
+	var filter *regexp.Regexp
+	if *run != "" {
+		if filter, err = regexp.Compile(*run); err != nil {
+			fatal(err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	all, err := examples.List(root)
+	if err != nil {
+		fatal(err)
+	}
+	var checked, failed, skipped int
+	for _, ex := range all {
+		if filter != nil && !filter.MatchString(ex.Name) {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(root, ex.File))
+		if err != nil {
+			fatal(err)
+		}
+		cases, err := golden.Extract(ex.File, src)
+		if err != nil {
+			// An example that doesn't parse can't be checked, and quietly
+			// leaving it out would read as a pass.
+			failed++
+			fmt.Printf("FAIL  %s\n%s", ex.File, indent(err.Error()))
+			continue
+		}
+		if len(cases) == 0 {
+			continue
+		}
+		ec := cfg.Examples[ex.Name]
+
+		if *list {
+			for _, c := range cases {
+				fmt.Printf("%s:%d args=%q\n", ex.File, c.Line, c.Args)
+				for _, l := range c.Expected {
+					fmt.Println("    " + l)
+				}
+			}
+			continue
+		}
+		if ec.Skip != "" {
+			skipped++
+			fmt.Printf("SKIP  %s: %s\n", ex.Name, ec.Skip)
+			continue
+		}
+
+		m, err := cfg.NewMatcher(ex.Name)
+		if err != nil {
+			fatal(err)
+		}
+		timeout := time.Duration(cfg.Timeout)
+		if ec.Timeout > 0 {
+			timeout = time.Duration(ec.Timeout)
+		}
+		for _, c := range cases {
+			checked++
+			name := fmt.Sprintf("%s:%d", ex.File, c.Line)
+			if len(c.Args) > 0 {
+				name += " " + strings.Join(c.Args, " ")
+			}
+			res, err := examples.Run(ctx, root, ex, examples.Options{
+				Timeout: timeout,
+				Args:    c.Args,
+				Stdin:   strings.NewReader(ec.Stdin),
+			})
+			if err != nil {
+				failed++
+				fmt.Printf("FAIL  %s\n%v\n", name, err)
+				continue
+			}
+			if d := m.Compare(c.Expected, string(res.Stdout)+string(res.Stderr)); d != "" {
+				failed++
+				fmt.Printf("FAIL  %s\n%s", name, indent(d))
+				if res.TimedOut {
+					fmt.Printf("    (killed after %v)\n", timeout)
+				}
+				continue
+			}
+			if *verbose {
+				fmt.Printf("ok    %s (%v)\n", name, res.Duration.Round(time.Millisecond))
+			}
+		}
+	}
+	if *list {
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Printf("%d cases checked, %d failed, %d examples skipped\n", checked, failed, skipped)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func loadConfig(path string) (*golden.Config, error) {
+	if path == "" {
+		return golden.ParseConfig(defaultConfig)
+	}
+	return golden.LoadConfig(path)
+}
+
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n    ") + "\n"
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
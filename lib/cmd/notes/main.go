@@ -0,0 +1,79 @@
+// Command notes builds the Learning*.md notes into an HTML site and can serve
+// it. Run from this directory, the repository root is ../../..:
+//
+//	notes -src ../../.. -out public     # write the site to a directory
+//	notes -src ../../.. -serve :8080    # build in memory and serve it
+//	notes -serve :8080                  # serve the copy embedded in the binary
+//
+// The embedded copy under site/ is regenerated with go generate.
+package main
+
+//go:generate go run . -src ../../.. -out site
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/prashant1k99/GoLearn/lib/notes"
+	"github.com/prashant1k99/GoLearn/lib/static"
+)
+
+//go:embed all:site
+var embedded embed.FS
+
+func main() {
+	src := flag.String("src", "", "directory holding Learning*.md and the example files; empty serves the embedded site")
+	out := flag.String("out", "", "write the generated site to this directory")
+	addr := flag.String("serve", "", "serve the site on this address, e.g. :8080")
+	flag.Parse()
+
+	if *src == "" && *out != "" {
+		log.Fatal("notes: -out needs -src")
+	}
+	if *out == "" && *addr == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var site fs.FS
+	if *src == "" {
+		sub, err := fs.Sub(embedded, "site")
+		if err != nil {
+			log.Fatal(err)
+		}
+		site = sub
+	} else {
+		fsys := os.DirFS(*src)
+		n, err := notes.Load(fsys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		built, err := n.Build(fsys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("built %d topics from %d notes files and %d examples\n", len(n.Topics), len(n.Sources), len(n.Examples))
+		if *out != "" {
+			if err := built.WriteDir(*out); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println("wrote", *out)
+		}
+		site = built.FS()
+	}
+
+	if *addr == "" {
+		return
+	}
+	h, err := static.New(site)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("serving notes on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, h))
+}
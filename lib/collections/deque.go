@@ -0,0 +1,118 @@
+package collections
+
+import "iter"
+
+// Deque is a double-ended queue in a growable ring buffer: pushing and
+// popping at either end is O(1) amortized, and At is O(1). The zero value is
+// an empty deque ready to use.
+type Deque[T any] struct {
+	buf  []T
+	head int // index of the front element in buf
+	len  int
+}
+
+// Len returns the number of values in the deque.
+func (d *Deque[T]) Len() int { return d.len }
+
+func (d *Deque[T]) grow() {
+	if d.len < len(d.buf) {
+		return
+	}
+	buf := make([]T, max(2*len(d.buf), 8))
+	n := copy(buf, d.buf[d.head:])
+	copy(buf[n:], d.buf[:d.head])
+	d.buf, d.head = buf, 0
+}
+
+// index maps a position in the deque to an index in buf.
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+// PushBack adds v at the back.
+func (d *Deque[T]) PushBack(v T) {
+	d.grow()
+	d.buf[d.index(d.len)] = v
+	d.len++
+}
+
+// PushFront adds v at the front.
+func (d *Deque[T]) PushFront(v T) {
+	d.grow()
+	d.head = (d.head - 1 + len(d.buf)) % len(d.buf)
+	d.buf[d.head] = v
+	d.len++
+}
+
+// PopFront removes and returns the front value. ok is false if the deque is
+// empty.
+func (d *Deque[T]) PopFront() (v T, ok bool) {
+	if d.len == 0 {
+		return v, false
+	}
+	var zero T
+	v, d.buf[d.head] = d.buf[d.head], zero
+	d.head = d.index(1)
+	d.len--
+	return v, true
+}
+
+// PopBack removes and returns the back value. ok is false if the deque is
+// empty.
+func (d *Deque[T]) PopBack() (v T, ok bool) {
+	if d.len == 0 {
+		return v, false
+	}
+	var zero T
+	i := d.index(d.len - 1)
+	v, d.buf[i] = d.buf[i], zero
+	d.len--
+	return v, true
+}
+
+// Front returns the front value without removing it.
+func (d *Deque[T]) Front() (v T, ok bool) {
+	if d.len == 0 {
+		return v, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the back value without removing it.
+func (d *Deque[T]) Back() (v T, ok bool) {
+	if d.len == 0 {
+		return v, false
+	}
+	return d.buf[d.index(d.len-1)], true
+}
+
+// At returns the i'th value from the front. It panics if i is out of range.
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.len {
+		panic("collections: Deque index out of range")
+	}
+	return d.buf[d.index(i)]
+}
+
+// All returns an iterator over the positions and values from front to back.
+func (d *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < d.len; i++ {
+			if !yield(i, d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the positions and values from back to
+// front.
+func (d *Deque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := d.len - 1; i >= 0; i-- {
+			if !yield(i, d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package collections
+
+import (
+	"slices"
+	"testing"
+)
+
+func collectDeque[T any](d *Deque[T]) []T {
+	var out []T
+	for _, v := range d.All() {
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestDeque(t *testing.T) {
+	var d Deque[int]
+	if _, ok := d.PopFront(); ok {
+		t.Error("PopFront on an empty deque succeeded")
+	}
+	if _, ok := d.Back(); ok {
+		t.Error("Back on an empty deque succeeded")
+	}
+	// Enough pushes at both ends to wrap around and grow a few times.
+	for i := 1; i <= 20; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	if d.Len() != 40 {
+		t.Fatalf("Len() = %d", d.Len())
+	}
+	if v, _ := d.Front(); v != -20 {
+		t.Errorf("Front() = %d", v)
+	}
+	if v, _ := d.Back(); v != 20 {
+		t.Errorf("Back() = %d", v)
+	}
+	if d.At(19) != -1 || d.At(20) != 1 {
+		t.Errorf("At(19), At(20) = %d, %d", d.At(19), d.At(20))
+	}
+	for i := 20; i >= 1; i-- {
+		if v, _ := d.PopBack(); v != i {
+			t.Fatalf("PopBack() = %d, want %d", v, i)
+		}
+		if v, _ := d.PopFront(); v != -i {
+			t.Fatalf("PopFront() = %d, want %d", v, -i)
+		}
+	}
+	if d.Len() != 0 {
+		t.Errorf("Len() = %d after popping everything", d.Len())
+	}
+}
+
+func TestDequeIterators(t *testing.T) {
+	var d Deque[string]
+	d.PushBack("b")
+	d.PushBack("c")
+	d.PushFront("a")
+	if got := collectDeque(&d); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("All() = %q", got)
+	}
+	var back []string
+	for i, v := range d.Backward() {
+		if d.At(i) != v {
+			t.Errorf("Backward() gave %q at %d, At says %q", v, i, d.At(i))
+		}
+		back = append(back, v)
+	}
+	if !slices.Equal(back, []string{"c", "b", "a"}) {
+		t.Errorf("Backward() = %q", back)
+	}
+}
+
+func TestDequeAtPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("At(1) on a one-value deque did not panic")
+		}
+	}()
+	var d Deque[int]
+	d.PushBack(1)
+	d.At(1)
+}
+
+func FuzzDeque(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3, 0, 0, 1})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		var d Deque[int]
+		var want []int
+		for i, op := range ops {
+			switch op % 4 {
+			case 0:
+				d.PushBack(i)
+				want = append(want, i)
+			case 1:
+				d.PushFront(i)
+				want = slices.Insert(want, 0, i)
+			case 2:
+				v, ok := d.PopFront()
+				if ok != (len(want) > 0) || ok && v != want[0] {
+					t.Fatalf("PopFront() = %d, %v; want %v", v, ok, want)
+				}
+				if ok {
+					want = want[1:]
+				}
+			case 3:
+				v, ok := d.PopBack()
+				if ok != (len(want) > 0) || ok && v != want[len(want)-1] {
+					t.Fatalf("PopBack() = %d, %v; want %v", v, ok, want)
+				}
+				if ok {
+					want = want[:len(want)-1]
+				}
+			}
+		}
+		if got := collectDeque(&d); !slices.Equal(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func BenchmarkDeque(b *testing.B) {
+	b.ReportAllocs()
+	var d Deque[int]
+	for i := 0; i < b.N; i++ {
+		d.PushBack(i)
+		if d.Len() > 1000 {
+			d.PopFront()
+		}
+	}
+}
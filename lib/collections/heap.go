@@ -0,0 +1,113 @@
+package collections
+
+import (
+	"cmp"
+	"iter"
+)
+
+// PriorityQueue is a binary heap: Pop always returns the value that sorts
+// first under the queue's less function. Push and Pop are O(log n).
+type PriorityQueue[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+// NewPriorityQueue returns an empty queue ordered by less. Pass a "greater"
+// function for a max-heap.
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{less: less}
+}
+
+// NewMinQueue returns an empty queue that pops its smallest value first.
+func NewMinQueue[T cmp.Ordered]() *PriorityQueue[T] {
+	return NewPriorityQueue(cmp.Less[T])
+}
+
+// Len returns the number of queued values.
+func (pq *PriorityQueue[T]) Len() int { return len(pq.items) }
+
+// Push adds v to the queue.
+func (pq *PriorityQueue[T]) Push(v T) {
+	pq.items = append(pq.items, v)
+	pq.up(len(pq.items) - 1)
+}
+
+// Peek returns the first value without removing it. ok is false if the queue
+// is empty.
+func (pq *PriorityQueue[T]) Peek() (v T, ok bool) {
+	if len(pq.items) == 0 {
+		return v, false
+	}
+	return pq.items[0], true
+}
+
+// Pop removes and returns the first value. ok is false if the queue is empty.
+func (pq *PriorityQueue[T]) Pop() (v T, ok bool) {
+	n := len(pq.items) - 1
+	if n < 0 {
+		return v, false
+	}
+	v = pq.items[0]
+	pq.items[0] = pq.items[n]
+	var zero T
+	pq.items[n] = zero
+	pq.items = pq.items[:n]
+	if n > 0 {
+		pq.down(0)
+	}
+	return v, true
+}
+
+func (pq *PriorityQueue[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !pq.less(pq.items[i], pq.items[parent]) {
+			return
+		}
+		pq.items[i], pq.items[parent] = pq.items[parent], pq.items[i]
+		i = parent
+	}
+}
+
+func (pq *PriorityQueue[T]) down(i int) {
+	n := len(pq.items)
+	for {
+		first := i
+		if l := 2*i + 1; l < n && pq.less(pq.items[l], pq.items[first]) {
+			first = l
+		}
+		if r := 2*i + 2; r < n && pq.less(pq.items[r], pq.items[first]) {
+			first = r
+		}
+		if first == i {
+			return
+		}
+		pq.items[i], pq.items[first] = pq.items[first], pq.items[i]
+		i = first
+	}
+}
+
+// All returns an iterator over the queued values in heap order, which is not
+// sorted order; use Drain for that. The queue is left unchanged.
+func (pq *PriorityQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range pq.items {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Drain returns an iterator that pops values in priority order until the
+// queue is empty or the loop stops. Values not reached stay queued.
+func (pq *PriorityQueue[T]) Drain() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := pq.Pop()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package collections
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	pq := NewMinQueue[int]()
+	if _, ok := pq.Pop(); ok {
+		t.Error("Pop on an empty queue succeeded")
+	}
+	for _, v := range []int{5, 3, 8, 1, 9, 2, 7, 3} {
+		pq.Push(v)
+	}
+	if v, _ := pq.Peek(); v != 1 {
+		t.Errorf("Peek() = %d", v)
+	}
+	if pq.Len() != 8 {
+		t.Errorf("Len() = %d", pq.Len())
+	}
+	all := slices.Collect(pq.All())
+	slices.Sort(all)
+	if !slices.Equal(all, []int{1, 2, 3, 3, 5, 7, 8, 9}) {
+		t.Errorf("All() sorted = %v", all)
+	}
+	if got := slices.Collect(pq.Drain()); !slices.Equal(got, []int{1, 2, 3, 3, 5, 7, 8, 9}) {
+		t.Errorf("Drain() = %v", got)
+	}
+	if pq.Len() != 0 {
+		t.Errorf("Len() = %d after Drain", pq.Len())
+	}
+}
+
+func TestPriorityQueueCustomOrder(t *testing.T) {
+	type job struct {
+		name     string
+		priority int
+	}
+	pq := NewPriorityQueue(func(a, b job) bool { return a.priority > b.priority })
+	pq.Push(job{"low", 1})
+	pq.Push(job{"high", 10})
+	pq.Push(job{"mid", 5})
+
+	var got []string
+	for j := range pq.Drain() {
+		got = append(got, j.name)
+		if j.name == "high" {
+			break
+		}
+	}
+	if !slices.Equal(got, []string{"high"}) || pq.Len() != 2 {
+		t.Errorf("got %q with %d left", got, pq.Len())
+	}
+	if j, _ := pq.Pop(); j.name != "mid" {
+		t.Errorf("Pop() = %q", j.name)
+	}
+}
+
+func FuzzPriorityQueue(f *testing.F) {
+	f.Add([]byte{3, 1, 2})
+	f.Fuzz(func(t *testing.T, vs []byte) {
+		pq := NewMinQueue[byte]()
+		for _, v := range vs {
+			pq.Push(v)
+		}
+		got := slices.Collect(pq.Drain())
+		want := slices.Sorted(slices.Values(vs))
+		if !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func BenchmarkPriorityQueue(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	pq := NewMinQueue[int]()
+	for i := 0; i < 1000; i++ {
+		pq.Push(r.Int())
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pq.Push(r.Int())
+		pq.Pop()
+	}
+}
@@ -0,0 +1,183 @@
+// Package collections holds generic containers that grew out of the
+// singly-linked List[T] in Generics.go: a doubly-linked List, a Deque, a
+// binary-heap PriorityQueue, a Set with the usual set algebra, an
+// insertion-ordered OrderedSet, an LRU cache and a SortedMap with range and
+// rank queries. Each has Go 1.23 iterators, so they range like slices and
+// maps do:
+//
+//	for v := range list.All() {
+//		...
+//	}
+//
+// None of them are safe for concurrent use; guard them with a mutex as
+// Mutexes.go does for its map.
+package collections
+
+import "iter"
+
+// Element is a value in a List. Keep the pointer to remove the value or
+// insert next to it later.
+type Element[T any] struct {
+	Value T
+
+	next, prev *Element[T]
+	list       *List[T]
+}
+
+// Next returns the element after e, or nil at the back of the list.
+func (e *Element[T]) Next() *Element[T] {
+	if e.list == nil || e.next == &e.list.root {
+		return nil
+	}
+	return e.next
+}
+
+// Prev returns the element before e, or nil at the front of the list.
+func (e *Element[T]) Prev() *Element[T] {
+	if e.list == nil || e.prev == &e.list.root {
+		return nil
+	}
+	return e.prev
+}
+
+// List is a doubly-linked list. The zero value is an empty list ready to use.
+type List[T any] struct {
+	// root is a sentinel: root.next is the front and root.prev the back, so
+	// inserting and removing never special-case the ends.
+	root Element[T]
+	len  int
+}
+
+// NewList returns a list holding vs in order.
+func NewList[T any](vs ...T) *List[T] {
+	l := &List[T]{}
+	for _, v := range vs {
+		l.PushBack(v)
+	}
+	return l
+}
+
+func (l *List[T]) lazyInit() {
+	if l.root.next == nil {
+		l.root.next = &l.root
+		l.root.prev = &l.root
+	}
+}
+
+// Len returns the number of elements.
+func (l *List[T]) Len() int { return l.len }
+
+// Front returns the first element, or nil if the list is empty.
+func (l *List[T]) Front() *Element[T] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back returns the last element, or nil if the list is empty.
+func (l *List[T]) Back() *Element[T] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// insert puts a new element holding v after at.
+func (l *List[T]) insert(v T, at *Element[T]) *Element[T] {
+	e := &Element[T]{Value: v, list: l, prev: at, next: at.next}
+	at.next.prev = e
+	at.next = e
+	l.len++
+	return e
+}
+
+// PushFront adds v at the front and returns its element.
+func (l *List[T]) PushFront(v T) *Element[T] {
+	l.lazyInit()
+	return l.insert(v, &l.root)
+}
+
+// PushBack adds v at the back and returns its element.
+func (l *List[T]) PushBack(v T) *Element[T] {
+	l.lazyInit()
+	return l.insert(v, l.root.prev)
+}
+
+// InsertBefore adds v just before mark and returns its element. It returns
+// nil, leaving the list alone, if mark isn't in l.
+func (l *List[T]) InsertBefore(v T, mark *Element[T]) *Element[T] {
+	if mark.list != l {
+		return nil
+	}
+	return l.insert(v, mark.prev)
+}
+
+// InsertAfter adds v just after mark and returns its element. It returns nil,
+// leaving the list alone, if mark isn't in l.
+func (l *List[T]) InsertAfter(v T, mark *Element[T]) *Element[T] {
+	if mark.list != l {
+		return nil
+	}
+	return l.insert(v, mark)
+}
+
+// Remove takes e out of l, if it is in l, and returns its value.
+func (l *List[T]) Remove(e *Element[T]) T {
+	if e.list == l {
+		e.prev.next = e.next
+		e.next.prev = e.prev
+		e.next, e.prev, e.list = nil, nil, nil
+		l.len--
+	}
+	return e.Value
+}
+
+// MoveToFront moves e to the front of l, if it is in l.
+func (l *List[T]) MoveToFront(e *Element[T]) {
+	if e.list != l || l.root.next == e {
+		return
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = &l.root
+	e.next = l.root.next
+	l.root.next.prev = e
+	l.root.next = e
+}
+
+// All returns an iterator over the values from front to back.
+func (l *List[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.Front(); e != nil; e = e.Next() {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the values from back to front.
+func (l *List[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Elements returns an iterator over the elements from front to back. The
+// element being visited may be removed during the loop.
+func (l *List[T]) Elements() iter.Seq[*Element[T]] {
+	return func(yield func(*Element[T]) bool) {
+		for e := l.Front(); e != nil; {
+			next := e.Next()
+			if !yield(e) {
+				return
+			}
+			e = next
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package collections
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	var l List[int]
+	if l.Front() != nil || l.Back() != nil || l.Len() != 0 {
+		t.Fatal("zero List isn't empty")
+	}
+	two := l.PushBack(2)
+	l.PushFront(1)
+	four := l.PushBack(4)
+	l.InsertBefore(3, four)
+	l.InsertAfter(5, four)
+
+	if got := slices.Collect(l.All()); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("All() = %v", got)
+	}
+	if got := slices.Collect(l.Backward()); !slices.Equal(got, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("Backward() = %v", got)
+	}
+	if l.Len() != 5 || l.Front().Value != 1 || l.Back().Value != 5 {
+		t.Errorf("Len, Front, Back = %d, %d, %d", l.Len(), l.Front().Value, l.Back().Value)
+	}
+	if two.Prev().Value != 1 || two.Next().Value != 3 {
+		t.Errorf("neighbours of 2 are %d and %d", two.Prev().Value, two.Next().Value)
+	}
+	if l.Front().Prev() != nil || l.Back().Next() != nil {
+		t.Error("the ends have neighbours")
+	}
+
+	if v := l.Remove(two); v != 2 {
+		t.Errorf("Remove returned %d", v)
+	}
+	l.Remove(two) // already removed: no-op
+	l.MoveToFront(four)
+	if got := slices.Collect(l.All()); !slices.Equal(got, []int{4, 1, 3, 5}) {
+		t.Errorf("after Remove and MoveToFront, All() = %v", got)
+	}
+	if two.Next() != nil || two.Prev() != nil {
+		t.Error("removed element still linked")
+	}
+}
+
+func TestListForeignElements(t *testing.T) {
+	a, b := NewList(1, 2), NewList(3)
+	e := b.Front()
+	if a.InsertBefore(0, e) != nil || a.InsertAfter(0, e) != nil {
+		t.Error("inserted next to an element of another list")
+	}
+	a.Remove(e)
+	a.MoveToFront(e)
+	if a.Len() != 2 || b.Len() != 1 {
+		t.Errorf("lengths changed to %d and %d", a.Len(), b.Len())
+	}
+}
+
+func TestListRemoveWhileIterating(t *testing.T) {
+	l := NewList(1, 2, 3, 4, 5, 6)
+	for e := range l.Elements() {
+		if e.Value%2 == 0 {
+			l.Remove(e)
+		}
+	}
+	if got := slices.Collect(l.All()); !slices.Equal(got, []int{1, 3, 5}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestListBreak(t *testing.T) {
+	l := NewList(1, 2, 3)
+	var got []int
+	for v := range l.All() {
+		got = append(got, v)
+		if v == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func FuzzList(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		// Replay the ops on a List and on a slice and compare.
+		var l List[byte]
+		var want []byte
+		for i, op := range ops {
+			switch op % 4 {
+			case 0:
+				l.PushBack(byte(i))
+				want = append(want, byte(i))
+			case 1:
+				l.PushFront(byte(i))
+				want = slices.Insert(want, 0, byte(i))
+			case 2:
+				if e := l.Front(); e != nil {
+					l.Remove(e)
+					want = want[1:]
+				}
+			case 3:
+				if e := l.Back(); e != nil {
+					l.InsertBefore(byte(i), e)
+					want = slices.Insert(want, len(want)-1, byte(i))
+				}
+			}
+		}
+		if got := slices.Collect(l.All()); !slices.Equal(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		back := slices.Collect(l.Backward())
+		slices.Reverse(back)
+		if !slices.Equal(back, want) {
+			t.Fatalf("backward got %v, want %v", back, want)
+		}
+	})
+}
+
+func BenchmarkListPushBack(b *testing.B) {
+	b.ReportAllocs()
+	var l List[int]
+	for i := 0; i < b.N; i++ {
+		l.PushBack(i)
+	}
+}
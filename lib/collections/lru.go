@@ -0,0 +1,111 @@
+package collections
+
+import "iter"
+
+// LRU is a cache holding at most a fixed number of entries. Putting an entry
+// into a full cache evicts the least recently used one, where Get and Put
+// count as uses and Peek does not.
+type LRU[K comparable, V any] struct {
+	capacity int
+	order    List[lruEntry[K, V]] // most recently used at the front
+	index    map[K]*Element[lruEntry[K, V]]
+
+	// OnEvict, if set, is called with every entry Put pushes out. It isn't
+	// called for entries taken out with Remove.
+	OnEvict func(key K, value V)
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewLRU returns an empty cache for capacity entries. It panics if capacity
+// is less than 1.
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	if capacity < 1 {
+		panic("collections: LRU capacity must be at least 1")
+	}
+	return &LRU[K, V]{capacity: capacity, index: make(map[K]*Element[lruEntry[K, V]])}
+}
+
+// Len returns the number of cached entries.
+func (c *LRU[K, V]) Len() int { return c.order.Len() }
+
+// Cap returns the most entries the cache holds.
+func (c *LRU[K, V]) Cap() int { return c.capacity }
+
+// Get returns the value cached for key and marks it as recently used.
+func (c *LRU[K, V]) Get(key K) (v V, ok bool) {
+	e, ok := c.index[key]
+	if !ok {
+		return v, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.value, true
+}
+
+// Peek returns the value cached for key without marking it as used.
+func (c *LRU[K, V]) Peek(key K) (v V, ok bool) {
+	e, ok := c.index[key]
+	if !ok {
+		return v, false
+	}
+	return e.Value.value, true
+}
+
+// Put caches value under key, replacing any value already there, and marks
+// it as recently used. It reports whether another entry was evicted to make
+// room.
+func (c *LRU[K, V]) Put(key K, value V) (evicted bool) {
+	if e, ok := c.index[key]; ok {
+		e.Value.value = value
+		c.order.MoveToFront(e)
+		return false
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.index, oldest.Value.key)
+		if c.OnEvict != nil {
+			c.OnEvict(oldest.Value.key, oldest.Value.value)
+		}
+		evicted = true
+	}
+	c.index[key] = c.order.PushFront(lruEntry[K, V]{key, value})
+	return evicted
+}
+
+// Remove takes key out of the cache and reports whether it was there.
+func (c *LRU[K, V]) Remove(key K) bool {
+	e, ok := c.index[key]
+	if !ok {
+		return false
+	}
+	c.order.Remove(e)
+	delete(c.index, key)
+	return true
+}
+
+// All returns an iterator over the entries from most to least recently used.
+// Iterating doesn't count as use.
+func (c *LRU[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for ent := range c.order.All() {
+			if !yield(ent.key, ent.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the keys from most to least recently used.
+func (c *LRU[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range c.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package collections
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[string, int](2)
+	var evicted []string
+	c.OnEvict = func(k string, v int) { evicted = append(evicted, fmt.Sprintf("%s=%d", k, v)) }
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get(a) = %d, %v", v, ok)
+	}
+	// b is now the least recently used.
+	if !c.Put("c", 3) {
+		t.Error("Put into a full cache didn't report an eviction")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("b wasn't evicted")
+	}
+	if !slices.Equal(evicted, []string{"b=2"}) {
+		t.Errorf("evicted %q", evicted)
+	}
+
+	// Peek doesn't count as a use, so a is still the oldest.
+	if v, ok := c.Peek("a"); !ok || v != 1 {
+		t.Errorf("Peek(a) = %d, %v", v, ok)
+	}
+	if got := slices.Collect(c.Keys()); !slices.Equal(got, []string{"c", "a"}) {
+		t.Errorf("Keys() = %q", got)
+	}
+
+	// Replacing a value doesn't evict.
+	if c.Put("a", 10) {
+		t.Error("replacing a value evicted something")
+	}
+	var all []string
+	for k, v := range c.All() {
+		all = append(all, fmt.Sprintf("%s=%d", k, v))
+	}
+	if !slices.Equal(all, []string{"a=10", "c=3"}) {
+		t.Errorf("All() = %q", all)
+	}
+
+	if !c.Remove("a") || c.Remove("a") || c.Len() != 1 || c.Cap() != 2 {
+		t.Errorf("after Remove, Len() = %d", c.Len())
+	}
+	if len(evicted) != 1 {
+		t.Errorf("Remove called OnEvict: %q", evicted)
+	}
+}
+
+func TestNewLRUPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewLRU(0) did not panic")
+		}
+	}()
+	NewLRU[int, int](0)
+}
+
+func FuzzLRU(f *testing.F) {
+	f.Add(3, []byte{1, 2, 3, 1, 4, 5})
+	f.Fuzz(func(t *testing.T, capacity int, keys []byte) {
+		capacity = capacity%8 + 8 // 1 to 15
+		c := NewLRU[byte, int](capacity)
+		// Model: keys in recency order, most recent first.
+		var model []byte
+		for i, k := range keys {
+			if i%3 == 2 {
+				_, ok := c.Get(k)
+				if j := slices.Index(model, k); j >= 0 {
+					if !ok {
+						t.Fatalf("Get(%d) missed", k)
+					}
+					model = slices.Insert(slices.Delete(model, j, j+1), 0, k)
+				} else if ok {
+					t.Fatalf("Get(%d) hit", k)
+				}
+				continue
+			}
+			c.Put(k, i)
+			if j := slices.Index(model, k); j >= 0 {
+				model = slices.Delete(model, j, j+1)
+			}
+			model = slices.Insert(model, 0, k)
+			if len(model) > capacity {
+				model = model[:capacity]
+			}
+		}
+		if got := slices.Collect(c.Keys()); !slices.Equal(got, model) {
+			t.Fatalf("Keys() = %v, want %v", got, model)
+		}
+	})
+}
+
+func BenchmarkLRU(b *testing.B) {
+	c := NewLRU[int, int](1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Put(i%2000, i)
+		c.Get((i * 7) % 2000)
+	}
+}
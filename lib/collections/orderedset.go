@@ -0,0 +1,62 @@
+package collections
+
+import "iter"
+
+// OrderedSet is a set that remembers insertion order: iterating visits values
+// in the order they were first added. Add, Remove and Contains are O(1).
+// The zero value is an empty set ready to use.
+type OrderedSet[T comparable] struct {
+	order List[T]
+	index map[T]*Element[T]
+}
+
+// NewOrderedSet returns a set holding vs, in that order, without repeats.
+func NewOrderedSet[T comparable](vs ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{}
+	for _, v := range vs {
+		s.Add(v)
+	}
+	return s
+}
+
+// Add appends v if it isn't already in the set and reports whether it was
+// new. Adding a value again doesn't move it.
+func (s *OrderedSet[T]) Add(v T) bool {
+	if _, ok := s.index[v]; ok {
+		return false
+	}
+	if s.index == nil {
+		s.index = make(map[T]*Element[T])
+	}
+	s.index[v] = s.order.PushBack(v)
+	return true
+}
+
+// Remove takes v out of the set and reports whether it was there.
+func (s *OrderedSet[T]) Remove(v T) bool {
+	e, ok := s.index[v]
+	if !ok {
+		return false
+	}
+	s.order.Remove(e)
+	delete(s.index, v)
+	return true
+}
+
+// Contains reports whether v is in the set.
+func (s *OrderedSet[T]) Contains(v T) bool {
+	_, ok := s.index[v]
+	return ok
+}
+
+// Len returns the number of values in the set.
+func (s *OrderedSet[T]) Len() int { return s.order.Len() }
+
+// All returns an iterator over the values in insertion order.
+func (s *OrderedSet[T]) All() iter.Seq[T] { return s.order.All() }
+
+// Backward returns an iterator over the values, most recently added first.
+func (s *OrderedSet[T]) Backward() iter.Seq[T] { return s.order.Backward() }
+
+// Set returns the values as an unordered Set.
+func (s *OrderedSet[T]) Set() Set[T] { return Collect(s.All()) }
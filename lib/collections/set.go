@@ -0,0 +1,134 @@
+package collections
+
+import (
+	"iter"
+	"maps"
+)
+
+// Set is an unordered set of comparable values. It is a map underneath, so
+// make one with NewSet or make(Set[T]); a nil Set reads as empty but panics
+// on Add.
+type Set[T comparable] map[T]struct{}
+
+// NewSet returns a set holding vs.
+func NewSet[T comparable](vs ...T) Set[T] {
+	s := make(Set[T], len(vs))
+	for _, v := range vs {
+		s[v] = struct{}{}
+	}
+	return s
+}
+
+// Collect returns a set of the values seq yields.
+func Collect[T comparable](seq iter.Seq[T]) Set[T] {
+	s := make(Set[T])
+	for v := range seq {
+		s[v] = struct{}{}
+	}
+	return s
+}
+
+// Add puts v in the set and reports whether it was new.
+func (s Set[T]) Add(v T) bool {
+	if _, ok := s[v]; ok {
+		return false
+	}
+	s[v] = struct{}{}
+	return true
+}
+
+// Remove takes v out of the set and reports whether it was there.
+func (s Set[T]) Remove(v T) bool {
+	if _, ok := s[v]; !ok {
+		return false
+	}
+	delete(s, v)
+	return true
+}
+
+// Contains reports whether v is in the set.
+func (s Set[T]) Contains(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+// Len returns the number of values in the set.
+func (s Set[T]) Len() int { return len(s) }
+
+// Clone returns a copy of the set.
+func (s Set[T]) Clone() Set[T] {
+	if s == nil {
+		return make(Set[T])
+	}
+	return maps.Clone(s)
+}
+
+// Union returns a new set of the values in s or t.
+func (s Set[T]) Union(t Set[T]) Set[T] {
+	u := s.Clone()
+	for v := range t {
+		u[v] = struct{}{}
+	}
+	return u
+}
+
+// Intersect returns a new set of the values in both s and t.
+func (s Set[T]) Intersect(t Set[T]) Set[T] {
+	small, big := s, t
+	if len(small) > len(big) {
+		small, big = big, small
+	}
+	u := make(Set[T])
+	for v := range small {
+		if big.Contains(v) {
+			u[v] = struct{}{}
+		}
+	}
+	return u
+}
+
+// Difference returns a new set of the values in s but not in t.
+func (s Set[T]) Difference(t Set[T]) Set[T] {
+	u := make(Set[T])
+	for v := range s {
+		if !t.Contains(v) {
+			u[v] = struct{}{}
+		}
+	}
+	return u
+}
+
+// SymmetricDifference returns a new set of the values in exactly one of s
+// and t.
+func (s Set[T]) SymmetricDifference(t Set[T]) Set[T] {
+	u := s.Difference(t)
+	for v := range t {
+		if !s.Contains(v) {
+			u[v] = struct{}{}
+		}
+	}
+	return u
+}
+
+// SubsetOf reports whether every value in s is also in t.
+func (s Set[T]) SubsetOf(t Set[T]) bool {
+	if len(s) > len(t) {
+		return false
+	}
+	for v := range s {
+		if !t.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether s and t hold the same values.
+func (s Set[T]) Equal(t Set[T]) bool {
+	return len(s) == len(t) && s.SubsetOf(t)
+}
+
+// All returns an iterator over the values, in no particular order.
+func (s Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s)
+}
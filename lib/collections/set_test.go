@@ -0,0 +1,119 @@
+package collections
+
+import (
+	"slices"
+	"testing"
+)
+
+func sorted(s Set[int]) []int {
+	return slices.Sorted(s.All())
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+
+	var tests = []struct {
+		name string
+		got  Set[int]
+		want []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersect", a.Intersect(b), []int{3, 4}},
+		{"difference", a.Difference(b), []int{1, 2}},
+		{"difference reversed", b.Difference(a), []int{5}},
+		{"symmetric difference", a.SymmetricDifference(b), []int{1, 2, 5}},
+		{"union with nil", a.Union(nil), []int{1, 2, 3, 4}},
+		{"intersect with nil", a.Intersect(nil), nil},
+		{"nil union", Set[int](nil).Union(b), []int{3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sorted(tt.got); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// None of the operations change their operands.
+	if got := sorted(a); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("a changed to %v", got)
+	}
+}
+
+func TestSetBasics(t *testing.T) {
+	s := NewSet[string]()
+	if !s.Add("x") || s.Add("x") {
+		t.Error("Add didn't report newness")
+	}
+	if !s.Contains("x") || s.Contains("y") || s.Len() != 1 {
+		t.Error("Contains or Len is wrong")
+	}
+	if !s.Remove("x") || s.Remove("x") {
+		t.Error("Remove didn't report presence")
+	}
+
+	a, b := NewSet(1, 2), NewSet(1, 2, 3)
+	if !a.SubsetOf(b) || b.SubsetOf(a) {
+		t.Error("SubsetOf is wrong")
+	}
+	if a.Equal(b) || !a.Equal(NewSet(2, 1)) || !Set[int](nil).Equal(NewSet[int]()) {
+		t.Error("Equal is wrong")
+	}
+	if c := Collect(slices.Values([]int{3, 1, 3})); !c.Equal(NewSet(1, 3)) {
+		t.Errorf("Collect gave %v", sorted(c))
+	}
+}
+
+func TestOrderedSet(t *testing.T) {
+	var s OrderedSet[string]
+	for _, v := range []string{"c", "a", "b", "a"} {
+		s.Add(v)
+	}
+	if got := slices.Collect(s.All()); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("All() = %q", got)
+	}
+	if !s.Remove("a") || s.Remove("a") || s.Contains("a") {
+		t.Error("Remove is wrong")
+	}
+	s.Add("a")
+	if got := slices.Collect(s.Backward()); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Backward() = %q", got)
+	}
+	if s.Len() != 3 || !s.Set().Equal(NewSet("a", "b", "c")) {
+		t.Errorf("Len() = %d, Set() = %v", s.Len(), s.Set())
+	}
+}
+
+func FuzzSet(f *testing.F) {
+	f.Add([]byte{1, 2, 3}, []byte{2, 3, 4})
+	f.Fuzz(func(t *testing.T, x, y []byte) {
+		a, b := NewSet(x...), NewSet(y...)
+		union, inter := a.Union(b), a.Intersect(b)
+		// |A ∪ B| = |A| + |B| - |A ∩ B|
+		if union.Len() != a.Len()+b.Len()-inter.Len() {
+			t.Errorf("|union| = %d, |a| = %d, |b| = %d, |intersection| = %d", union.Len(), a.Len(), b.Len(), inter.Len())
+		}
+		// A = (A \ B) ∪ (A ∩ B)
+		if !a.Difference(b).Union(inter).Equal(a) {
+			t.Error("difference and intersection don't make up a")
+		}
+		if !a.SymmetricDifference(b).Equal(union.Difference(inter)) {
+			t.Error("symmetric difference isn't union minus intersection")
+		}
+	})
+}
+
+func BenchmarkSetIntersect(b *testing.B) {
+	x, y := NewSet[int](), NewSet[int]()
+	for i := 0; i < 1000; i++ {
+		x.Add(i)
+		y.Add(i * 2)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Intersect(y)
+	}
+}
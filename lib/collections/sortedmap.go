@@ -0,0 +1,324 @@
+package collections
+
+import (
+	"cmp"
+	"iter"
+	"math/rand/v2"
+)
+
+// SortedMap is a map that keeps its keys in order, so iterating is sorted
+// without the slices.Sort that MapKeys output needs, and it answers nearest
+// key (Floor, Ceiling) and position (Rank, Select) questions.
+//
+// It is an indexable skip list: every link records how many entries it
+// skips, which makes Rank and Select O(log n) like Get, Set and Delete.
+type SortedMap[K, V any] struct {
+	cmp   func(a, b K) int
+	head  skipNode[K, V] // sentinel before the first entry
+	tail  *skipNode[K, V]
+	level int // levels in use, at least 1
+	len   int
+}
+
+const (
+	skipMaxLevel = 32
+	skipP        = 4 // one node in skipP is promoted to the next level
+)
+
+type skipNode[K, V any] struct {
+	key   K
+	value V
+	next  []skipLink[K, V]
+	prev  *skipNode[K, V] // nil for the first entry
+}
+
+type skipLink[K, V any] struct {
+	node *skipNode[K, V]
+	// span is the number of level-0 steps the link covers. A link to nil
+	// covers the steps to just past the last entry.
+	span int
+}
+
+// NewSortedMap returns an empty map ordered by cmp.Compare on the keys.
+func NewSortedMap[K cmp.Ordered, V any]() *SortedMap[K, V] {
+	return NewSortedMapFunc[K, V](cmp.Compare[K])
+}
+
+// NewSortedMapFunc returns an empty map ordered by compare, which returns a
+// negative number, zero or a positive number as a sorts before, with or
+// after b. Keys that compare equal are the same key.
+func NewSortedMapFunc[K, V any](compare func(a, b K) int) *SortedMap[K, V] {
+	m := &SortedMap[K, V]{cmp: compare, level: 1}
+	m.head.next = make([]skipLink[K, V], skipMaxLevel)
+	return m
+}
+
+// FromMap returns a SortedMap holding the entries of m.
+func FromMap[K cmp.Ordered, V any](m map[K]V) *SortedMap[K, V] {
+	sm := NewSortedMap[K, V]()
+	for k, v := range m {
+		sm.Set(k, v)
+	}
+	return sm
+}
+
+// Len returns the number of entries.
+func (m *SortedMap[K, V]) Len() int { return m.len }
+
+func randomLevel() int {
+	l := 1
+	for l < skipMaxLevel && rand.IntN(skipP) == 0 {
+		l++
+	}
+	return l
+}
+
+// search walks down to the last node at each level whose key is before key,
+// or not after it when inclusive is set. It returns the node reached at
+// level 0 and that node's position, counting from 1 for the first entry.
+func (m *SortedMap[K, V]) search(key K, inclusive bool, update []*skipNode[K, V], rank []int) (*skipNode[K, V], int) {
+	x, pos := &m.head, 0
+	for i := m.level - 1; i >= 0; i-- {
+		for {
+			n := x.next[i].node
+			if n == nil {
+				break
+			}
+			c := m.cmp(n.key, key)
+			if c > 0 || c == 0 && !inclusive {
+				break
+			}
+			pos += x.next[i].span
+			x = n
+		}
+		if update != nil {
+			update[i] = x
+		}
+		if rank != nil {
+			rank[i] = pos
+		}
+	}
+	return x, pos
+}
+
+// Get returns the value stored under key.
+func (m *SortedMap[K, V]) Get(key K) (v V, ok bool) {
+	x, _ := m.search(key, false, nil, nil)
+	if n := x.next[0].node; n != nil && m.cmp(n.key, key) == 0 {
+		return n.value, true
+	}
+	return v, false
+}
+
+// Set stores value under key and reports whether the key was new.
+func (m *SortedMap[K, V]) Set(key K, value V) bool {
+	var update [skipMaxLevel]*skipNode[K, V]
+	var rank [skipMaxLevel]int
+	x, _ := m.search(key, false, update[:], rank[:])
+	if n := x.next[0].node; n != nil && m.cmp(n.key, key) == 0 {
+		n.value = value
+		return false
+	}
+
+	lvl := randomLevel()
+	if lvl > m.level {
+		for i := m.level; i < lvl; i++ {
+			update[i], rank[i] = &m.head, 0
+			m.head.next[i] = skipLink[K, V]{span: m.len}
+		}
+		m.level = lvl
+	}
+	n := &skipNode[K, V]{key: key, value: value, next: make([]skipLink[K, V], lvl)}
+	for i := 0; i < lvl; i++ {
+		prev := update[i]
+		before := rank[0] - rank[i] // steps from prev to the new node's predecessor
+		n.next[i] = skipLink[K, V]{node: prev.next[i].node, span: prev.next[i].span - before}
+		prev.next[i] = skipLink[K, V]{node: n, span: before + 1}
+	}
+	for i := lvl; i < m.level; i++ {
+		update[i].next[i].span++
+	}
+
+	if update[0] != &m.head {
+		n.prev = update[0]
+	}
+	if next := n.next[0].node; next != nil {
+		next.prev = n
+	} else {
+		m.tail = n
+	}
+	m.len++
+	return true
+}
+
+// Delete removes key and reports whether it was there.
+func (m *SortedMap[K, V]) Delete(key K) bool {
+	var update [skipMaxLevel]*skipNode[K, V]
+	x, _ := m.search(key, false, update[:], nil)
+	n := x.next[0].node
+	if n == nil || m.cmp(n.key, key) != 0 {
+		return false
+	}
+	for i := 0; i < m.level; i++ {
+		link := &update[i].next[i]
+		if link.node == n {
+			link.span += n.next[i].span - 1
+			link.node = n.next[i].node
+		} else {
+			link.span--
+		}
+	}
+	if next := n.next[0].node; next != nil {
+		next.prev = n.prev
+	} else {
+		m.tail = n.prev
+	}
+	for m.level > 1 && m.head.next[m.level-1].node == nil {
+		m.level--
+	}
+	m.len--
+	return true
+}
+
+// Min returns the entry with the smallest key.
+func (m *SortedMap[K, V]) Min() (k K, v V, ok bool) {
+	return entry(m.head.next[0].node)
+}
+
+// Max returns the entry with the largest key.
+func (m *SortedMap[K, V]) Max() (k K, v V, ok bool) {
+	return entry(m.tail)
+}
+
+// Floor returns the entry with the largest key not after key.
+func (m *SortedMap[K, V]) Floor(key K) (k K, v V, ok bool) {
+	x, _ := m.search(key, true, nil, nil)
+	if x == &m.head {
+		return k, v, false
+	}
+	return entry(x)
+}
+
+// Ceiling returns the entry with the smallest key not before key.
+func (m *SortedMap[K, V]) Ceiling(key K) (k K, v V, ok bool) {
+	x, _ := m.search(key, false, nil, nil)
+	return entry(x.next[0].node)
+}
+
+// Lower returns the entry with the largest key strictly before key.
+func (m *SortedMap[K, V]) Lower(key K) (k K, v V, ok bool) {
+	x, _ := m.search(key, false, nil, nil)
+	if x == &m.head {
+		return k, v, false
+	}
+	return entry(x)
+}
+
+// Higher returns the entry with the smallest key strictly after key.
+func (m *SortedMap[K, V]) Higher(key K) (k K, v V, ok bool) {
+	x, _ := m.search(key, true, nil, nil)
+	return entry(x.next[0].node)
+}
+
+func entry[K, V any](n *skipNode[K, V]) (k K, v V, ok bool) {
+	if n == nil {
+		return k, v, false
+	}
+	return n.key, n.value, true
+}
+
+// Rank returns the number of keys before key, which is the position key has,
+// or would have, counting from 0.
+func (m *SortedMap[K, V]) Rank(key K) int {
+	_, pos := m.search(key, false, nil, nil)
+	return pos
+}
+
+// Select returns the entry at position i, counting from 0, so Select(0) is
+// Min and Select(Len()-1) is Max.
+func (m *SortedMap[K, V]) Select(i int) (k K, v V, ok bool) {
+	if i < 0 || i >= m.len {
+		return k, v, false
+	}
+	x, pos := &m.head, 0
+	for l := m.level - 1; l >= 0; l-- {
+		for x.next[l].node != nil && pos+x.next[l].span <= i+1 {
+			pos += x.next[l].span
+			x = x.next[l].node
+		}
+		if pos == i+1 {
+			break
+		}
+	}
+	return entry(x)
+}
+
+// All returns an iterator over the entries in key order.
+func (m *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return m.ascend(func() *skipNode[K, V] { return m.head.next[0].node }, nil)
+}
+
+// Backward returns an iterator over the entries in reverse key order.
+func (m *SortedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := m.tail; n != nil; n = n.prev {
+			if !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the entries with keys from lo up to but not
+// including hi, in key order.
+func (m *SortedMap[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return m.ascend(m.ceilingNode(lo), func(k K) bool { return m.cmp(k, hi) < 0 })
+}
+
+// Ascend returns an iterator over the entries with keys from lo onwards, in
+// key order.
+func (m *SortedMap[K, V]) Ascend(lo K) iter.Seq2[K, V] {
+	return m.ascend(m.ceilingNode(lo), nil)
+}
+
+func (m *SortedMap[K, V]) ceilingNode(key K) func() *skipNode[K, V] {
+	return func() *skipNode[K, V] {
+		x, _ := m.search(key, false, nil, nil)
+		return x.next[0].node
+	}
+}
+
+// ascend iterates forwards from the node start returns while more, if not
+// nil, accepts the key. start is called when the loop begins, so the
+// iterator sees changes made after it was created.
+func (m *SortedMap[K, V]) ascend(start func() *skipNode[K, V], more func(K) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := start(); n != nil && (more == nil || more(n.key)); n = n.next[0].node {
+			if !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the keys in order.
+func (m *SortedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values in key order.
+func (m *SortedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
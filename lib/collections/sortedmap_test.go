@@ -0,0 +1,270 @@
+package collections
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func keysOf[K, V any](seq func(func(K, V) bool)) []K {
+	var out []K
+	for k := range seq {
+		out = append(out, k)
+	}
+	return out
+}
+
+func TestSortedMap(t *testing.T) {
+	m := NewSortedMap[int, string]()
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		if !m.Set(k, fmt.Sprint(k)) {
+			t.Errorf("Set(%d) didn't report a new key", k)
+		}
+	}
+	if m.Set(30, "thirty") {
+		t.Error("Set(30) again reported a new key")
+	}
+	if v, ok := m.Get(30); !ok || v != "thirty" {
+		t.Errorf("Get(30) = %q, %v", v, ok)
+	}
+	if _, ok := m.Get(35); ok {
+		t.Error("Get(35) found something")
+	}
+	if got := keysOf(m.All()); !slices.Equal(got, []int{10, 20, 30, 40, 50}) {
+		t.Errorf("All() keys = %v", got)
+	}
+	if got := keysOf(m.Backward()); !slices.Equal(got, []int{50, 40, 30, 20, 10}) {
+		t.Errorf("Backward() keys = %v", got)
+	}
+	if got := slices.Collect(m.Values()); !slices.Equal(got, []string{"10", "20", "thirty", "40", "50"}) {
+		t.Errorf("Values() = %q", got)
+	}
+	if k, _, _ := m.Min(); k != 10 {
+		t.Errorf("Min() = %d", k)
+	}
+	if k, _, _ := m.Max(); k != 50 {
+		t.Errorf("Max() = %d", k)
+	}
+}
+
+func TestSortedMapNearest(t *testing.T) {
+	m := NewSortedMap[int, bool]()
+	for _, k := range []int{10, 20, 30} {
+		m.Set(k, true)
+	}
+
+	var tests = []struct {
+		name string
+		fn   func(int) (int, bool, bool)
+		key  int
+		want int
+		ok   bool
+	}{
+		{"Floor", m.Floor, 20, 20, true},
+		{"Floor", m.Floor, 25, 20, true},
+		{"Floor", m.Floor, 5, 0, false},
+		{"Floor", m.Floor, 99, 30, true},
+		{"Ceiling", m.Ceiling, 20, 20, true},
+		{"Ceiling", m.Ceiling, 25, 30, true},
+		{"Ceiling", m.Ceiling, 31, 0, false},
+		{"Ceiling", m.Ceiling, -1, 10, true},
+		{"Lower", m.Lower, 20, 10, true},
+		{"Lower", m.Lower, 10, 0, false},
+		{"Higher", m.Higher, 20, 30, true},
+		{"Higher", m.Higher, 30, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s(%d)", tt.name, tt.key), func(t *testing.T) {
+			k, _, ok := tt.fn(tt.key)
+			if k != tt.want || ok != tt.ok {
+				t.Errorf("got %d, %v; want %d, %v", k, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSortedMapRankSelect(t *testing.T) {
+	m := NewSortedMap[string, int]()
+	for i, k := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		m.Set(k, i)
+	}
+	for i, want := range []string{"alpha", "bravo", "charlie", "delta", "echo"} {
+		if k, _, ok := m.Select(i); !ok || k != want {
+			t.Errorf("Select(%d) = %q, %v; want %q", i, k, ok, want)
+		}
+		if r := m.Rank(want); r != i {
+			t.Errorf("Rank(%q) = %d, want %d", want, r, i)
+		}
+	}
+	if r := m.Rank("cat"); r != 2 {
+		t.Errorf("Rank(cat) = %d, want 2", r)
+	}
+	if r := m.Rank("zulu"); r != 5 {
+		t.Errorf("Rank(zulu) = %d, want 5", r)
+	}
+	if _, _, ok := m.Select(5); ok {
+		t.Error("Select(5) found something")
+	}
+	if _, _, ok := m.Select(-1); ok {
+		t.Error("Select(-1) found something")
+	}
+}
+
+func TestSortedMapRange(t *testing.T) {
+	m := NewSortedMap[int, int]()
+	for k := 0; k < 100; k += 10 {
+		m.Set(k, k)
+	}
+	if got := keysOf(m.Range(15, 50)); !slices.Equal(got, []int{20, 30, 40}) {
+		t.Errorf("Range(15, 50) = %v", got)
+	}
+	if got := keysOf(m.Range(20, 21)); !slices.Equal(got, []int{20}) {
+		t.Errorf("Range(20, 21) = %v", got)
+	}
+	if got := keysOf(m.Range(50, 50)); len(got) != 0 {
+		t.Errorf("Range(50, 50) = %v", got)
+	}
+	if got := keysOf(m.Ascend(75)); !slices.Equal(got, []int{80, 90}) {
+		t.Errorf("Ascend(75) = %v", got)
+	}
+
+	// The iterator looks for its start when the loop begins.
+	r := m.Range(0, 30)
+	m.Delete(0)
+	m.Set(5, 5)
+	if got := keysOf(r); !slices.Equal(got, []int{5, 10, 20}) {
+		t.Errorf("Range(0, 30) after changes = %v", got)
+	}
+}
+
+func TestSortedMapCustomOrder(t *testing.T) {
+	// Case-insensitive keys, longest first.
+	m := NewSortedMapFunc[string, int](func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	m.Set("go", 1)
+	m.Set("rust", 2)
+	m.Set("GO", 3)
+	m.Set("c", 4)
+	if m.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", m.Len())
+	}
+	if got := keysOf(m.All()); !slices.Equal(got, []string{"rust", "go", "c"}) {
+		t.Errorf("All() keys = %q", got)
+	}
+	if v, _ := m.Get("Go"); v != 3 {
+		t.Errorf("Get(Go) = %d", v)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	src := map[string]int{"b": 2, "c": 3, "a": 1}
+	m := FromMap(src)
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, slices.Sorted(maps.Keys(src))) {
+		t.Errorf("Keys() = %q", got)
+	}
+}
+
+// FuzzSortedMap replays sets and deletes on a SortedMap and on a plain map
+// and checks every query against the plain map's sorted keys.
+func FuzzSortedMap(f *testing.F) {
+	f.Add([]byte{5, 3, 8, 3 | 0x80, 1, 9})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		m := NewSortedMap[int, int]()
+		model := make(map[int]int)
+		for i, op := range ops {
+			k := int(op & 0x3f)
+			if op&0x80 != 0 {
+				_, had := model[k]
+				if m.Delete(k) != had {
+					t.Fatalf("Delete(%d) disagrees with the model", k)
+				}
+				delete(model, k)
+			} else {
+				_, had := model[k]
+				if m.Set(k, i) == had {
+					t.Fatalf("Set(%d) disagrees with the model", k)
+				}
+				model[k] = i
+			}
+		}
+
+		keys := slices.Sorted(maps.Keys(model))
+		if m.Len() != len(keys) {
+			t.Fatalf("Len() = %d, want %d", m.Len(), len(keys))
+		}
+		if got := keysOf(m.All()); !slices.Equal(got, keys) {
+			t.Fatalf("All() = %v, want %v", got, keys)
+		}
+		back := keysOf(m.Backward())
+		slices.Reverse(back)
+		if !slices.Equal(back, keys) {
+			t.Fatalf("Backward() reversed = %v, want %v", back, keys)
+		}
+		for i, k := range keys {
+			if got, v, ok := m.Select(i); !ok || got != k || v != model[k] {
+				t.Fatalf("Select(%d) = %d, %d, %v", i, got, v, ok)
+			}
+		}
+		for q := -1; q <= 0x40; q++ {
+			r, _ := slices.BinarySearch(keys, q)
+			if got := m.Rank(q); got != r {
+				t.Fatalf("Rank(%d) = %d, want %d", q, got, r)
+			}
+			if c, _, ok := m.Ceiling(q); ok != (r < len(keys)) || ok && c != keys[r] {
+				t.Fatalf("Ceiling(%d) = %d, %v", q, c, ok)
+			}
+		}
+	})
+}
+
+func BenchmarkSortedMapSet(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	m := NewSortedMap[int, int]()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.Set(r.Intn(1<<20), i)
+	}
+}
+
+func BenchmarkSortedMapGet(b *testing.B) {
+	m := NewSortedMap[int, int]()
+	for i := 0; i < 100000; i++ {
+		m.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(i % 100000)
+	}
+}
+
+// BenchmarkSortedKeys is the alternative the package replaces: sorting the
+// keys of a map before every ordered read.
+func BenchmarkSortedKeys(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		src := make(map[int]int, n)
+		for i := 0; i < n; i++ {
+			src[i*7%n] = i
+		}
+		sm := FromMap(src)
+		b.Run(fmt.Sprintf("MapKeys+Sort/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for range slices.Sorted(maps.Keys(src)) {
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("SortedMap/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for range sm.Keys() {
+				}
+			}
+		})
+	}
+}
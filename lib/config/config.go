@@ -0,0 +1,523 @@
+// Package config fills a struct from defaults, a config file, environment
+// variables and command-line flags.
+//
+// CmdFlags.go defines flags one flag.String at a time and EnvVars.go reads
+// os.Getenv separately, so a setting that can come from either is declared
+// twice and the two never agree on which wins. Here a setting is declared
+// once, as a tagged struct field:
+//
+//	type Config struct {
+//		Port    int           `config:"port" default:"8080" min:"1" max:"65535" usage:"HTTP port"`
+//		Timeout time.Duration `default:"30s"`
+//		DB      struct {
+//			URL      string `config:"url,required,secret"`
+//			MaxConns int    `config:"max_conns" default:"10" min:"1"`
+//		} `config:"db"`
+//	}
+//
+// and Loader.Load fills it from, in increasing order of precedence:
+//
+//  1. the default tag
+//  2. the config file: JSON, or TOML-like "key = value" lines under
+//     "[section]" headers ("db.max_conns", or max_conns under [db])
+//  3. environment variables: the prefix, then the key in upper case with dots
+//     as underscores (APP_DB_MAX_CONNS), or the name in an env tag
+//  4. flags: the key with underscores as dashes (-db.max-conns), or the name
+//     in a flag tag
+//
+// A later source replaces an earlier one's value. Load then checks required
+// fields and min/max ranges, and returns a Report saying where each value
+// came from, which can print the effective config with secret values
+// redacted.
+//
+// The config tag is a key and options: "required" (the value must not be
+// zero) and "secret" (never printed). A field without a tag is keyed by its
+// name in snake case (MaxConns is max_conns). An env or flag tag of "-"
+// turns that source off for the field, and a config tag of "-" skips the
+// field entirely. Fields can be strings, bools, numbers, time.Durations,
+// slices of those (comma-separated outside files), or any
+// encoding.TextUnmarshaler.
+package config
+
+import (
+	"cmp"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Source is where a value came from.
+type Source int
+
+const (
+	Unset Source = iota // no default and not set anywhere
+	Default
+	File
+	Env
+	Flag
+)
+
+func (s Source) String() string {
+	switch s {
+	case Unset:
+		return "unset"
+	case Default:
+		return "default"
+	case File:
+		return "file"
+	case Env:
+		return "env"
+	case Flag:
+		return "flag"
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// Loader says where to look for values.
+type Loader struct {
+	// File is the config file to read. Empty means none. A file ending in
+	// .json is JSON; anything else is read as TOML-like key = value lines.
+	File string
+	// FileFlag, if set, is the name of a flag that overrides File.
+	FileFlag string
+	// EnvPrefix is put, with an underscore, before derived environment
+	// variable names. Empty means no prefix.
+	EnvPrefix string
+	// LookupEnv reads the environment; nil means os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+	// Flags, if set, gets a flag for every field and is parsed from Args.
+	// Its usage message lists them with their defaults and variables.
+	// The first Load defines and parses the flags; later ones, reloading,
+	// reuse the values they were given.
+	Flags *flag.FlagSet
+	Args  []string
+
+	flagValues map[string]*rawFlag // by key, once the flags are defined
+	flagFile   *string             // FileFlag's value
+	flagErr    error
+}
+
+// Field describes one setting and where its value came from.
+type Field struct {
+	Key      string // dotted path, e.g. "db.max_conns"
+	Env      string // environment variable, or "" if none
+	Flag     string // flag name, or "" if none
+	Usage    string
+	Default  string
+	Min, Max string
+	Required bool
+	Secret   bool
+
+	Source Source
+	// From says exactly where the value came from: "default", "file
+	// app.toml:12", "env APP_PORT" or "flag -port".
+	From string
+
+	value reflect.Value
+}
+
+// Value returns the field's current value.
+func (f *Field) Value() any { return f.value.Interface() }
+
+// Load fills the struct dst points to. It returns a Report of every field
+// and where its value came from, and an error, joining all of them, for
+// bad values, unknown keys in the file, and failed validation.
+func (l *Loader) Load(dst any) (*Report, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: Load needs a pointer to a struct, not %T", dst)
+	}
+	var fields []*Field
+	if err := collect(v.Elem(), "", l.EnvPrefix, &fields); err != nil {
+		return nil, err
+	}
+	r := &Report{Fields: fields}
+
+	// Flags are parsed first, since one of them may name the file, but
+	// applied last.
+	file := l.File
+	if l.Flags != nil {
+		if l.flagValues == nil {
+			l.defineFlags(fields)
+			l.flagErr = l.Flags.Parse(l.Args)
+		}
+		if l.flagErr != nil {
+			return r, l.flagErr
+		}
+		if l.flagFile != nil {
+			file = *l.flagFile
+		}
+	}
+	r.File = file
+
+	var errs []error
+	set := func(f *Field, raw any, src Source, from string) {
+		if err := setValue(f.value, raw); err != nil {
+			if f.Secret {
+				// Parse errors quote the input, which is the secret.
+				err = errors.New("invalid value")
+			}
+			errs = append(errs, fmt.Errorf("config: %s: %w (from %s)", f.Key, err, from))
+			return
+		}
+		f.Source, f.From = src, from
+	}
+
+	for _, f := range fields {
+		if f.Default != "" {
+			set(f, f.Default, Default, "default")
+		}
+	}
+
+	if file != "" {
+		entries, err := readFile(file)
+		if err != nil {
+			return r, err
+		}
+		byKey := make(map[string]*Field, len(fields))
+		for _, f := range fields {
+			byKey[f.Key] = f
+		}
+		for _, e := range entries {
+			f, ok := byKey[e.key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("config: %s: unknown key %q", e.pos, e.key))
+				continue
+			}
+			set(f, e.value, File, "file "+e.pos)
+		}
+	}
+
+	lookup := l.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	for _, f := range fields {
+		if f.Env == "" {
+			continue
+		}
+		if s, ok := lookup(f.Env); ok {
+			set(f, s, Env, "env "+f.Env)
+		}
+	}
+
+	for _, f := range fields {
+		if rf := l.flagValues[f.Key]; rf != nil && rf.set {
+			set(f, rf.value, Flag, "flag -"+f.Flag)
+		}
+	}
+
+	for _, f := range fields {
+		if err := validate(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return r, errors.Join(errs...)
+}
+
+func (l *Loader) defineFlags(fields []*Field) {
+	l.flagValues = make(map[string]*rawFlag)
+	for _, f := range fields {
+		if f.Flag == "" {
+			continue
+		}
+		rf := &rawFlag{def: f.Default, isBool: f.value.Kind() == reflect.Bool}
+		l.flagValues[f.Key] = rf
+		l.Flags.Var(rf, f.Flag, flagUsage(f))
+	}
+	if l.FileFlag != "" {
+		l.flagFile = new(string)
+		l.Flags.StringVar(l.flagFile, l.FileFlag, l.File, "config `file`")
+	}
+}
+
+// collect walks the struct's fields, recursing into nested structs that
+// aren't TextUnmarshalers.
+func collect(v reflect.Value, keyPrefix, envPrefix string, out *[]*Field) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key, opts, _ := strings.Cut(sf.Tag.Get("config"), ",")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = snakeCase(sf.Name)
+		}
+		if keyPrefix != "" {
+			key = keyPrefix + "." + key
+		}
+		fv := v.Field(i)
+		if sf.Type.Kind() == reflect.Struct && !isText(fv) {
+			if err := collect(fv, key, envPrefix, out); err != nil {
+				return err
+			}
+			continue
+		}
+		if !supported(fv) {
+			return fmt.Errorf("config: %s: unsupported type %s", key, sf.Type)
+		}
+
+		f := &Field{
+			Key:     key,
+			Usage:   sf.Tag.Get("usage"),
+			Default: sf.Tag.Get("default"),
+			Min:     sf.Tag.Get("min"),
+			Max:     sf.Tag.Get("max"),
+			value:   fv,
+		}
+		for _, o := range strings.Split(opts, ",") {
+			switch o {
+			case "":
+			case "required":
+				f.Required = true
+			case "secret":
+				f.Secret = true
+			default:
+				return fmt.Errorf("config: %s: unknown option %q", key, o)
+			}
+		}
+		switch env := sf.Tag.Get("env"); env {
+		case "-":
+		case "":
+			f.Env = strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+			if envPrefix != "" {
+				f.Env = envPrefix + "_" + f.Env
+			}
+		default:
+			f.Env = env
+		}
+		switch fl := sf.Tag.Get("flag"); fl {
+		case "-":
+		case "":
+			f.Flag = strings.ReplaceAll(key, "_", "-")
+		default:
+			f.Flag = fl
+		}
+		*out = append(*out, f)
+	}
+	return nil
+}
+
+// snakeCase turns a Go name into a key: MaxConns is max_conns, and acronyms
+// stay together, so DBURL is dburl and HTTPPort is http_port.
+func snakeCase(name string) string {
+	var b strings.Builder
+	rs := []rune(name)
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+var (
+	textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType    = reflect.TypeFor[time.Duration]()
+)
+
+func isText(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(textUnmarshaler)
+}
+
+func supported(v reflect.Value) bool {
+	if isText(v) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return supported(reflect.New(v.Type().Elem()).Elem())
+	}
+	return false
+}
+
+// setValue parses raw, a string or, from a file array, a []string, into v.
+func setValue(v reflect.Value, raw any) error {
+	if v.Kind() == reflect.Slice && !isText(v) {
+		var parts []string
+		switch raw := raw.(type) {
+		case []string:
+			parts = raw
+		case string:
+			if raw != "" {
+				parts = strings.Split(raw, ",")
+			}
+		}
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setScalar(s.Index(i), strings.TrimSpace(p)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Errorf("got a list, want a single value")
+	}
+	return setScalar(v, s)
+}
+
+func setScalar(v reflect.Value, s string) error {
+	if isText(v) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(n)
+	}
+	return nil
+}
+
+// validate checks the required option and the min and max tags, which
+// bound numbers and durations, and the length of strings and slices.
+func validate(f *Field) error {
+	v := f.value
+	if f.Required && v.IsZero() {
+		return fmt.Errorf("config: %s is required (%s)", f.Key, where(f))
+	}
+	for _, b := range []struct {
+		bound string
+		name  string
+		out   func(c int) bool
+	}{
+		{f.Min, "min", func(c int) bool { return c < 0 }},
+		{f.Max, "max", func(c int) bool { return c > 0 }},
+	} {
+		if b.bound == "" {
+			continue
+		}
+		c, err := compare(v, b.bound)
+		if err != nil {
+			return fmt.Errorf("config: %s: bad %s tag: %w", f.Key, b.name, err)
+		}
+		if b.out(c) {
+			return fmt.Errorf("config: %s: %s is out of range, %s is %s (from %s)", f.Key, display(f), b.name, b.bound, f.From)
+		}
+	}
+	return nil
+}
+
+// where names the places a field can be set.
+func where(f *Field) string {
+	var ways []string
+	ways = append(ways, "key "+f.Key)
+	if f.Env != "" {
+		ways = append(ways, "env "+f.Env)
+	}
+	if f.Flag != "" {
+		ways = append(ways, "flag -"+f.Flag)
+	}
+	return "set " + strings.Join(ways, ", ")
+}
+
+// compare compares v with bound, parsed as v's type, or for strings and
+// slices compares their length with bound.
+func compare(v reflect.Value, bound string) (int, error) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice:
+		n, err := strconv.Atoi(bound)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Len(), n), nil
+	}
+	b := reflect.New(v.Type()).Elem()
+	if err := setScalar(b, bound); err != nil {
+		return 0, err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(v.Int(), b.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(v.Uint(), b.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(v.Float(), b.Float()), nil
+	}
+	return 0, fmt.Errorf("no range for type %s", v.Type())
+}
+
+// rawFlag holds a flag's text until Load applies it.
+type rawFlag struct {
+	def, value string
+	set        bool
+	isBool     bool
+}
+
+func (f *rawFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	if f.set {
+		return f.value
+	}
+	return f.def
+}
+
+func (f *rawFlag) Set(s string) error {
+	f.value, f.set = s, true
+	return nil
+}
+
+func (f *rawFlag) IsBoolFlag() bool { return f.isBool }
+
+func flagUsage(f *Field) string {
+	u := f.Usage
+	if u == "" {
+		u = f.Key
+	}
+	if f.Env != "" {
+		u += " (env " + f.Env + ")"
+	}
+	return u
+}
@@ -0,0 +1,318 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Port     int           `config:"port" default:"8080" min:"1" max:"65535" usage:"HTTP port"`
+	Host     string        `default:"localhost"`
+	Timeout  time.Duration `default:"30s" min:"1s"`
+	Debug    bool
+	Tags     []string `default:"a,b"`
+	Ratio    float64  `default:"0.5" min:"0" max:"1"`
+	BindAddr net.IP   `config:"bind_addr" default:"127.0.0.1"`
+	DB       struct {
+		URL      string `config:"url,required,secret"`
+		MaxConns int    `default:"10" min:"1" env:"DB_POOL"`
+	} `config:"db"`
+	Internal string `config:"-"`
+	NoFlag   string `flag:"-" env:"-"`
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := vars[k]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+const tomlFile = `# test config
+port = 9000
+host = "file.example.com"
+tags = ["x", "y, z"]  # a comment
+
+[db]
+url = "postgres://user:hunter2@db/app"
+max_conns = 20
+`
+
+func TestPrecedence(t *testing.T) {
+	var c testConfig
+	l := &Loader{
+		File:      writeFile(t, "app.toml", tomlFile),
+		EnvPrefix: "APP",
+		LookupEnv: env(map[string]string{"APP_HOST": "env.example.com", "APP_PORT": "9100", "DB_POOL": "30"}),
+		Flags:     newFlags(),
+		Args:      []string{"-port", "9200", "-debug", "rest"},
+	}
+	r, err := l.Load(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		key  string
+		got  any
+		want any
+		from string
+	}{
+		{"port", c.Port, 9200, "flag -port"},
+		{"host", c.Host, "env.example.com", "env APP_HOST"},
+		{"timeout", c.Timeout, 30 * time.Second, "default"},
+		{"debug", c.Debug, true, "flag -debug"},
+		{"ratio", c.Ratio, 0.5, "default"},
+		{"db.url", c.DB.URL, "postgres://user:hunter2@db/app", "file app.toml:7"},
+		{"db.max_conns", c.DB.MaxConns, 30, "env DB_POOL"},
+		{"no_flag", c.NoFlag, "", ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if f := r.Field(tt.key); f == nil || f.From != tt.from {
+			t.Errorf("%s: from %+v, want %q", tt.key, f, tt.from)
+		}
+	}
+	if want := []string{"x", "y, z"}; !slices.Equal(c.Tags, want) {
+		t.Errorf("tags: got %q, want %q", c.Tags, want)
+	}
+	if !c.BindAddr.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("bind_addr: got %v", c.BindAddr)
+	}
+	if r.Field("internal") != nil {
+		t.Error(`a config:"-" field was loaded`)
+	}
+	if f := r.Field("no_flag"); f.Env != "" || f.Flag != "" || f.Source != Unset {
+		t.Errorf("no_flag = %+v", f)
+	}
+	if got := l.Flags.Args(); !slices.Equal(got, []string{"rest"}) {
+		t.Errorf("Args() = %q", got)
+	}
+}
+
+func TestJSONMatchesTOML(t *testing.T) {
+	const jsonFile = `{
+		"port": 9000,
+		"host": "file.example.com",
+		"tags": ["x", "y, z"],
+		"db": {"url": "postgres://user:hunter2@db/app", "max_conns": 20}
+	}`
+	var fromTOML, fromJSON testConfig
+	for _, c := range []struct {
+		dst  *testConfig
+		file string
+	}{
+		{&fromTOML, writeFile(t, "app.toml", tomlFile)},
+		{&fromJSON, writeFile(t, "app.json", jsonFile)},
+	} {
+		l := &Loader{File: c.file, LookupEnv: env(nil)}
+		if _, err := l.Load(c.dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fromTOML.Port != fromJSON.Port || fromTOML.Host != fromJSON.Host ||
+		fromTOML.DB != fromJSON.DB || !slices.Equal(fromTOML.Tags, fromJSON.Tags) {
+		t.Errorf("TOML gave %+v, JSON gave %+v", fromTOML, fromJSON)
+	}
+}
+
+func TestFileFlag(t *testing.T) {
+	var c testConfig
+	l := &Loader{
+		File:      "/does/not/exist.toml",
+		FileFlag:  "config",
+		LookupEnv: env(nil),
+		Flags:     newFlags(),
+		Args:      []string{"-config", writeFile(t, "other.toml", tomlFile)},
+	}
+	if _, err := l.Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 9000 {
+		t.Errorf("got %v, want %v", c.Port, 9000)
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := writeFile(t, "app.toml", tomlFile)
+	l := &Loader{
+		File:      "/does/not/exist.toml",
+		FileFlag:  "config",
+		LookupEnv: env(nil),
+		Flags:     newFlags(),
+		Args:      []string{"-config", path, "-host", "flag.example.com"},
+	}
+	var c testConfig
+	r, err := l.Load(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.File != path || c.Port != 9000 {
+		t.Fatalf("got file %q port %v, want %q 9000", r.File, c.Port, path)
+	}
+
+	if err := os.WriteFile(path, []byte("port = 9001\nhost = \"file\"\n[db]\nurl = \"x\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var c2 testConfig
+	r, err = l.Load(&c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2.Port != 9001 || c2.Host != "flag.example.com" || r.File != path {
+		t.Errorf("reload: got port %v host %q file %q, want 9001 flag.example.com %q", c2.Port, c2.Host, r.File, path)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var tests = []struct {
+		name  string
+		file  string
+		env   map[string]string
+		wants []string
+	}{
+		{"required", "", nil, []string{"db.url is required (set key db.url, env DB_URL, flag -db.url)"}},
+		{"range", "port = 70000\nratio = 1.5\n", map[string]string{"DB_URL": "x"}, []string{
+			"port: 70000 is out of range, max is 65535 (from file app.toml:1)",
+			"ratio: 1.5 is out of range, max is 1 (from file app.toml:2)",
+		}},
+		{"min duration", "timeout = 10ms\n", map[string]string{"DB_URL": "x"}, []string{"timeout: \"10ms\" is out of range, min is 1s"}},
+		{"bad value", "", map[string]string{"DB_URL": "x", "PORT": "eighty"}, []string{`port: invalid integer "eighty" (from env PORT)`}},
+		{"unknown key", "prot = 80\n", map[string]string{"DB_URL": "x"}, []string{`app.toml:1: unknown key "prot"`}},
+		{"list for a scalar", "port = [1, 2]\n", map[string]string{"DB_URL": "x"}, []string{"port: got a list"}},
+		{"syntax", "port 80\n", nil, []string{"app.toml:1: want key = value"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Loader{LookupEnv: env(tt.env)}
+			if tt.file != "" {
+				l.File = writeFile(t, "app.toml", tt.file)
+			}
+			var c testConfig
+			_, err := l.Load(&c)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q doesn't mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSecretParseError(t *testing.T) {
+	var c struct {
+		Pin int `config:"pin,secret"`
+	}
+	l := &Loader{LookupEnv: env(map[string]string{"PIN": "hunter2"})}
+	_, err := l.Load(&c)
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if got, want := err.Error(), "config: pin: invalid value (from env PIN)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var c testConfig
+	l := &Loader{
+		EnvPrefix: "APP",
+		LookupEnv: env(map[string]string{"APP_DB_URL": "postgres://user:hunter2@db/app", "APP_TAGS": ""}),
+	}
+	r, err := l.Load(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := r.Print(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `port         = 8080        # default
+host         = "localhost" # default
+timeout      = "30s"       # default
+debug        = false       # unset
+tags         = []          # env APP_TAGS
+ratio        = 0.5         # default
+bind_addr    = "127.0.0.1" # default
+db.url       = [redacted]  # env APP_DB_URL
+db.max_conns = 10          # default
+no_flag      = ""          # unset
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if strings.Contains(b.String(), "hunter2") {
+		t.Error("secret printed")
+	}
+}
+
+func TestFlagUsage(t *testing.T) {
+	var c testConfig
+	fs := newFlags()
+	var usage strings.Builder
+	fs.SetOutput(&usage)
+	l := &Loader{EnvPrefix: "APP", LookupEnv: env(nil), Flags: fs, Args: []string{"-h"}}
+	if _, err := l.Load(&c); err != flag.ErrHelp {
+		t.Fatalf("got %v, want %v", err, flag.ErrHelp)
+	}
+	for _, want := range []string{
+		"-port value\n    \tHTTP port (env APP_PORT) (default 8080)",
+		"-db.max-conns value",
+	} {
+		if !strings.Contains(usage.String(), want) {
+			t.Errorf("usage doesn't contain %q:\n%s", want, usage.String())
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	var tests = []struct{ in, want string }{
+		{"Port", "port"},
+		{"MaxConns", "max_conns"},
+		{"HTTPPort", "http_port"},
+		{"DBURL", "dburl"},
+		{"UserID", "user_id"},
+	}
+	for _, tt := range tests {
+		if got := snakeCase(tt.in); got != tt.want {
+			t.Errorf("snakeCase(%q): got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoadNeedsStructPointer(t *testing.T) {
+	var c testConfig
+	if _, err := (&Loader{}).Load(c); err == nil {
+		t.Error("no error for a non-pointer")
+	}
+	var bad struct{ Ch chan int }
+	if _, err := (&Loader{}).Load(&bad); err == nil {
+		t.Error("no error for an unsupported type")
+	}
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// entry is one key = value from a config file. value is a string, or a
+// []string for an array.
+type entry struct {
+	key   string
+	value any
+	pos   string // "app.toml:12", or just the file name for JSON
+}
+
+func readFile(path string) ([]entry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	name := filepath.Base(path)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return parseJSON(name, b)
+	}
+	return parseTOML(name, b)
+}
+
+// parseJSON flattens a JSON object into dotted keys.
+func parseJSON(name string, b []byte) ([]entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", name, err)
+	}
+	var entries []entry
+	var walk func(prefix string, obj map[string]any) error
+	walk = func(prefix string, obj map[string]any) error {
+		for _, k := range slices.Sorted(maps.Keys(obj)) {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			switch v := obj[k].(type) {
+			case nil:
+			case map[string]any:
+				if err := walk(key, v); err != nil {
+					return err
+				}
+			case []any:
+				list := make([]string, len(v))
+				for i, e := range v {
+					s, ok := jsonScalar(e)
+					if !ok {
+						return fmt.Errorf("config: %s: %s: arrays may only hold strings, numbers and booleans", name, key)
+					}
+					list[i] = s
+				}
+				entries = append(entries, entry{key, list, name})
+			default:
+				s, _ := jsonScalar(v)
+				entries = append(entries, entry{key, s, name})
+			}
+		}
+		return nil
+	}
+	if err := walk("", doc); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func jsonScalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// parseTOML reads the part of TOML a config file needs: "key = value" lines,
+// "[section]" headers that prefix the keys after them, # comments, quoted
+// strings, and one-line arrays. Unquoted values, such as 30s, are taken as
+// they are.
+func parseTOML(name string, b []byte) ([]entry, error) {
+	var entries []entry
+	seen := make(map[string]bool)
+	section := ""
+	for i, line := range strings.Split(string(b), "\n") {
+		pos := fmt.Sprintf("%s:%d", name, i+1)
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("config: %s: unterminated section header", pos)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if !validKey(section) {
+				return nil, fmt.Errorf("config: %s: bad section name %q", pos, section)
+			}
+			continue
+		}
+		k, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("config: %s: want key = value", pos)
+		}
+		key := strings.TrimSpace(k)
+		if !validKey(key) {
+			return nil, fmt.Errorf("config: %s: bad key %q", pos, key)
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("config: %s: %s set twice", pos, key)
+		}
+		seen[key] = true
+		value, err := tomlValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %s: %w", pos, key, err)
+		}
+		entries = append(entries, entry{key, value, pos})
+	}
+	return entries, nil
+}
+
+func validKey(k string) bool {
+	if k == "" || strings.HasPrefix(k, ".") || strings.HasSuffix(k, ".") || strings.Contains(k, "..") {
+		return false
+	}
+	for _, r := range k {
+		if !(r == '_' || r == '-' || r == '.' || '0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// stripComment removes a # comment that isn't inside quotes.
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote && !(quote == '"' && escaped(line, i)) {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// escaped reports whether the byte at i is preceded by an odd number of
+// backslashes.
+func escaped(s string, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func tomlValue(raw string) (any, error) {
+	if strings.HasPrefix(raw, "[") {
+		if !strings.HasSuffix(raw, "]") {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		inner := strings.TrimSpace(raw[1 : len(raw)-1])
+		list := []string{}
+		for inner != "" {
+			item, rest, err := nextItem(inner)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			inner = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ","))
+		}
+		return list, nil
+	}
+	if raw == "" || raw[0] != '"' && raw[0] != '\'' {
+		// Unquoted: taken as it is, commas and all.
+		return raw, nil
+	}
+	item, rest, err := nextItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("unexpected %q after value", rest)
+	}
+	return item, nil
+}
+
+// nextItem reads one scalar from the start of s: a quoted string or
+// everything up to a comma.
+func nextItem(s string) (item, rest string, err error) {
+	switch s[0] {
+	case '"':
+		for i := 1; i < len(s); i++ {
+			if s[i] == '"' && !escaped(s, i) {
+				item, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", fmt.Errorf("bad string %s", s[:i+1])
+				}
+				return item, s[i+1:], nil
+			}
+		}
+		return "", "", fmt.Errorf("unterminated string")
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	item, rest, _ = strings.Cut(s, ",")
+	return strings.TrimSpace(item), rest, nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	const in = `
+top = 1
+quoted = "a # not a comment \"quoted\""
+literal = 'C:\path'   # comment
+bare = 30s
+commas = a,b , c
+empty = []
+list = ["x", 'y', z, 2]
+
+[server.http]
+port = 80
+`
+	got, err := parseTOML("f.toml", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []entry{
+		{"top", "1", "f.toml:2"},
+		{"quoted", `a # not a comment "quoted"`, "f.toml:3"},
+		{"literal", `C:\path`, "f.toml:4"},
+		{"bare", "30s", "f.toml:5"},
+		{"commas", "a,b , c", "f.toml:6"},
+		{"empty", []string{}, "f.toml:7"},
+		{"list", []string{"x", "y", "z", "2"}, "f.toml:8"},
+		{"server.http.port", "80", "f.toml:11"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, in := range []string{
+		"[section",
+		"[bad section]",
+		"key",
+		"bad key = 1",
+		"a = 1\na = 2",
+		`s = "unterminated`,
+		`s = "a" "b"`,
+		"l = [1, 2",
+	} {
+		if _, err := parseTOML("f.toml", []byte(in)); err == nil {
+			t.Errorf("%q: no error", in)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	got, err := parseJSON("f.json", []byte(`{"b": {"c": true, "d": null}, "a": [1, "x"], "n": 1.50}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []entry{
+		{"a", []string{"1", "x"}, "f.json"},
+		{"b.c", "true", "f.json"},
+		{"n", "1.50", "f.json"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+	for _, in := range []string{`[1]`, `{"a": [[1]]}`, `{"a": [{"b": 1}]}`, `{`} {
+		if _, err := parseJSON("f.json", []byte(in)); err == nil {
+			t.Errorf("%s: no error", in)
+		}
+	}
+}
+
+func FuzzParseTOML(f *testing.F) {
+	f.Add(tomlFile)
+	f.Add(`a = "x\"#y" # z` + "\n[b]\nc = ['p', \"q\", r]")
+	f.Fuzz(func(t *testing.T, in string) {
+		parseTOML("f.toml", []byte(in)) // must not panic
+	})
+}
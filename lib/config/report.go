@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Report lists every field Load filled, in struct order.
+type Report struct {
+	Fields []*Field
+	// File is the config file Load read, or "" if none.
+	File string
+}
+
+// Field returns the field with the given dotted key, or nil.
+func (r *Report) Field(key string) *Field {
+	for _, f := range r.Fields {
+		if f.Key == key {
+			return f
+		}
+	}
+	return nil
+}
+
+// Redacted is printed in place of a secret's value.
+const Redacted = "[redacted]"
+
+// Print writes the effective config, one "key = value" line per field in
+// the TOML-like file format, each with a comment saying where its value
+// came from. Secret values are replaced by Redacted unless they are empty.
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	for _, f := range r.Fields {
+		from := f.From
+		if f.Source == Unset {
+			from = "unset"
+		}
+		fmt.Fprintf(tw, "%s\t= %s\t# %s\n", f.Key, display(f), from)
+	}
+	return tw.Flush()
+}
+
+// display formats a field's value for Print and error messages.
+func display(f *Field) string {
+	if f.Secret && !f.value.IsZero() {
+		return Redacted
+	}
+	return format(f.value)
+}
+
+func format(v reflect.Value) string {
+	if v.Type() == durationType {
+		return fmt.Sprintf("%q", time.Duration(v.Int()).String())
+	}
+	if isText(v) {
+		if s, ok := v.Addr().Interface().(fmt.Stringer); ok {
+			return fmt.Sprintf("%q", s.String())
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = format(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,154 @@
+package counters
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Workload describes how a counter is exercised.
+type Workload struct {
+	// Goroutines share the operations between them. Less than 1 means 1.
+	Goroutines int
+	// Procs is the GOMAXPROCS value during the run; 0 leaves it unchanged.
+	Procs int
+	// ReadPercent is the share of operations, 0 to 100, that call Load
+	// rather than Inc.
+	ReadPercent int
+}
+
+// Run performs n operations on c, spread evenly over w.Goroutines goroutines,
+// and returns the number of them that were increments. It ignores w.Procs.
+func Run(c Counter, n int, w Workload) int {
+	g := max(w.Goroutines, 1)
+	var wg sync.WaitGroup
+	incs := 0
+	for i := 0; i < g; i++ {
+		ops := n / g
+		if i < n%g {
+			ops++
+		}
+		for j := 0; j < ops; j++ {
+			if !isRead(j, w.ReadPercent) {
+				incs++
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < ops; j++ {
+				if isRead(j, w.ReadPercent) {
+					c.Load()
+				} else {
+					c.Inc()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return incs
+}
+
+// isRead spreads reads evenly through a goroutine's operations.
+func isRead(j, readPercent int) bool {
+	return j%100 < readPercent
+}
+
+// Result is the measurement of one implementation under one workload.
+type Result struct {
+	Impl string
+	Workload
+	N           int // operations in the measured run
+	Elapsed     time.Duration
+	NsPerOp     float64
+	AllocsPerOp float64
+	BytesPerOp  float64
+	OpsPerSec   float64
+}
+
+// Measure runs impl under w with a growing number of operations, the way go
+// test -bench does, until a run takes at least benchtime, and reports on that
+// run. It checks the counter's final value, so a broken implementation is an
+// error rather than a fast result.
+func Measure(impl Impl, w Workload, benchtime time.Duration) (Result, error) {
+	if w.ReadPercent < 0 || w.ReadPercent > 100 {
+		return Result{}, fmt.Errorf("counters: read percent %d is not between 0 and 100", w.ReadPercent)
+	}
+	if w.Procs > 0 {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(w.Procs))
+	}
+	w.Goroutines = max(w.Goroutines, 1)
+
+	n := 1
+	for {
+		r, err := measureOnce(impl, w, n)
+		if err != nil {
+			return Result{}, err
+		}
+		if r.Elapsed >= benchtime || n >= 1e9 {
+			return r, nil
+		}
+		// Aim 20% past benchtime, growing at least by one and at most 100x.
+		next := n * 100
+		if ns := r.Elapsed.Nanoseconds(); ns > 0 {
+			next = min(int(1.2*float64(benchtime.Nanoseconds())*float64(n)/float64(ns)), next)
+		}
+		n = max(next, n+1)
+	}
+}
+
+func measureOnce(impl Impl, w Workload, n int) (Result, error) {
+	c := impl.New()
+	defer c.Close()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	incs := Run(c, n, w)
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if got := c.Load(); got != uint64(incs) {
+		return Result{}, fmt.Errorf("counters: %s counted %d increments, want %d", impl.Name, got, incs)
+	}
+	r := Result{
+		Impl:        impl.Name,
+		Workload:    w,
+		N:           n,
+		Elapsed:     elapsed,
+		NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+		AllocsPerOp: float64(after.Mallocs-before.Mallocs) / float64(n),
+		BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / float64(n),
+	}
+	if r.Workload.Procs == 0 {
+		r.Workload.Procs = runtime.GOMAXPROCS(0)
+	}
+	if elapsed > 0 {
+		r.OpsPerSec = float64(n) / elapsed.Seconds()
+	}
+	return r, nil
+}
+
+// Sweep measures every implementation at every combination of goroutine
+// count and GOMAXPROCS value. done, if not nil, is called after each
+// measurement, for progress output.
+func Sweep(impls []Impl, goroutines, procs []int, readPercent int, benchtime time.Duration, done func(Result)) ([]Result, error) {
+	var results []Result
+	for _, p := range procs {
+		for _, g := range goroutines {
+			for _, impl := range impls {
+				r, err := Measure(impl, Workload{Goroutines: g, Procs: p, ReadPercent: readPercent}, benchtime)
+				if err != nil {
+					return results, err
+				}
+				results = append(results, r)
+				if done != nil {
+					done(r)
+				}
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,110 @@
+// Package counters compares the ways the examples share a counter between
+// goroutines: a mutex (Mutexes.go), an atomic (AtomicCounter.go) and a
+// goroutine that owns the count and is asked about it over a channel
+// (StatefulGoroutines.go). Each is a Counter, so the same workload can be run
+// against all three and measured with Measure.
+package counters
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a count shared by many goroutines.
+type Counter interface {
+	Inc()
+	Load() uint64
+	// Close releases whatever the counter holds, such as the owning goroutine
+	// of a ChannelCounter. The counter must not be used afterwards.
+	Close()
+}
+
+// MutexCounter guards its count with a sync.Mutex. The zero value is ready
+// to use.
+type MutexCounter struct {
+	mu sync.Mutex
+	n  uint64
+}
+
+func NewMutex() Counter { return &MutexCounter{} }
+
+func (c *MutexCounter) Inc() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n++
+}
+
+func (c *MutexCounter) Load() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func (c *MutexCounter) Close() {}
+
+// AtomicCounter is an atomic.Uint64. The zero value is ready to use.
+type AtomicCounter struct {
+	n atomic.Uint64
+}
+
+func NewAtomic() Counter { return &AtomicCounter{} }
+
+func (c *AtomicCounter) Inc()         { c.n.Add(1) }
+func (c *AtomicCounter) Load() uint64 { return c.n.Load() }
+func (c *AtomicCounter) Close()       {}
+
+// ChannelCounter keeps its count in a goroutine of its own. Inc and Load send
+// that goroutine a request, and Load waits for the reply on a channel of its
+// own, as the readOp in StatefulGoroutines.go does.
+type ChannelCounter struct {
+	ops chan chan uint64 // a nil request is an increment
+}
+
+// NewChannel starts the goroutine owning the count; Close stops it.
+func NewChannel() Counter {
+	c := &ChannelCounter{ops: make(chan chan uint64)}
+	go func() {
+		var n uint64
+		for resp := range c.ops {
+			if resp == nil {
+				n++
+			} else {
+				resp <- n
+			}
+		}
+	}()
+	return c
+}
+
+func (c *ChannelCounter) Inc() { c.ops <- nil }
+
+func (c *ChannelCounter) Load() uint64 {
+	resp := make(chan uint64, 1)
+	c.ops <- resp
+	return <-resp
+}
+
+func (c *ChannelCounter) Close() { close(c.ops) }
+
+// Impl names a Counter implementation.
+type Impl struct {
+	Name string
+	New  func() Counter
+}
+
+// Impls lists every implementation, in the order they're reported.
+var Impls = []Impl{
+	{Name: "mutex", New: NewMutex},
+	{Name: "atomic", New: NewAtomic},
+	{Name: "channel", New: NewChannel},
+}
+
+// Find returns the implementation called name.
+func Find(name string) (Impl, bool) {
+	for _, impl := range Impls {
+		if impl.Name == name {
+			return impl, true
+		}
+	}
+	return Impl{}, false
+}
@@ -0,0 +1,146 @@
+package counters
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounters(t *testing.T) {
+	var tests = []Workload{
+		{Goroutines: 1},
+		{Goroutines: 50},
+		{Goroutines: 8, ReadPercent: 50},
+		{Goroutines: 8, ReadPercent: 100},
+	}
+
+	for _, impl := range Impls {
+		for _, w := range tests {
+			testname := fmt.Sprintf("%s/goroutines=%d/reads=%d", impl.Name, w.Goroutines, w.ReadPercent)
+			t.Run(testname, func(t *testing.T) {
+				c := impl.New()
+				defer c.Close()
+				incs := Run(c, 80000, w)
+				if want := 80000 * (100 - w.ReadPercent) / 100; incs != want {
+					t.Errorf("Run did %d increments, want %d", incs, want)
+				}
+				if got := c.Load(); got != uint64(incs) {
+					t.Errorf("got %d, want %d", got, incs)
+				}
+			})
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	for _, name := range []string{"mutex", "atomic", "channel"} {
+		if _, ok := Find(name); !ok {
+			t.Errorf("Find(%q) found nothing", name)
+		}
+	}
+	if _, ok := Find("spinlock"); ok {
+		t.Error(`Find("spinlock") found something`)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	impl, _ := Find("channel")
+	r, err := Measure(impl, Workload{Goroutines: 4, Procs: 2, ReadPercent: 10}, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Impl != "channel" || r.Goroutines != 4 || r.Procs != 2 || r.ReadPercent != 10 {
+		t.Errorf("result doesn't describe the workload: %+v", r)
+	}
+	if r.Elapsed < 20*time.Millisecond || r.N < 2 || r.NsPerOp <= 0 || r.OpsPerSec <= 0 {
+		t.Errorf("implausible measurement: %+v", r)
+	}
+	// Every Load makes a reply channel.
+	if r.AllocsPerOp < 0.1 {
+		t.Errorf("AllocsPerOp = %.3f, want at least 0.1", r.AllocsPerOp)
+	}
+
+	if _, err := Measure(impl, Workload{ReadPercent: 101}, time.Millisecond); err == nil {
+		t.Error("Measure accepted a read percent of 101")
+	}
+}
+
+func TestMeasureCatchesLostIncrements(t *testing.T) {
+	broken := Impl{Name: "racy", New: func() Counter { return &lossy{} }}
+	if _, err := Measure(broken, Workload{Goroutines: 1}, time.Millisecond); err == nil {
+		t.Error("Measure accepted a counter that loses increments")
+	}
+}
+
+// lossy drops every other increment.
+type lossy struct{ n, calls uint64 }
+
+func (c *lossy) Inc() {
+	c.calls++
+	if c.calls%2 == 0 {
+		c.n++
+	}
+}
+func (c *lossy) Load() uint64 { return c.n }
+func (c *lossy) Close()       {}
+
+func TestReports(t *testing.T) {
+	results := []Result{
+		{Impl: "mutex", Workload: Workload{Goroutines: 4, Procs: 2}, N: 1000, NsPerOp: 40, OpsPerSec: 25e6},
+		{Impl: "atomic", Workload: Workload{Goroutines: 4, Procs: 2}, N: 1000, NsPerOp: 10, OpsPerSec: 1e8},
+	}
+
+	var table bytes.Buffer
+	if err := WriteTable(&table, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("table has %d lines, want 3:\n%s", len(lines), table.String())
+	}
+	if !strings.Contains(lines[1], "4.00x") || !strings.Contains(lines[2], "1.00x") {
+		t.Errorf("vs best column is wrong:\n%s", table.String())
+	}
+	if !strings.Contains(lines[2], "100.00M") {
+		t.Errorf("ops/s column is wrong:\n%s", table.String())
+	}
+
+	var out bytes.Buffer
+	if err := WriteCSV(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(CSVHeader, ",") {
+		t.Fatalf("got %q", records)
+	}
+	if got := strings.Join(records[2], ","); got != "atomic,4,2,0,1000,10.000,0.000,0.000,100000000" {
+		t.Errorf("got %s", got)
+	}
+}
+
+// BenchmarkCounters runs every implementation at a few goroutine counts.
+// GOMAXPROCS is go test's -cpu flag:
+//
+//	go test -bench Counters -benchmem -cpu 1,2,4,8 ./counters
+func BenchmarkCounters(b *testing.B) {
+	for _, reads := range []int{0, 90} {
+		for _, g := range []int{1, 4, 16, 64} {
+			for _, impl := range Impls {
+				w := Workload{Goroutines: g, ReadPercent: reads}
+				b.Run(fmt.Sprintf("reads=%d/goroutines=%d/%s", reads, g, impl.Name), func(b *testing.B) {
+					c := impl.New()
+					defer c.Close()
+					b.ReportAllocs()
+					b.ResetTimer()
+					Run(c, b.N, w)
+				})
+			}
+		}
+	}
+}
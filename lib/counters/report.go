@@ -0,0 +1,74 @@
+package counters
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// WriteTable writes results as an aligned table. The "vs best" column
+// compares each result's ns/op to the fastest implementation under the same
+// workload, so 1.00x marks the one to pick.
+func WriteTable(w io.Writer, results []Result) error {
+	best := make(map[Workload]float64)
+	for _, r := range results {
+		if b, ok := best[r.Workload]; !ok || r.NsPerOp < b {
+			best[r.Workload] = r.NsPerOp
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "impl\tgoroutines\tgomaxprocs\treads\tns/op\tallocs/op\tB/op\tops/s\tvs best\t")
+	for _, r := range results {
+		rel := 0.0
+		if b := best[r.Workload]; b > 0 {
+			rel = r.NsPerOp / b
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d%%\t%.2f\t%.2f\t%.1f\t%s\t%.2fx\t\n",
+			r.Impl, r.Goroutines, r.Procs, r.ReadPercent,
+			r.NsPerOp, r.AllocsPerOp, r.BytesPerOp, humanRate(r.OpsPerSec), rel)
+	}
+	return tw.Flush()
+}
+
+// humanRate shortens an operations-per-second figure: 12.3M rather than
+// 12345678.
+func humanRate(v float64) string {
+	switch {
+	case v >= 1e9:
+		return fmt.Sprintf("%.2fG", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("%.2fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.2fk", v/1e3)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+// CSVHeader is the first record WriteCSV writes.
+var CSVHeader = []string{"impl", "goroutines", "gomaxprocs", "read_percent", "n", "ns_per_op", "allocs_per_op", "bytes_per_op", "ops_per_sec"}
+
+// WriteCSV writes results as CSV for spreadsheets and plotting. The per-op
+// figures keep three decimals, finer than WriteTable shows, and ops_per_sec is
+// rounded to a whole number.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write(CSVHeader)
+	for _, r := range results {
+		cw.Write([]string{
+			r.Impl,
+			strconv.Itoa(r.Goroutines),
+			strconv.Itoa(r.Procs),
+			strconv.Itoa(r.ReadPercent),
+			strconv.Itoa(r.N),
+			strconv.FormatFloat(r.NsPerOp, 'f', 3, 64),
+			strconv.FormatFloat(r.AllocsPerOp, 'f', 3, 64),
+			strconv.FormatFloat(r.BytesPerOp, 'f', 3, 64),
+			strconv.FormatFloat(r.OpsPerSec, 'f', 0, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,418 @@
+// Package cron runs jobs on cron schedules. It replaces the hand-stopped
+// ticker of Tickers.go for periodic maintenance: each job has a schedule
+// ("*/5 * * * *", "@daily", "@every 90s"), optional jitter, and a policy for
+// runs missed while the scheduler was stopped or the machine asleep. A job
+// never overlaps itself, and the scheduler keeps a history of every run and
+// its error.
+package cron
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/clock"
+)
+
+// CatchUp says what to do about runs whose time passed while the scheduler
+// couldn't run them.
+type CatchUp int
+
+const (
+	// SkipMissed records missed runs in the history without running them.
+	SkipMissed CatchUp = iota
+	// RunOnce makes one run stand in for all the missed ones.
+	RunOnce
+	// RunAll makes every missed run, one after another.
+	RunAll
+)
+
+func (c CatchUp) String() string {
+	switch c {
+	case SkipMissed:
+		return "skip"
+	case RunOnce:
+		return "once"
+	case RunAll:
+		return "all"
+	}
+	return fmt.Sprintf("CatchUp(%d)", int(c))
+}
+
+// maxCatchUp caps how many missed runs are counted, and run by RunAll, after
+// a long pause.
+const maxCatchUp = 1000
+
+// Options configure a job.
+type Options struct {
+	// Jitter delays each run by a random duration up to this long, so jobs
+	// on the same schedule across many machines don't all start at once.
+	Jitter time.Duration
+	// CatchUp is the policy for missed runs.
+	CatchUp CatchUp
+	// Timeout, if set, cancels the context each run gets once it has run
+	// that long by the scheduler's Clock; its Err is then DeadlineExceeded.
+	Timeout time.Duration
+}
+
+// Run is one entry in a job's history.
+type Run struct {
+	Scheduled time.Time // the activation time from the schedule
+	Started   time.Time // zero if the run was skipped
+	Finished  time.Time
+	Err       error
+	// Skipped says why the run didn't happen: it was missed, or the
+	// previous run was still going. Empty for runs that happened.
+	Skipped string
+}
+
+// Duration returns how long the run took.
+func (r Run) Duration() time.Duration { return r.Finished.Sub(r.Started) }
+
+// Job is a scheduled function.
+type Job struct {
+	Name     string
+	Spec     string
+	Schedule Schedule
+	Options  Options
+
+	fn func(context.Context) error
+
+	mu      sync.Mutex
+	due     time.Time // next activation from the schedule
+	planned time.Time // due plus jitter: when it will really run
+	running bool
+	history []Run
+	limit   int
+}
+
+// Next returns when the job will next run, jitter included.
+func (j *Job) Next() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.planned
+}
+
+// Running reports whether a run is in progress.
+func (j *Job) Running() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.running
+}
+
+// History returns the job's most recent runs, oldest first.
+func (j *Job) History() []Run {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return slices.Clone(j.history)
+}
+
+func (j *Job) record(r Run) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.history = append(j.history, r)
+	if over := len(j.history) - j.limit; over > 0 {
+		j.history = slices.Delete(j.history, 0, over)
+	}
+}
+
+// plan sets the job's next activation to the first one after t. j.mu must
+// be held.
+func (j *Job) plan(t time.Time) {
+	j.due = j.Schedule.Next(t)
+	j.planned = j.due
+	if j.Options.Jitter > 0 && !j.due.IsZero() {
+		j.planned = j.due.Add(rand.N(j.Options.Jitter))
+	}
+}
+
+// Scheduler runs jobs. Set its fields before adding jobs.
+type Scheduler struct {
+	// Location is the time zone of schedules without a CRON_TZ prefix.
+	Location *time.Location
+	// Grace is how late a run can start before it counts as missed.
+	Grace time.Duration
+	// HistorySize is the number of runs kept per job.
+	HistorySize int
+	// Clock is the time source; tests use a clock.Fake.
+	Clock clock.Clock
+
+	mu      sync.Mutex
+	jobs    []*Job
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// New returns a stopped scheduler using time.Local, a one-second grace and
+// a history of 20 runs per job.
+func New() *Scheduler {
+	return &Scheduler{
+		Location:    time.Local,
+		Grace:       time.Second,
+		HistorySize: 20,
+		Clock:       clock.Real,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Add schedules fn under name. The first run is the schedule's first
+// activation after now, even if the scheduler is started later: runs due
+// before Start are treated as missed.
+func (s *Scheduler) Add(name, spec string, fn func(ctx context.Context) error, opts Options) (*Job, error) {
+	sched, err := ParseIn(spec, s.Location)
+	if err != nil {
+		return nil, err
+	}
+	j := &Job{Name: name, Spec: spec, Schedule: sched, Options: opts, fn: fn, limit: max(s.HistorySize, 1)}
+	j.plan(s.Clock.Now())
+	if j.due.IsZero() {
+		return nil, fmt.Errorf("cron: %q never runs", spec)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.ContainsFunc(s.jobs, func(o *Job) bool { return o.Name == name }) {
+		return nil, fmt.Errorf("cron: a job named %q already exists", name)
+	}
+	s.jobs = append(s.jobs, j)
+	s.poke()
+	return j, nil
+}
+
+// Remove unschedules the job called name, letting a run in progress finish.
+// It reports whether there was such a job.
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.jobs, func(j *Job) bool { return j.Name == name })
+	if i < 0 {
+		return false
+	}
+	s.jobs = slices.Delete(s.jobs, i, i+1)
+	return true
+}
+
+// Job returns the job called name, or nil.
+func (s *Scheduler) Job(name string) *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// Jobs returns every job, in the order added.
+func (s *Scheduler) Jobs() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.jobs)
+}
+
+// poke wakes the scheduling goroutine to look at the jobs again.
+func (s *Scheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// maxSleep bounds how long the scheduler sleeps, so a wall clock that jumps,
+// or a machine that was suspended, is noticed soon after.
+const maxSleep = time.Minute
+
+// Start starts scheduling in a new goroutine.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.loop(s.stop, s.done)
+}
+
+// Stop stops scheduling, cancels the context of runs in progress and waits
+// for them to return. Runs that fall due while stopped are handled by each
+// job's CatchUp policy when the scheduler is started again.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	stop, done, cancel := s.stop, s.done, s.cancel
+	s.stop = nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	cancel()
+	s.running.Wait()
+}
+
+func (s *Scheduler) loop(stop, done chan struct{}) {
+	defer close(done)
+	timer := s.Clock.NewTimer(0)
+	defer timer.Stop()
+	for {
+		now := s.Clock.Now()
+		s.dispatch(now)
+		timer.Reset(s.sleep(now))
+		select {
+		case <-stop:
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// sleep returns how long to wait for the next planned run.
+func (s *Scheduler) sleep(now time.Time) time.Duration {
+	d := maxSleep
+	for _, j := range s.Jobs() {
+		if next := j.Next(); !next.IsZero() {
+			d = min(d, next.Sub(now))
+		}
+	}
+	return max(d, 0)
+}
+
+// dispatch starts every job that is due at now.
+func (s *Scheduler) dispatch(now time.Time) {
+	for _, j := range s.Jobs() {
+		if runs := s.due(j, now); len(runs) > 0 {
+			s.running.Add(1)
+			go s.execute(j, runs)
+		}
+	}
+}
+
+// due works out which of j's activations up to now should run, records the
+// ones that won't, and moves j on to its next activation. When it returns
+// runs, j is marked running.
+func (s *Scheduler) due(j *Job, now time.Time) []time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.planned.IsZero() || j.planned.After(now) {
+		return nil
+	}
+
+	// Every activation up to now; the jitter of later ones doesn't matter.
+	dues := []time.Time{j.due}
+	late := now.Sub(j.planned) > s.Grace
+	next := j.Schedule.Next(j.due)
+	for !next.IsZero() && !next.After(now) && len(dues) < maxCatchUp {
+		dues = append(dues, next)
+		next = j.Schedule.Next(next)
+	}
+	if len(dues) > 1 {
+		late = true
+	}
+	j.plan(dues[len(dues)-1])
+	if !j.due.IsZero() && !j.due.After(now) {
+		// More than maxCatchUp runs behind: jump ahead.
+		j.plan(now)
+	}
+
+	skip := func(ts []time.Time, why string) {
+		for _, t := range ts {
+			j.history = append(j.history, Run{Scheduled: t, Skipped: why})
+		}
+		if over := len(j.history) - j.limit; over > 0 {
+			j.history = slices.Delete(j.history, 0, over)
+		}
+	}
+	switch {
+	case j.running:
+		skip(dues, "previous run still running")
+		return nil
+	case !late:
+	case j.Options.CatchUp == SkipMissed:
+		skip(dues, "missed")
+		return nil
+	case j.Options.CatchUp == RunOnce:
+		skip(dues[:len(dues)-1], "missed, caught up by a later run")
+		dues = dues[len(dues)-1:]
+	}
+	j.running = true
+	return dues
+}
+
+// execute makes the runs and records them.
+func (s *Scheduler) execute(j *Job, runs []time.Time) {
+	defer s.running.Done()
+	defer func() {
+		j.mu.Lock()
+		j.running = false
+		j.mu.Unlock()
+	}()
+	s.mu.Lock()
+	parent := s.ctx
+	s.mu.Unlock()
+	if parent == nil {
+		parent = context.Background()
+	}
+	for _, at := range runs {
+		if parent.Err() != nil {
+			j.record(Run{Scheduled: at, Skipped: "scheduler stopped"})
+			continue
+		}
+		ctx, cancel := parent, context.CancelFunc(func() {})
+		if j.Options.Timeout > 0 {
+			ctx, cancel = s.withTimeout(parent, j.Options.Timeout)
+		}
+		r := Run{Scheduled: at, Started: s.Clock.Now()}
+		r.Err = call(ctx, j)
+		r.Finished = s.Clock.Now()
+		cancel()
+		j.record(r)
+	}
+}
+
+// withTimeout is context.WithTimeout on s.Clock, so a fake clock drives job
+// timeouts too. The context has no Deadline, since that would be read
+// against the real clock, but its Err is DeadlineExceeded once d has passed.
+func (s *Scheduler) withTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	t := s.Clock.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+	return timeoutCtx{ctx}, func() {
+		t.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// timeoutCtx reports the cause it was canceled with as its Err when that
+// cause is a timeout.
+type timeoutCtx struct {
+	context.Context
+}
+
+func (c timeoutCtx) Err() error {
+	err := c.Context.Err()
+	if err != nil && context.Cause(c.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// call runs the job's function, turning a panic into an error.
+func call(ctx context.Context, j *Job) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("cron: job %s panicked: %v\n\n%s", j.Name, v, debug.Stack())
+		}
+	}()
+	return j.fn(ctx)
+}
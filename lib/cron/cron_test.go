@@ -0,0 +1,317 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/clock"
+)
+
+var start = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestScheduler() (*Scheduler, *clock.Fake) {
+	c := clock.NewFake(start)
+	s := New()
+	s.Location = time.UTC
+	s.Clock = c
+	return s, c
+}
+
+// step moves the clock to t and runs whatever is due, waiting for it.
+func step(s *Scheduler, c *clock.Fake, t time.Time) {
+	c.Set(t)
+	s.dispatch(t)
+	s.running.Wait()
+}
+
+func TestRunsOnSchedule(t *testing.T) {
+	s, c := newTestScheduler()
+	var runs atomic.Int32
+	j, err := s.Add("tick", "*/5 * * * *", func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := j.Next(), start.Add(5*time.Minute); !got.Equal(want) {
+		t.Fatalf("Next() = %v, want %v", got, want)
+	}
+	step(s, c, start.Add(4*time.Minute))
+	if runs.Load() != 0 {
+		t.Fatal("ran early")
+	}
+	for i := 1; i <= 3; i++ {
+		step(s, c, start.Add(time.Duration(5*i)*time.Minute))
+	}
+	if got := runs.Load(); got != 3 {
+		t.Errorf("got %v runs, want %v", got, 3)
+	}
+	h := j.History()
+	if len(h) != 3 || !h[2].Scheduled.Equal(start.Add(15*time.Minute)) || h[2].Skipped != "" {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	var tests = []struct {
+		policy      CatchUp
+		wantRuns    int
+		wantSkipped int
+	}{
+		{SkipMissed, 0, 6},
+		{RunOnce, 1, 5},
+		{RunAll, 6, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.policy), func(t *testing.T) {
+			s, c := newTestScheduler()
+			var runs atomic.Int32
+			j, err := s.Add("job", "* * * * *", func(context.Context) error {
+				runs.Add(1)
+				return nil
+			}, Options{CatchUp: tt.policy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Asleep from 10:00 to 10:06:30: runs at 10:01 ... 10:06 missed.
+			step(s, c, start.Add(6*time.Minute+30*time.Second))
+			skipped := 0
+			for _, r := range j.History() {
+				if r.Skipped != "" {
+					skipped++
+				}
+			}
+			if int(runs.Load()) != tt.wantRuns || skipped != tt.wantSkipped {
+				t.Errorf("got %d runs and %d skipped, want %d and %d", runs.Load(), skipped, tt.wantRuns, tt.wantSkipped)
+			}
+			if got, want := j.Next(), start.Add(7*time.Minute); !got.Equal(want) {
+				t.Errorf("Next() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLateRunIsMissed(t *testing.T) {
+	s, c := newTestScheduler()
+	j, _ := s.Add("job", "* * * * *", func(context.Context) error { return nil }, Options{})
+	step(s, c, start.Add(time.Minute+5*time.Second))
+	if h := j.History(); len(h) != 1 || h[0].Skipped != "missed" {
+		t.Errorf("history = %+v", h)
+	}
+	step(s, c, start.Add(2*time.Minute+500*time.Millisecond)) // within Grace
+	if h := j.History(); len(h) != 2 || h[1].Skipped != "" {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func TestNoOverlap(t *testing.T) {
+	s, c := newTestScheduler()
+	release := make(chan struct{})
+	var runs atomic.Int32
+	j, _ := s.Add("slow", "* * * * *", func(context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	}, Options{})
+
+	c.Set(start.Add(time.Minute))
+	s.dispatch(start.Add(time.Minute))
+	for !j.Running() {
+		time.Sleep(time.Millisecond)
+	}
+	c.Set(start.Add(2 * time.Minute))
+	s.dispatch(start.Add(2 * time.Minute))
+	close(release)
+	s.running.Wait()
+
+	if runs.Load() != 1 {
+		t.Errorf("got %v runs, want %v", runs.Load(), 1)
+	}
+	h := j.History()
+	if len(h) != 2 || h[0].Skipped != "previous run still running" || h[1].Skipped != "" {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func TestErrorsAndPanics(t *testing.T) {
+	s, c := newTestScheduler()
+	boom := errors.New("boom")
+	failing, _ := s.Add("failing", "* * * * *", func(context.Context) error { return boom }, Options{})
+	panicking, _ := s.Add("panicking", "* * * * *", func(context.Context) error { panic("oops") }, Options{})
+	step(s, c, start.Add(time.Minute))
+
+	if h := failing.History(); len(h) != 1 || !errors.Is(h[0].Err, boom) {
+		t.Errorf("failing history = %+v", h)
+	}
+	if h := panicking.History(); len(h) != 1 || h[0].Err == nil || !strings.Contains(h[0].Err.Error(), "oops") {
+		t.Errorf("panicking history = %+v", h)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s, c := newTestScheduler()
+	// An hour by the fake clock: on the real one the test would time out.
+	j, _ := s.Add("job", "* * * * *", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Options{Timeout: time.Hour})
+	due := start.Add(time.Minute)
+	c.Set(due)
+	s.dispatch(due)
+	c.BlockUntil(1)
+	c.Advance(time.Hour - time.Second)
+	if h := j.History(); len(h) != 0 {
+		t.Fatalf("finished before the timeout: %+v", h)
+	}
+	c.Advance(time.Second)
+	s.running.Wait()
+	h := j.History()
+	if len(h) != 1 || !errors.Is(h[0].Err, context.DeadlineExceeded) {
+		t.Fatalf("history = %+v", h)
+	}
+	if got, want := h[0].Finished.Sub(h[0].Started), time.Hour; got != want {
+		t.Errorf("ran for %v, want %v", got, want)
+	}
+}
+
+func TestTimeoutStopsTimer(t *testing.T) {
+	s, c := newTestScheduler()
+	j, _ := s.Add("job", "* * * * *", func(context.Context) error { return nil }, Options{Timeout: time.Hour})
+	step(s, c, start.Add(time.Minute))
+	if h := j.History(); len(h) != 1 || h[0].Err != nil {
+		t.Errorf("history = %+v", h)
+	}
+	if n := c.Pending(); n != 0 {
+		t.Errorf("%d timers left on the clock after the run", n)
+	}
+}
+
+func TestHistorySize(t *testing.T) {
+	s, c := newTestScheduler()
+	s.HistorySize = 3
+	j, _ := s.Add("job", "* * * * *", func(context.Context) error { return nil }, Options{})
+	for i := 1; i <= 10; i++ {
+		step(s, c, start.Add(time.Duration(i)*time.Minute))
+	}
+	h := j.History()
+	if len(h) != 3 || !h[0].Scheduled.Equal(start.Add(8*time.Minute)) {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	s, c := newTestScheduler()
+	const jitter = 30 * time.Second
+	j, _ := s.Add("job", "* * * * *", func(context.Context) error { return nil }, Options{Jitter: jitter})
+	for i := 1; i <= 50; i++ {
+		due := start.Add(time.Duration(i) * time.Minute)
+		next := j.Next()
+		if next.Before(due) || !next.Before(due.Add(jitter)) {
+			t.Fatalf("Next() = %v, want in [%v, %v)", next, due, due.Add(jitter))
+		}
+		step(s, c, next)
+		if h := j.History(); !h[len(h)-1].Scheduled.Equal(due) || h[len(h)-1].Skipped != "" {
+			t.Fatalf("last run = %+v", h[len(h)-1])
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	s, _ := newTestScheduler()
+	noop := func(context.Context) error { return nil }
+	if _, err := s.Add("a", "@hourly", noop, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add("a", "@daily", noop, Options{}); err == nil {
+		t.Error("added a second job called a")
+	}
+	if _, err := s.Add("b", "0 0 30 2 *", noop, Options{}); err == nil {
+		t.Error("added a job that never runs")
+	}
+	if _, err := s.Add("c", "bogus", noop, Options{}); err == nil {
+		t.Error("added a job with a bad spec")
+	}
+	if s.Job("a") == nil || len(s.Jobs()) != 1 {
+		t.Fatalf("jobs = %v", s.Jobs())
+	}
+	if !s.Remove("a") || s.Remove("a") || s.Job("a") != nil {
+		t.Error("Remove didn't remove exactly once")
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	s := New()
+	var runs atomic.Int32
+	stopped := make(chan error, 1)
+	s.Add("fast", "@every 10ms", func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}, Options{})
+	s.Add("blocking", "@every 10ms", func(ctx context.Context) error {
+		<-ctx.Done()
+		select {
+		case stopped <- ctx.Err():
+		default:
+		}
+		return ctx.Err()
+	}, Options{})
+	s.Start()
+	s.Start() // no-op
+	deadline := time.Now().Add(5 * time.Second)
+	for runs.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.Stop()
+	if runs.Load() < 3 {
+		t.Fatalf("only %d runs", runs.Load())
+	}
+	if err := <-stopped; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	n := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != n {
+		t.Error("ran after Stop")
+	}
+	s.Stop() // no-op
+}
+
+func TestStartWithFakeClock(t *testing.T) {
+	s, c := newTestScheduler()
+	ran := make(chan time.Time)
+	j, _ := s.Add("hourly", "@hourly", func(context.Context) error {
+		ran <- c.Now()
+		return nil
+	}, Options{})
+	s.Start()
+	defer s.Stop()
+
+	for i := 1; i <= 3; i++ {
+		c.BlockUntil(1) // the scheduler is asleep
+		c.Advance(time.Hour)
+		if got, want := <-ran, start.Add(time.Duration(i)*time.Hour); !got.Equal(want) {
+			t.Errorf("run %d at %v, want %v", i, got, want)
+		}
+	}
+	s.Stop()
+	if h := j.History(); len(h) != 3 {
+		t.Errorf("history = %+v", h)
+	}
+}
+
+func BenchmarkDispatch(b *testing.B) {
+	s, _ := newTestScheduler()
+	for i := 0; i < 100; i++ {
+		s.Add(fmt.Sprint(i), "0 0 * * *", func(context.Context) error { return nil }, Options{})
+	}
+	for i := 0; i < b.N; i++ {
+		s.dispatch(start)
+	}
+}
@@ -0,0 +1,309 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule says when a job runs.
+type Schedule interface {
+	// Next returns the first activation strictly after t, or the zero time if
+	// there is none within five years.
+	Next(t time.Time) time.Time
+}
+
+// SpecSchedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type SpecSchedule struct {
+	Second, Minute, Hour, Dom, Month, Dow uint64
+	// DomStar and DowStar record a "*" or "?" day field: standard cron matches
+	// a day when both day fields do, unless both are restricted, when either
+	// will do.
+	DomStar, DowStar bool
+	Location         *time.Location
+
+	everyHour bool // the hour field is "*" or "*/1"
+}
+
+// EverySchedule runs at a fixed interval from whenever it is first asked.
+type EverySchedule struct {
+	Interval time.Duration
+}
+
+// Next returns t plus the interval.
+func (e EverySchedule) Next(t time.Time) time.Time {
+	return t.Add(e.Interval)
+}
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondBounds = bounds{0, 59, nil}
+	minuteBounds = bounds{0, 59, nil}
+	hourBounds   = bounds{0, 23, nil}
+	domBounds    = bounds{1, 31, nil}
+	monthBounds  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is Sunday as well as 0, as in most crons.
+	dowBounds = bounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// Parse parses a schedule in time.Local. See ParseIn.
+func Parse(spec string) (Schedule, error) {
+	return ParseIn(spec, time.Local)
+}
+
+// ParseIn parses a schedule whose times are in loc:
+//
+//   - five fields: minute hour day-of-month month day-of-week
+//   - six fields: second minute hour day-of-month month day-of-week
+//   - @yearly, @monthly, @weekly, @daily (or @midnight), @hourly
+//   - @every <duration>, e.g. "@every 90s"
+//
+// Fields take "*", numbers, ranges "1-5", steps "*/15" or "10-50/10", and
+// comma-separated lists of those. Months and weekdays also take names, "jan"
+// or "MON". A "CRON_TZ=Europe/Paris " or "TZ=..." prefix overrides loc.
+func ParseIn(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := cutTZ(spec); ok {
+		name, expr, _ := strings.Cut(rest, " ")
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("cron: %q: %w", spec, err)
+		}
+		loc, spec = l, strings.TrimSpace(expr)
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("cron: %q: %w", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("cron: %q: interval must be positive", spec)
+		}
+		return EverySchedule{d}, nil
+	}
+	expr := spec
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if expr, ok = descriptors[strings.ToLower(spec)]; !ok {
+			return nil, fmt.Errorf("cron: unknown descriptor %q", spec)
+		}
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: %q: want 5 or 6 fields, got %d", spec, len(fields))
+	}
+
+	s := &SpecSchedule{Location: loc}
+	var err error
+	parsers := []struct {
+		dst *uint64
+		b   bounds
+	}{
+		{&s.Second, secondBounds},
+		{&s.Minute, minuteBounds},
+		{&s.Hour, hourBounds},
+		{&s.Dom, domBounds},
+		{&s.Month, monthBounds},
+		{&s.Dow, dowBounds},
+	}
+	for i, p := range parsers {
+		if *p.dst, err = parseField(fields[i], p.b); err != nil {
+			return nil, fmt.Errorf("cron: %q: %w", spec, err)
+		}
+	}
+	if s.Dow&(1<<7) != 0 {
+		s.Dow = s.Dow&^(1<<7) | 1
+	}
+	s.DomStar = fields[3] == "*" || fields[3] == "?"
+	s.DowStar = fields[5] == "*" || fields[5] == "?"
+	s.everyHour = s.Hour == 1<<24-1
+	return s, nil
+}
+
+func cutTZ(spec string) (string, bool) {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if rest, ok := strings.CutPrefix(spec, prefix); ok {
+			return rest, true
+		}
+	}
+	return "", false
+}
+
+// parseField turns one field into the set of values it matches.
+func parseField(field string, b bounds) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, stepStr, hasStep := strings.Cut(part, "/")
+		lo, hi := b.min, b.max
+		switch {
+		case expr == "*" || expr == "?":
+		default:
+			loStr, hiStr, isRange := strings.Cut(expr, "-")
+			var err error
+			if lo, err = b.value(loStr); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = b.value(hiStr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "10/5" means from 10 to the end, every 5.
+				hi = b.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("range %q runs backwards", expr)
+			}
+		}
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step %q", stepStr)
+			}
+			step = n
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (b bounds) value(s string) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("%d out of range %d-%d", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+func has(set uint64, v int) bool { return set&(1<<v) != 0 }
+
+func (s *SpecSchedule) dayMatches(t time.Time) bool {
+	dom, dow := has(s.Dom, t.Day()), has(s.Dow, int(t.Weekday()))
+	if s.DomStar || s.DowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first matching second after t, in the schedule's
+// location.
+//
+// Daylight saving changes follow the wall clock, as in Vixie cron: a time
+// that a spring-forward gap skips doesn't run that day, and a time that a
+// fall-back repeats runs once, the first time, unless the schedule runs
+// every hour anyway.
+func (s *SpecSchedule) Next(t time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	orig := t.In(loc)
+	t = orig.Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+
+	for {
+		t = s.next(t, loc, yearLimit)
+		if t.IsZero() || s.everyHour || wall(t) > wall(orig) {
+			return t
+		}
+		// A repeated wall-clock time during a fall-back: look past it.
+		t = t.Add(time.Second)
+	}
+}
+
+func (s *SpecSchedule) next(t time.Time, loc *time.Location, yearLimit int) time.Time {
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for !has(s.Month, int(t.Month())) {
+		t = midnight(t.Year(), t.Month()+1, 1, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		t = midnight(t.Year(), t.Month(), t.Day()+1, loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for !has(s.Hour, t.Hour()) {
+		day := t.Day()
+		// Step by the absolute hour: time.Date would map an hour a
+		// spring-forward skips back onto the one before it.
+		t = t.Truncate(time.Minute).Add(-time.Duration(t.Minute())*time.Minute + time.Hour)
+		if t.Day() != day {
+			goto wrap
+		}
+	}
+	for !has(s.Minute, t.Minute()) {
+		hour := t.Hour()
+		t = t.Truncate(time.Minute).Add(time.Minute)
+		if t.Hour() != hour {
+			goto wrap
+		}
+	}
+	for !has(s.Second, t.Second()) {
+		minute := t.Minute()
+		t = t.Add(time.Second)
+		if t.Minute() != minute {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// midnight returns the start of a day. In the few zones whose clocks jump
+// forward at midnight, the day starts at one o'clock.
+func midnight(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if t.Hour() != 0 {
+		t = t.Add(time.Hour)
+	}
+	return t
+}
+
+// wall returns t's wall-clock reading as a number that orders correctly, so
+// times inside a repeated fall-back hour can be compared by what the clock
+// showed rather than by instant.
+func wall(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC).Unix()
+}
@@ -0,0 +1,165 @@
+package cron
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoad(t testing.TB, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestNext(t *testing.T) {
+	var tests = []struct {
+		spec string
+		from string
+		want string
+	}{
+		{"* * * * *", "2024-03-01T10:00:00Z", "2024-03-01T10:01:00Z"},
+		{"*/15 * * * *", "2024-03-01T10:07:30Z", "2024-03-01T10:15:00Z"},
+		{"30 * * * * *", "2024-03-01T10:00:30Z", "2024-03-01T10:01:30Z"},
+		{"0 9 * * mon-fri", "2024-03-01T10:00:00Z", "2024-03-04T09:00:00Z"}, // Friday to Monday
+		{"0 0 1 * *", "2024-01-31T12:00:00Z", "2024-02-01T00:00:00Z"},
+		{"0 0 29 2 *", "2024-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"0 0 31 * *", "2024-04-01T00:00:00Z", "2024-05-31T00:00:00Z"},
+		{"0 12 * * 7", "2024-03-01T00:00:00Z", "2024-03-03T12:00:00Z"},   // 7 is Sunday
+		{"0 0 13 * fri", "2024-03-01T01:00:00Z", "2024-03-08T00:00:00Z"}, // either day field
+		{"0 0 1-7 * *", "2024-03-07T01:00:00Z", "2024-04-01T00:00:00Z"},
+		{"10-50/20 * * * *", "2024-03-01T10:31:00Z", "2024-03-01T10:50:00Z"},
+		{"0 0 1 jan,jul *", "2024-02-01T00:00:00Z", "2024-07-01T00:00:00Z"},
+		{"@daily", "2024-12-31T23:59:59Z", "2025-01-01T00:00:00Z"},
+		{"@hourly", "2024-03-01T10:00:00Z", "2024-03-01T11:00:00Z"},
+		{"@weekly", "2024-03-01T10:00:00Z", "2024-03-03T00:00:00Z"},
+		{"@yearly", "2024-03-01T10:00:00Z", "2025-01-01T00:00:00Z"},
+		{"@every 90s", "2024-03-01T10:00:00Z", "2024-03-01T10:01:30Z"},
+		{"CRON_TZ=Asia/Kolkata 0 9 * * *", "2024-03-01T00:00:00Z", "2024-03-01T03:30:00Z"},
+		{"0 0 30 2 *", "2024-03-01T00:00:00Z", "0001-01-01T00:00:00Z"}, // never
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s from %s", tt.spec, tt.from), func(t *testing.T) {
+			s, err := ParseIn(tt.spec, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, _ := time.Parse(time.RFC3339, tt.from)
+			want, _ := time.Parse(time.RFC3339, tt.want)
+			if got := s.Next(from); !got.Equal(want) {
+				t.Errorf("got %v, want %v", got.UTC(), want)
+			}
+		})
+	}
+}
+
+// TestNextDST covers the two days a year New York's wall clock isn't
+// continuous. Times are written in UTC: EST is -5, EDT -4.
+func TestNextDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	var tests = []struct {
+		name string
+		spec string
+		from string
+		want []string
+	}{
+		// 2024-03-10 02:00 EST jumps to 03:00 EDT.
+		{"daily job in the gap is skipped", "30 2 * * *", "2024-03-09T12:00:00Z",
+			[]string{"2024-03-11T06:30:00Z", "2024-03-12T06:30:00Z"}},
+		{"daily job after the gap", "0 9 * * *", "2024-03-09T15:00:00Z",
+			[]string{"2024-03-10T13:00:00Z", "2024-03-11T13:00:00Z"}},
+		{"hourly job over the gap", "0 * * * *", "2024-03-10T05:30:00Z",
+			[]string{"2024-03-10T06:00:00Z", "2024-03-10T07:00:00Z", "2024-03-10T08:00:00Z"}},
+		// 2024-11-03 02:00 EDT falls back to 01:00 EST.
+		{"daily job in the repeated hour runs once", "30 1 * * *", "2024-11-02T12:00:00Z",
+			[]string{"2024-11-03T05:30:00Z", "2024-11-04T06:30:00Z"}},
+		{"hourly job runs in both copies", "30 * * * *", "2024-11-03T04:45:00Z",
+			[]string{"2024-11-03T05:30:00Z", "2024-11-03T06:30:00Z", "2024-11-03T07:30:00Z"}},
+		{"minutely job doesn't repeat the hour", "*/30 1 * * *", "2024-11-03T05:10:00Z",
+			[]string{"2024-11-03T05:30:00Z", "2024-11-04T06:00:00Z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseIn(tt.spec, ny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			at, _ := time.Parse(time.RFC3339, tt.from)
+			for _, w := range tt.want {
+				want, _ := time.Parse(time.RFC3339, w)
+				at = s.Next(at)
+				if !at.Equal(want) {
+					t.Fatalf("got %v, want %v", at.UTC(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@fortnightly",
+		"@every",
+		"@every -1s",
+		"@every soon",
+		"CRON_TZ=Mars/Olympus * * * * *",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	s, err := ParseIn("TZ=Europe/Paris 0 8 * * *", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.(*SpecSchedule).Location.String(); got != "Europe/Paris" {
+		t.Errorf("got %v, want %v", got, "Europe/Paris")
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{"* * * * *", "*/5 1-3 * jan mon", "@daily", "0 0 29 2 *", "CRON_TZ=UTC 1 2 3 4 5 6"} {
+		f.Add(s)
+	}
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, spec string) {
+		s, err := ParseIn(spec, time.UTC)
+		if err != nil {
+			return
+		}
+		if next := s.Next(from); !next.IsZero() && !next.After(from) {
+			t.Errorf("%q: Next(%v) = %v, not after", spec, from, next)
+		}
+	})
+}
+
+func BenchmarkNext(b *testing.B) {
+	s, err := ParseIn("0 9 * * mon-fri", time.UTC)
+	if err != nil {
+		b.Fatal(err)
+	}
+	t := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < b.N; i++ {
+		t = s.Next(t)
+	}
+}
@@ -0,0 +1,49 @@
+// Package enums holds the ServerState enum from Enums.go, so its transition
+// rules can be imported and tested.
+package enums
+
+import "fmt"
+
+// ServerState is the state of a server connection.
+type ServerState int
+
+// The possible values for ServerState. Unlike in Enums.go the constants are
+// typed, so a plain int can't be passed where a ServerState is expected.
+const (
+	StateIdle ServerState = iota
+	StateConnected
+	StateError
+	StateRetrying
+)
+
+var stateName = map[ServerState]string{
+	StateIdle:      "idle",
+	StateConnected: "connected",
+	StateError:     "error",
+	StateRetrying:  "retrying",
+}
+
+// String returns the state's name, or "ServerState(N)" for a value outside
+// the enum, as the stringer tool would.
+func (ss ServerState) String() string {
+	if name, ok := stateName[ss]; ok {
+		return name
+	}
+	return fmt.Sprintf("ServerState(%d)", int(ss))
+}
+
+// Transition returns the state a server moves to from s. It panics on a value
+// outside the enum.
+func Transition(s ServerState) ServerState {
+	switch s {
+	case StateIdle:
+		return StateConnected
+	case StateConnected, StateRetrying:
+		// Suppose we check some predicate here to determine the next state...
+		return StateIdle
+	case StateError:
+		return StateError
+	default:
+		panic(fmt.Errorf("unknown state: %s", s))
+	}
+}
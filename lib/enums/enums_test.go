@@ -0,0 +1,96 @@
+package enums
+
+import (
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	var tests = []struct {
+		from, want ServerState
+	}{
+		{StateIdle, StateConnected},
+		{StateConnected, StateIdle},
+		{StateRetrying, StateIdle},
+		{StateError, StateError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String(), func(t *testing.T) {
+			ans := Transition(tt.from)
+			if ans != tt.want {
+				t.Errorf("got %s, want %s", ans, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransitionUnknownPanics(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Transition(ServerState(42)) did not panic")
+		}
+		if err, ok := r.(error); !ok || err.Error() != "unknown state: ServerState(42)" {
+			t.Errorf("panicked with %v", r)
+		}
+	}()
+	Transition(ServerState(42))
+}
+
+func TestString(t *testing.T) {
+	var tests = []struct {
+		s    ServerState
+		want string
+	}{
+		{StateIdle, "idle"},
+		{StateConnected, "connected"},
+		{StateError, "error"},
+		{StateRetrying, "retrying"},
+		{ServerState(-1), "ServerState(-1)"},
+		{ServerState(4), "ServerState(4)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if ans := tt.s.String(); ans != tt.want {
+				t.Errorf("got %q, want %q", ans, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzTransition checks that every state in the enum moves to another state
+// in the enum and that everything else panics.
+func FuzzTransition(f *testing.F) {
+	for s := StateIdle; s <= StateRetrying; s++ {
+		f.Add(int(s))
+	}
+	f.Add(-1)
+	f.Add(100)
+	f.Fuzz(func(t *testing.T, n int) {
+		s := ServerState(n)
+		_, known := stateName[s]
+		defer func() {
+			if r := recover(); (r != nil) == known {
+				t.Errorf("Transition(%d): known=%v, panic=%v", n, known, r)
+			}
+		}()
+		next := Transition(s)
+		if _, ok := stateName[next]; !ok {
+			t.Errorf("Transition(%s) = %d, outside the enum", s, int(next))
+		}
+	})
+}
+
+func BenchmarkTransition(b *testing.B) {
+	s := StateIdle
+	for i := 0; i < b.N; i++ {
+		s = Transition(s)
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = StateRetrying.String()
+	}
+}
@@ -0,0 +1,365 @@
+// Package examples builds and runs the single-file example programs in the
+// repository root.
+//
+// Every example is a `package main` of its own, so the root can't be built as
+// one package: worker, check, f, hello and rect are each declared in more
+// than one file. Instead each example is copied into a throwaway module of
+// its own, together with any files it embeds, and built or run there.
+package examples
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kind says how an example is meant to be run.
+type Kind int
+
+const (
+	// Program has a main func and is built and executed.
+	Program Kind = iota
+	// Test has no main, only Test and Benchmark funcs, and is run with go test.
+	Test
+	// Broken has neither, so there is nothing to run.
+	Broken
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Program:
+		return "program"
+	case Test:
+		return "test"
+	}
+	return "broken"
+}
+
+// Example is one example file.
+type Example struct {
+	Name   string // file name without .go, e.g. "WorkerPool"
+	File   string // e.g. "WorkerPool.go"
+	Kind   Kind
+	Embeds []string // //go:embed patterns, relative to the example's directory
+}
+
+// DefaultGoVersion goes in the go.mod of every temp module.
+const DefaultGoVersion = "1.22"
+
+// FindRoot walks up from dir to the first directory holding Learning.md, which
+// is the repository root where the examples live.
+func FindRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "Learning.md")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("examples: no Learning.md found in any parent directory")
+		}
+		dir = parent
+	}
+}
+
+// List returns the examples in dir, sorted by name.
+func List(dir string) ([]Example, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var list []Example
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		ex, err := inspect(path)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ex)
+	}
+	return list, nil
+}
+
+// Find returns the example called name in dir. The ".go" suffix is optional
+// and the match ignores case, so "workerpool" finds WorkerPool.go.
+func Find(dir, name string) (Example, error) {
+	list, err := List(dir)
+	if err != nil {
+		return Example{}, err
+	}
+	name = strings.TrimSuffix(name, ".go")
+	for _, ex := range list {
+		if strings.EqualFold(ex.Name, name) {
+			return ex, nil
+		}
+	}
+	return Example{}, fmt.Errorf("examples: no example named %q in %s", name, dir)
+}
+
+// inspect parses a file to find out what kind of example it is and which
+// files it embeds.
+func inspect(path string) (Example, error) {
+	file := filepath.Base(path)
+	ex := Example{Name: strings.TrimSuffix(file, ".go"), File: file, Kind: Broken}
+
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		// Still listed, so the user can see it fail to build.
+		return ex, nil
+	}
+	hasMain, hasTests := false, false
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+		switch name := fn.Name.Name; {
+		case name == "main":
+			hasMain = true
+		case strings.HasPrefix(name, "Test"), strings.HasPrefix(name, "Benchmark"):
+			hasTests = true
+		}
+	}
+	switch {
+	case f.Name.Name == "main" && hasMain:
+		ex.Kind = Program
+	case hasTests:
+		ex.Kind = Test
+	}
+
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if rest, ok := strings.CutPrefix(c.Text, "//go:embed "); ok {
+				ex.Embeds = append(ex.Embeds, strings.Fields(rest)...)
+			}
+		}
+	}
+	return ex, nil
+}
+
+// Module is an example copied into a temporary module.
+type Module struct {
+	Dir     string
+	Example Example
+}
+
+// Remove deletes the module's directory.
+func (m *Module) Remove() error {
+	return os.RemoveAll(m.Dir)
+}
+
+// NewModule copies ex, and the files it embeds, from srcDir into a fresh temp
+// module. Test examples are copied as a _test.go file so go test finds them.
+func NewModule(srcDir string, ex Example, goVersion string) (*Module, error) {
+	if goVersion == "" {
+		goVersion = DefaultGoVersion
+	}
+	dir, err := os.MkdirTemp("", "example-"+strings.ToLower(ex.Name)+"-")
+	if err != nil {
+		return nil, err
+	}
+	m := &Module{Dir: dir, Example: ex}
+
+	gomod := fmt.Sprintf("module example/%s\n\ngo %s\n", strings.ToLower(ex.Name), goVersion)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644); err != nil {
+		m.Remove()
+		return nil, err
+	}
+	target := ex.File
+	if ex.Kind == Test {
+		target = ex.Name + "_test.go"
+	}
+	if err := copyFile(filepath.Join(srcDir, ex.File), filepath.Join(dir, target)); err != nil {
+		m.Remove()
+		return nil, err
+	}
+	for _, pattern := range ex.Embeds {
+		if err := copyEmbed(srcDir, dir, pattern); err != nil {
+			m.Remove()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// copyEmbed copies whatever an embed pattern names: single files, globs and
+// whole directories.
+func copyEmbed(srcDir, dstDir, pattern string) error {
+	matches, err := filepath.Glob(filepath.Join(srcDir, filepath.FromSlash(pattern)))
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("examples: embed pattern %q matches no files", pattern)
+	}
+	for _, match := range matches {
+		err := filepath.WalkDir(match, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(srcDir, path)
+			if err != nil {
+				return err
+			}
+			return copyFile(path, filepath.Join(dstDir, rel))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, b, 0644)
+}
+
+// BuildError carries the compiler output of a failed build.
+type BuildError struct {
+	Example string
+	Output  string
+}
+
+func (e *BuildError) Error() string {
+	return fmt.Sprintf("examples: build %s failed:\n%s", e.Example, e.Output)
+}
+
+// Build compiles the module's program to out (or to "main" inside the module
+// when out is empty) and returns the binary's path.
+func (m *Module) Build(ctx context.Context, out string) (string, error) {
+	if m.Example.Kind != Program {
+		return "", fmt.Errorf("examples: %s is a %s example, not a program", m.Example.Name, m.Example.Kind)
+	}
+	if out == "" {
+		out = filepath.Join(m.Dir, "main")
+	}
+	out, err := filepath.Abs(out)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", out, ".")
+	cmd.Dir = m.Dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", &BuildError{Example: m.Example.Name, Output: strings.TrimSpace(string(output))}
+	}
+	return out, nil
+}
+
+// Options control a run.
+type Options struct {
+	// Timeout bounds the run itself, not the build. Zero means no limit.
+	Timeout time.Duration
+	// Args are passed to the program, or to go test for Test examples.
+	Args  []string
+	Stdin io.Reader
+	// GoVersion goes in the temp module's go.mod.
+	GoVersion string
+}
+
+// Result is what a run produced.
+type Result struct {
+	Example  Example
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Duration time.Duration
+	TimedOut bool
+}
+
+// Run builds ex from srcDir in a temp module, runs it and captures its output.
+// A program that exits non-zero is not an error; look at ExitCode. Servers and
+// other programs that never finish on their own end with TimedOut set.
+func Run(ctx context.Context, srcDir string, ex Example, opts Options) (*Result, error) {
+	m, err := NewModule(srcDir, ex, opts.GoVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Remove()
+
+	var cmd *exec.Cmd
+	runCtx, cancel := ctx, context.CancelFunc(func() {})
+	switch ex.Kind {
+	case Program:
+		bin, err := m.Build(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		if opts.Timeout > 0 {
+			runCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		}
+		cmd = exec.CommandContext(runCtx, bin, opts.Args...)
+	case Test:
+		if opts.Timeout > 0 {
+			runCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		}
+		// vet is off because the examples are teaching material, not lint-clean code.
+		args := append([]string{"test", "-vet=off", "-v", "-bench", ".", "-benchtime", "100ms"}, opts.Args...)
+		cmd = exec.CommandContext(runCtx, "go", args...)
+	default:
+		return nil, fmt.Errorf("examples: %s has no main or test funcs to run", ex.File)
+	}
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd.Dir = m.Dir
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait forever on pipes held open by a child the program spawned.
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+	res := &Result{
+		Example:  ex,
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		Duration: time.Since(start),
+		TimedOut: runCtx.Err() == context.DeadlineExceeded,
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	case res.TimedOut, errors.Is(err, exec.ErrWaitDelay):
+		res.ExitCode = -1
+	default:
+		return nil, err
+	}
+	return res, nil
+}
+
+// FirstLine returns the first line of b, handy for one-line summaries.
+func FirstLine(b []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	if sc.Scan() {
+		return sc.Text()
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package examples
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixture writes a tiny repository root: Learning.md and a few examples.
+func fixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"Learning.md": "# Notes\n",
+		"Hello.go":    "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hello\") }\n",
+		"Echo.go": `package main
+
+import (
+	"io"
+	"os"
+)
+
+func main() { io.Copy(os.Stdout, os.Stdin) }
+`,
+		"Forever.go": "package main\n\nimport \"time\"\n\nfunc main() { time.Sleep(time.Hour) }\n",
+		"Adder.go": `package adder
+
+import "testing"
+
+func TestAdd(t *testing.T) {}
+`,
+		"Notes.go":      "package notes\n",
+		"Hello_test.go": "package main\n",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestList(t *testing.T) {
+	dir := fixture(t)
+	list, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ex := range list {
+		got = append(got, ex.Name+":"+ex.Kind.String())
+	}
+	want := "Adder:test Echo:program Forever:program Hello:program Notes:broken"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := fixture(t)
+	var tests = []struct {
+		name string
+		want string // "" for a miss
+	}{
+		{"Hello", "Hello.go"},
+		{"hello.go", "Hello.go"},
+		{"ECHO", "Echo.go"},
+		{"Missing", ""},
+		{"Hello_test", ""},
+	}
+	for _, tt := range tests {
+		ex, err := Find(dir, tt.name)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: got %s, want an error", tt.name, ex.File)
+			}
+			continue
+		}
+		if err != nil || ex.File != tt.want {
+			t.Errorf("%s: got %s %v, want %s", tt.name, ex.File, err, tt.want)
+		}
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	dir := fixture(t)
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := FindRoot(sub); err != nil || got != dir {
+		t.Errorf("got %s %v, want %s", got, err, dir)
+	}
+}
+
+// run finds name in the fixture and runs it.
+func run(t *testing.T, name string, opts Options) *Result {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds with the go tool")
+	}
+	dir := fixture(t)
+	ex, err := Find(dir, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(context.Background(), dir, ex, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestRunStdin(t *testing.T) {
+	res := run(t, "Echo", Options{Stdin: strings.NewReader("piped\n")})
+	if string(res.Stdout) != "piped\n" || res.ExitCode != 0 || res.TimedOut {
+		t.Errorf("got %q, exit %d, timed out %v", res.Stdout, res.ExitCode, res.TimedOut)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	res := run(t, "Forever", Options{Timeout: 200 * time.Millisecond})
+	if !res.TimedOut || res.ExitCode != -1 {
+		t.Errorf("got exit %d, timed out %v; want -1, true", res.ExitCode, res.TimedOut)
+	}
+	if res.Duration > 10*time.Second {
+		t.Errorf("took %v to kill", res.Duration)
+	}
+}
+
+func TestRunBroken(t *testing.T) {
+	dir := fixture(t)
+	ex, err := Find(dir, "Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Run(context.Background(), dir, ex, Options{}); err == nil {
+		t.Error("ran an example with no main or tests")
+	}
+}
@@ -0,0 +1,137 @@
+package generics
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestMapKeys(t *testing.T) {
+	var tests = []struct {
+		m    map[int]string
+		want []int
+	}{
+		{nil, []int{}},
+		{map[int]string{}, []int{}},
+		{map[int]string{1: "2"}, []int{1}},
+		{map[int]string{1: "2", 2: "4", 4: "8"}, []int{1, 2, 4}},
+	}
+
+	for _, tt := range tests {
+		testname := fmt.Sprintf("%v", tt.m)
+		t.Run(testname, func(t *testing.T) {
+			// Map order is random, so compare the keys sorted.
+			ans := MapKeys(tt.m)
+			slices.Sort(ans)
+			if !slices.Equal(ans, tt.want) {
+				t.Errorf("got %v, want %v", ans, tt.want)
+			}
+		})
+	}
+}
+
+func TestListPushGetAll(t *testing.T) {
+	var tests = []struct {
+		push []int
+		want []int
+	}{
+		{nil, nil},
+		{[]int{10}, []int{10}},
+		{[]int{10, 13, 23}, []int{10, 13, 23}},
+	}
+
+	for _, tt := range tests {
+		testname := fmt.Sprintf("%v", tt.push)
+		t.Run(testname, func(t *testing.T) {
+			var lst List[int]
+			for _, v := range tt.push {
+				lst.Push(v)
+			}
+			if ans := lst.GetAll(); !slices.Equal(ans, tt.want) {
+				t.Errorf("got %v, want %v", ans, tt.want)
+			}
+		})
+	}
+}
+
+func TestListStrings(t *testing.T) {
+	var lst List[string]
+	lst.Push("a")
+	lst.Push("b")
+	if ans := lst.GetAll(); !slices.Equal(ans, []string{"a", "b"}) {
+		t.Errorf("got %q, want [a b]", ans)
+	}
+}
+
+// FuzzList checks that a list hands back exactly what was pushed, in order.
+func FuzzList(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("hello"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var lst List[byte]
+		for _, b := range data {
+			lst.Push(b)
+		}
+		ans := lst.GetAll()
+		if len(data) == 0 && ans == nil {
+			return
+		}
+		if !slices.Equal(ans, data) {
+			t.Errorf("got %v, want %v", ans, data)
+		}
+	})
+}
+
+// FuzzMapKeys checks that MapKeys returns every key exactly once.
+func FuzzMapKeys(f *testing.F) {
+	f.Add("")
+	f.Add("abracadabra")
+	f.Fuzz(func(t *testing.T, s string) {
+		m := make(map[rune]int)
+		for i, r := range s {
+			m[r] = i
+		}
+		keys := MapKeys(m)
+		if len(keys) != len(m) {
+			t.Fatalf("got %d keys, want %d", len(keys), len(m))
+		}
+		seen := make(map[rune]bool)
+		for _, k := range keys {
+			if _, ok := m[k]; !ok || seen[k] {
+				t.Errorf("unexpected or repeated key %q", k)
+			}
+			seen[k] = true
+		}
+	})
+}
+
+func BenchmarkListPush(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var lst List[int]
+		for j := 0; j < 100; j++ {
+			lst.Push(j)
+		}
+	}
+}
+
+func BenchmarkListGetAll(b *testing.B) {
+	var lst List[int]
+	for j := 0; j < 100; j++ {
+		lst.Push(j)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lst.GetAll()
+	}
+}
+
+func BenchmarkMapKeys(b *testing.B) {
+	m := make(map[int]string, 100)
+	for j := 0; j < 100; j++ {
+		m[j] = "v"
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MapKeys(m)
+	}
+}
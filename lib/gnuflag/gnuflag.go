@@ -0,0 +1,351 @@
+// Package gnuflag parses command lines GNU style, on top of the standard
+// flag package.
+//
+// CmdFlags.go notes that the flag package stops at the first positional
+// argument, so in "-word=opt a1 a2 a3 -numb=7" the -numb=7 is silently one
+// more positional argument. A FlagSet here wraps a flag.FlagSet, so flags are
+// still defined with its String, Int, Bool, Duration and Var methods, but
+// Parse accepts:
+//
+//   - flags anywhere among the positional arguments
+//   - --name and --name=value, or --name value for flags that aren't bools
+//   - single-letter flags as -v, and letters added with Short, combined as in
+//     -abc; a letter that takes a value takes the rest of the word or the
+//     next one, so -n7, -n=7 and -n 7 are the same; a bool letter takes a
+//     value only after "=", as in -v=false
+//   - -name and -name=value, as the flag package does, when name is a flag
+//     longer than one letter
+//   - "--" to end the flags; a lone "-" is a positional argument
+//
+// StringSlice, IP, URL and Enum add repeatable and checked flag types to
+// go with the standard ones. After Parse, the wrapped set's Args, NArg,
+// Visit and Parsed work as they would after its own Parse.
+package gnuflag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// FlagSet is a flag.FlagSet with GNU-style parsing and short names.
+type FlagSet struct {
+	*flag.FlagSet
+	shorts map[rune]string // short letter to flag name
+}
+
+// New returns a FlagSet wrapping a new flag.FlagSet.
+func New(name string, errorHandling flag.ErrorHandling) *FlagSet {
+	return Wrap(flag.NewFlagSet(name, errorHandling))
+}
+
+// Wrap returns a FlagSet that parses fs's flags GNU style. Flags defined
+// on either one are flags of both.
+func Wrap(fs *flag.FlagSet) *FlagSet {
+	return &FlagSet{FlagSet: fs, shorts: make(map[rune]string)}
+}
+
+// CommandLine wraps flag.CommandLine.
+var CommandLine = Wrap(flag.CommandLine)
+
+// Parse parses os.Args[1:] into the flags of flag.CommandLine. It is the
+// drop-in for flag.Parse.
+func Parse() {
+	// CommandLine exits on error.
+	CommandLine.Parse(os.Args[1:])
+}
+
+// Short makes -short another name for the flag called name, which must
+// already be defined.
+func (f *FlagSet) Short(short rune, name string) {
+	if f.Lookup(name) == nil {
+		panic(fmt.Sprintf("gnuflag: Short(%q, %q): no flag %q", short, name, name))
+	}
+	if other, ok := f.shorts[short]; ok {
+		panic(fmt.Sprintf("gnuflag: -%c is already short for --%s", short, other))
+	}
+	if f.Lookup(string(short)) != nil {
+		panic(fmt.Sprintf("gnuflag: -%c is already a flag", short))
+	}
+	f.shorts[short] = name
+}
+
+// ShortFor returns the short name of the flag called name, if it has one.
+func (f *FlagSet) ShortFor(name string) (rune, bool) {
+	for s, n := range f.shorts {
+		if n == name {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// Parse parses args, which shouldn't include the program name. It handles
+// errors as the wrapped set's ErrorHandling says: printing the error and
+// usage, then returning it, exiting or panicking. -h and --help, unless
+// defined, print the usage and return flag.ErrHelp.
+func (f *FlagSet) Parse(args []string) error {
+	pos, err := f.parse(args)
+	if err == nil {
+		// Leave the positional arguments with the wrapped set, for Args and
+		// NArg. After "--" it takes the rest as they are.
+		return f.FlagSet.Parse(append([]string{"--"}, pos...))
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		f.usage()
+	} else {
+		fmt.Fprintln(f.Output(), err)
+		f.usage()
+	}
+	switch f.ErrorHandling() {
+	case flag.ExitOnError:
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+func (f *FlagSet) parse(args []string) ([]string, error) {
+	var pos []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		var err error
+		switch {
+		case a == "--":
+			return append(pos, args[i+1:]...), nil
+		case len(a) < 2 || a[0] != '-':
+			pos = append(pos, a)
+		case a[1] == '-':
+			i, err = f.parseLong(a, a[2:], args, i)
+		default:
+			i, err = f.parseShorts(a, args, i)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pos, nil
+}
+
+// parseLong parses the flag in args[i], written as arg with the dashes
+// removed, and returns the index of the last argument it used.
+func (f *FlagSet) parseLong(written, arg string, args []string, i int) (int, error) {
+	name, value, hasValue := strings.Cut(arg, "=")
+	fl := f.Lookup(name)
+	if fl == nil {
+		if name == "help" || name == "h" {
+			return i, flag.ErrHelp
+		}
+		return i, fmt.Errorf("flag provided but not defined: %s", written)
+	}
+	written, _, _ = strings.Cut(written, "=")
+	switch {
+	case hasValue:
+	case isBool(fl):
+		value = "true"
+	case i+1 < len(args):
+		i++
+		value = args[i]
+	default:
+		return i, fmt.Errorf("flag needs an argument: %s", written)
+	}
+	return i, f.set(fl, written, value)
+}
+
+// parseShorts parses a word that starts with one dash: a long flag written
+// the flag package's way, or one or more short ones.
+func (f *FlagSet) parseShorts(a string, args []string, i int) (int, error) {
+	name, _, _ := strings.Cut(a[1:], "=")
+	if utf8.RuneCountInString(name) > 1 && f.Lookup(name) != nil || name == "help" {
+		return f.parseLong(a, a[1:], args, i)
+	}
+	for j, c := range a[1:] {
+		fl := f.LookupShort(c)
+		if fl == nil {
+			if c == 'h' {
+				return i, flag.ErrHelp
+			}
+			if j == 0 && len(name) > 1 {
+				return i, fmt.Errorf("flag provided but not defined: %s", a)
+			}
+			return i, fmt.Errorf("flag provided but not defined: -%c (in %s)", c, a)
+		}
+		written := "-" + string(c)
+		rest := a[1+j+utf8.RuneLen(c):]
+		if isBool(fl) {
+			if value, ok := strings.CutPrefix(rest, "="); ok {
+				return i, f.set(fl, written, value)
+			}
+			if err := f.set(fl, written, "true"); err != nil {
+				return i, err
+			}
+			continue
+		}
+		// "-n=" sets an empty value; only a bare "-n" takes the next argument.
+		value, hasEq := strings.CutPrefix(rest, "=")
+		if !hasEq && value == "" {
+			if i+1 >= len(args) {
+				return i, fmt.Errorf("flag needs an argument: %s", written)
+			}
+			i++
+			value = args[i]
+		}
+		return i, f.set(fl, written, value)
+	}
+	return i, nil
+}
+
+// TakesValue reports whether Parse would take the argument after arg as a
+// flag's value: arg is --name or -name for a flag that isn't a bool, or
+// short letters ending in one that needs a value and wasn't given it, as in
+// "-vn 7". Programs that look for subcommand names among the arguments use
+// it to step over flag values.
+func (f *FlagSet) TakesValue(arg string) bool {
+	switch {
+	case len(arg) < 2 || arg[0] != '-' || arg == "--":
+		return false
+	case arg[1] == '-':
+		fl := f.Lookup(arg[2:])
+		return fl != nil && !isBool(fl)
+	}
+	if utf8.RuneCountInString(arg) > 2 {
+		if fl := f.Lookup(arg[1:]); fl != nil {
+			return !isBool(fl)
+		}
+	}
+	for j, c := range arg[1:] {
+		fl := f.LookupShort(c)
+		if fl == nil {
+			return false
+		}
+		rest := arg[1+j+utf8.RuneLen(c):]
+		if isBool(fl) {
+			if strings.HasPrefix(rest, "=") {
+				return false
+			}
+			continue
+		}
+		return rest == ""
+	}
+	return false
+}
+
+// LookupShort returns the flag -c names: the one Short gave the letter to,
+// or else the flag called c.
+func (f *FlagSet) LookupShort(c rune) *flag.Flag {
+	if name, ok := f.shorts[c]; ok {
+		return f.Lookup(name)
+	}
+	return f.Lookup(string(c))
+}
+
+func (f *FlagSet) set(fl *flag.Flag, written, value string) error {
+	// Set through the wrapped set so Visit sees the flag as set.
+	if err := f.FlagSet.Set(fl.Name, value); err != nil {
+		return fmt.Errorf("invalid value %q for flag %s: %v", value, written, err)
+	}
+	return nil
+}
+
+func isBool(fl *flag.Flag) bool {
+	b, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func (f *FlagSet) usage() {
+	if f.Usage != nil {
+		f.Usage()
+		return
+	}
+	if f.Name() == "" {
+		fmt.Fprintf(f.Output(), "Usage:\n")
+	} else {
+		fmt.Fprintf(f.Output(), "Usage of %s:\n", f.Name())
+	}
+	f.PrintDefaults()
+}
+
+// PrintDefaults prints the flags as the flag package does, but named the
+// way Parse reads them: "-n, --numb int" for a flag with a short name,
+// "--word string" for a long one.
+func (f *FlagSet) PrintDefaults() {
+	var zeroErrs []error
+	f.VisitAll(func(fl *flag.Flag) {
+		var b strings.Builder
+		b.WriteString("  -")
+		if utf8.RuneCountInString(fl.Name) > 1 {
+			if s, ok := f.ShortFor(fl.Name); ok {
+				fmt.Fprintf(&b, "%c, -", s)
+			}
+			b.WriteString("-")
+		}
+		b.WriteString(fl.Name)
+		typ, usage := flag.UnquoteUsage(fl)
+		if typ != "" {
+			b.WriteString(" " + typ)
+		}
+		// A single-letter bool fits before the usage, as in the flag package.
+		if b.Len() <= 4 {
+			b.WriteString("\t")
+		} else {
+			b.WriteString("\n    \t")
+		}
+		b.WriteString(strings.ReplaceAll(usage, "\n", "\n    \t"))
+		zero, err := isZeroValue(fl)
+		if err != nil {
+			zeroErrs = append(zeroErrs, err)
+		}
+		switch {
+		case zero:
+		case isString(fl):
+			fmt.Fprintf(&b, " (default %q)", fl.DefValue)
+		default:
+			fmt.Fprintf(&b, " (default %v)", fl.DefValue)
+		}
+		fmt.Fprintln(f.Output(), b.String())
+	})
+	// As in the flag package, these come last so they don't break up the list.
+	if len(zeroErrs) > 0 {
+		fmt.Fprintln(f.Output())
+		for _, err := range zeroErrs {
+			fmt.Fprintln(f.Output(), err)
+		}
+	}
+}
+
+func isString(fl *flag.Flag) bool {
+	g, ok := fl.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	_, ok = g.Get().(string)
+	return ok
+}
+
+// isZeroValue reports whether a flag's default is its type's zero value,
+// which PrintDefaults leaves out. A String method that panics on the zero
+// value is reported as an error and the default treated as non-zero.
+func isZeroValue(fl *flag.Flag) (ok bool, err error) {
+	typ := reflect.TypeOf(fl.Value)
+	var z reflect.Value
+	if typ.Kind() == reflect.Pointer {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			ok = false
+			err = fmt.Errorf("panic calling String method on zero %v for flag %s: %v", typ, fl.Name, e)
+		}
+	}()
+	return fl.DefValue == z.Interface().(flag.Value).String(), nil
+}
@@ -0,0 +1,318 @@
+package gnuflag
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flags defines the flags of CmdFlags.go, plus a few to exercise the rest.
+type flags struct {
+	fs      *FlagSet
+	word    *string
+	numb    *int
+	fork    *bool
+	svar    string
+	all     *bool
+	long    *bool
+	output  *string
+	tags    *[]string
+	timeout *time.Duration
+	out     bytes.Buffer
+}
+
+func newFlags() *flags {
+	f := &flags{fs: New("test", flag.ContinueOnError)}
+	f.fs.SetOutput(&f.out)
+	f.word = f.fs.String("word", "foo", "a string")
+	f.numb = f.fs.Int("numb", 42, "an int")
+	f.fork = f.fs.Bool("fork", false, "a bool")
+	f.fs.StringVar(&f.svar, "svar", "bar", "a string var")
+	f.all = f.fs.Bool("a", false, "all")
+	f.long = f.fs.Bool("l", false, "long listing")
+	f.output = f.fs.String("output", "", "output `file`")
+	f.fs.Short('o', "output")
+	f.fs.Short('n', "numb")
+	f.tags = f.fs.StringSlice("tag", []string{"default"}, "a tag, repeatable")
+	f.fs.Short('t', "tag")
+	f.timeout = f.fs.Duration("timeout", time.Second, "timeout")
+	return f
+}
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		args  []string
+		check func(f *flags) bool
+		rest  []string
+	}{
+		// CmdFlags.go's runs.
+		{[]string{"-word=opt", "-numb=7", "-fork", "-svar=flag"},
+			func(f *flags) bool { return *f.word == "opt" && *f.numb == 7 && *f.fork && f.svar == "flag" }, nil},
+		{[]string{"-word=opt", "a1", "a2", "a3"},
+			func(f *flags) bool { return *f.word == "opt" && *f.numb == 42 }, []string{"a1", "a2", "a3"}},
+		{[]string{"-word=opt", "a1", "a2", "a3", "-numb=7"},
+			func(f *flags) bool { return *f.numb == 7 }, []string{"a1", "a2", "a3"}},
+
+		{[]string{"--word", "w", "--numb=3", "x", "--fork"},
+			func(f *flags) bool { return *f.word == "w" && *f.numb == 3 && *f.fork }, []string{"x"}},
+		{[]string{"--fork=false"}, func(f *flags) bool { return !*f.fork }, nil},
+		{[]string{"-al", "x"}, func(f *flags) bool { return *f.all && *f.long }, []string{"x"}},
+		{[]string{"-alofile", "x"}, func(f *flags) bool { return *f.all && *f.long && *f.output == "file" }, []string{"x"}},
+		{[]string{"-lo", "file"}, func(f *flags) bool { return *f.long && *f.output == "file" }, nil},
+		{[]string{"-n7"}, func(f *flags) bool { return *f.numb == 7 }, nil},
+		{[]string{"-n=7"}, func(f *flags) bool { return *f.numb == 7 }, nil},
+		{[]string{"-n", "-7"}, func(f *flags) bool { return *f.numb == -7 }, nil},
+		{[]string{"-o=", "x"}, func(f *flags) bool { return *f.output == "" }, []string{"x"}},
+		{[]string{"--word=", "x"}, func(f *flags) bool { return *f.word == "" }, []string{"x"}},
+		{[]string{"-a", "-a=false"}, func(f *flags) bool { return !*f.all }, nil},
+		{[]string{"-l=true", "x"}, func(f *flags) bool { return *f.long && !*f.all }, []string{"x"}},
+		{[]string{"-al=false"}, func(f *flags) bool { return *f.all && !*f.long }, nil},
+		{[]string{"-a", "--", "-l", "--numb=1"},
+			func(f *flags) bool { return *f.all && !*f.long && *f.numb == 42 }, []string{"-l", "--numb=1"}},
+		{[]string{"-", "-a"}, func(f *flags) bool { return *f.all }, []string{"-"}},
+		{nil, func(f *flags) bool { return slices.Equal(*f.tags, []string{"default"}) }, nil},
+		{[]string{"-t", "x", "--tag=y", "z", "-tw"},
+			func(f *flags) bool { return slices.Equal(*f.tags, []string{"x", "y", "w"}) }, []string{"z"}},
+		{[]string{"x", "--timeout", "90s"}, func(f *flags) bool { return *f.timeout == 90*time.Second }, []string{"x"}},
+	}
+	for _, tt := range tests {
+		f := newFlags()
+		if err := f.fs.Parse(tt.args); err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		if !tt.check(f) {
+			t.Errorf("%q: flags not set as expected", tt.args)
+		}
+		if got := f.fs.Args(); !slices.Equal(got, tt.rest) && len(got)+len(tt.rest) > 0 {
+			t.Errorf("%q: Args() = %q, want %q", tt.args, got, tt.rest)
+		}
+		if !f.fs.Parsed() {
+			t.Errorf("%q: not Parsed", tt.args)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var tests = []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-wat"}, "flag provided but not defined: -wat"},
+		{[]string{"--wat"}, "flag provided but not defined: --wat"},
+		{[]string{"-ax"}, "flag provided but not defined: -x (in -ax)"},
+		{[]string{"--numb"}, "flag needs an argument: --numb"},
+		{[]string{"-al", "-o"}, "flag needs an argument: -o"},
+		{[]string{"--numb=x"}, `invalid value "x" for flag --numb: parse error`},
+		{[]string{"-n", "x"}, `invalid value "x" for flag -n: parse error`},
+		{[]string{"--fork=maybe"}, `invalid value "maybe" for flag --fork`},
+		{[]string{"-a=maybe"}, `invalid value "maybe" for flag -a`},
+	}
+	for _, tt := range tests {
+		f := newFlags()
+		err := f.fs.Parse(tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want %q", tt.args, err, tt.want)
+			continue
+		}
+		if out := f.out.String(); !strings.HasPrefix(out, err.Error()+"\nUsage of test:\n") {
+			t.Errorf("%q: printed %q", tt.args, out)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	for _, arg := range []string{"-h", "--help", "-help"} {
+		f := newFlags()
+		if err := f.fs.Parse([]string{"x", arg}); !errors.Is(err, flag.ErrHelp) {
+			t.Errorf("%s: got %v, want ErrHelp", arg, err)
+		}
+		if !strings.HasPrefix(f.out.String(), "Usage of test:\n") {
+			t.Errorf("%s: printed %q", arg, f.out.String())
+		}
+	}
+}
+
+func TestPrintDefaults(t *testing.T) {
+	f := newFlags()
+	f.fs.PrintDefaults()
+	want := `  -a	all
+  --fork
+    	a bool
+  -l	long listing
+  -n, --numb int
+    	an int (default 42)
+  -o, --output file
+    	output file
+  --svar string
+    	a string var (default "bar")
+  -t, --tag value
+    	a tag, repeatable (default default)
+  --timeout duration
+    	timeout (default 1s)
+  --word string
+    	a string (default "foo")
+`
+	if got := f.out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// intRef's String dereferences p, so its zero value panics.
+type intRef struct{ p *int }
+
+func (r intRef) String() string { return strconv.Itoa(*r.p) }
+
+func (r intRef) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	*r.p = n
+	return err
+}
+
+func TestPrintDefaultsZeroPanics(t *testing.T) {
+	var out bytes.Buffer
+	fs := New("test", flag.ContinueOnError)
+	fs.SetOutput(&out)
+	n := 3
+	fs.Var(intRef{&n}, "ref", "an int by reference")
+	fs.PrintDefaults()
+	want := `  --ref value
+    	an int by reference (default 3)
+
+panic calling String method on zero gnuflag.intRef for flag ref: runtime error: invalid memory address or nil pointer dereference
+`
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTakesValue(t *testing.T) {
+	f := newFlags()
+	var tests = []struct {
+		arg  string
+		want bool
+	}{
+		{"--numb", true},
+		{"--numb=7", false},
+		{"--fork", false},
+		{"-numb", true},
+		{"-word=x", false},
+		{"-n", true},
+		{"-n7", false},
+		{"-n=", false},
+		{"--word=", false},
+		{"-al", false},
+		{"-alo", true},
+		{"-alofile", false},
+		{"-a=true", false},
+		{"-ax", false},
+		{"--wat", false},
+		{"--", false},
+		{"-", false},
+		{"numb", false},
+	}
+	for _, tt := range tests {
+		if got := f.fs.TakesValue(tt.arg); got != tt.want {
+			t.Errorf("TakesValue(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestWrapExisting(t *testing.T) {
+	std := flag.NewFlagSet("std", flag.ContinueOnError)
+	n := std.Int("n", 0, "")
+	if err := Wrap(std).Parse([]string{"a", "-n", "3", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if *n != 3 || !slices.Equal(std.Args(), []string{"a", "b"}) {
+		t.Errorf("got n=%d args=%q", *n, std.Args())
+	}
+	var set []string
+	std.Visit(func(fl *flag.Flag) { set = append(set, fl.Name) })
+	if !slices.Equal(set, []string{"n"}) {
+		t.Errorf("Visit saw %q", set)
+	}
+}
+
+func TestShortPanics(t *testing.T) {
+	var tests = []struct {
+		short rune
+		name  string
+	}{
+		{'x', "nope"},
+		{'o', "word"}, // already short for output
+		{'a', "word"}, // already a flag
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Short(%q, %q): no panic", tt.short, tt.name)
+				}
+			}()
+			newFlags().fs.Short(tt.short, tt.name)
+		}()
+	}
+}
+
+func TestValues(t *testing.T) {
+	fs := New("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	ip := fs.IP("ip", net.IPv4(127, 0, 0, 1), "")
+	u := fs.URL("url", "http://localhost:8080", "")
+	level := fs.Enum("level", "info", []string{"debug", "info", "warn"}, "")
+
+	if ip.String() != "127.0.0.1" || u.Host != "localhost:8080" || *level != "info" {
+		t.Fatalf("defaults: %v %v %v", ip, u, *level)
+	}
+	if err := fs.Parse([]string{"--ip=::1", "--url", "https://example.com/x", "--level=debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if ip.String() != "::1" || u.String() != "https://example.com/x" || *level != "debug" {
+		t.Errorf("got %v %v %v", ip, u, *level)
+	}
+
+	var tests = []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--ip=1.2.3"}, "not an IP address"},
+		{[]string{"--url=/relative"}, "not an absolute URL"},
+		{[]string{"--url=http://%zz"}, "invalid URL escape"},
+		{[]string{"--level=trace"}, "must be one of debug, info, warn"},
+	}
+	for _, tt := range tests {
+		if err := fs.Parse(tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("-word=opt a1 -numb=7 -alo x -- -l")
+	f.Add("--tag a -t b --fork=false -n")
+	f.Fuzz(func(t *testing.T, line string) {
+		fl := newFlags()
+		args := strings.Fields(line)
+		if err := fl.fs.Parse(args); err != nil {
+			return
+		}
+		// Every positional argument came from args, in order.
+		rest := fl.fs.Args()
+		i := 0
+		for _, a := range args {
+			if i < len(rest) && a == rest[i] {
+				i++
+			}
+		}
+		if i != len(rest) {
+			t.Errorf("%q: Args() = %q", args, rest)
+		}
+	})
+}
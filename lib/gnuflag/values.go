@@ -0,0 +1,166 @@
+package gnuflag
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// stringSlice collects every value a repeatable flag is given. The first
+// one replaces the default.
+type stringSlice struct {
+	p   *[]string
+	set bool
+}
+
+func (s *stringSlice) Set(v string) error {
+	if !s.set {
+		*s.p, s.set = nil, true
+	}
+	*s.p = append(*s.p, v)
+	return nil
+}
+
+func (s *stringSlice) String() string {
+	if s == nil || s.p == nil {
+		return ""
+	}
+	return strings.Join(*s.p, ",")
+}
+
+func (s *stringSlice) Get() any { return *s.p }
+
+// StringSliceVar defines a flag that can be given more than once, each
+// value being appended to *p: "-t a -t b" gives []string{"a", "b"}.
+func (f *FlagSet) StringSliceVar(p *[]string, name string, value []string, usage string) {
+	*p = slices.Clone(value)
+	f.Var(&stringSlice{p: p}, name, usage)
+}
+
+// StringSlice is StringSliceVar with a new slice.
+func (f *FlagSet) StringSlice(name string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVar(p, name, value, usage)
+	return p
+}
+
+type ipValue struct{ p *net.IP }
+
+func (v ipValue) Set(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return fmt.Errorf("not an IP address")
+	}
+	*v.p = ip
+	return nil
+}
+
+func (v ipValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+func (v ipValue) Get() any { return *v.p }
+
+// IPVar defines a flag holding an IPv4 or IPv6 address.
+func (f *FlagSet) IPVar(p *net.IP, name string, value net.IP, usage string) {
+	*p = value
+	f.Var(ipValue{p}, name, usage)
+}
+
+// IP is IPVar with a new net.IP.
+func (f *FlagSet) IP(name string, value net.IP, usage string) *net.IP {
+	p := new(net.IP)
+	f.IPVar(p, name, value, usage)
+	return p
+}
+
+type urlValue struct{ p *url.URL }
+
+func (v urlValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" && u.Opaque == "" && u.Path == "" {
+		return fmt.Errorf("not an absolute URL")
+	}
+	*v.p = *u
+	return nil
+}
+
+func (v urlValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+func (v urlValue) Get() any { return v.p }
+
+// URLVar defines a flag holding an absolute URL, with value as its default.
+// An empty value leaves *p as it is. It panics if value isn't a URL.
+func (f *FlagSet) URLVar(p *url.URL, name string, value string, usage string) {
+	v := urlValue{p}
+	if value != "" {
+		if err := v.Set(value); err != nil {
+			panic(fmt.Sprintf("gnuflag: default %q for flag %s: %v", value, name, err))
+		}
+	}
+	f.Var(v, name, usage)
+}
+
+// URL is URLVar with a new url.URL.
+func (f *FlagSet) URL(name string, value string, usage string) *url.URL {
+	p := new(url.URL)
+	f.URLVar(p, name, value, usage)
+	return p
+}
+
+type enumValue struct {
+	p       *string
+	allowed []string
+}
+
+func (v enumValue) Set(s string) error {
+	if !slices.Contains(v.allowed, s) {
+		return fmt.Errorf("must be one of %s", strings.Join(v.allowed, ", "))
+	}
+	*v.p = s
+	return nil
+}
+
+func (v enumValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return *v.p
+}
+
+func (v enumValue) Get() any { return *v.p }
+
+// EnumVar defines a string flag that only takes one of the allowed values.
+// The default needn't be one of them, so "" can mean unset.
+func (f *FlagSet) EnumVar(p *string, name string, value string, allowed []string, usage string) {
+	*p = value
+	f.Var(enumValue{p, slices.Clone(allowed)}, name, usage)
+}
+
+// Enum is EnumVar with a new string.
+func (f *FlagSet) Enum(name string, value string, allowed []string, usage string) *string {
+	p := new(string)
+	f.EnumVar(p, name, value, allowed, usage)
+	return p
+}
+
+var (
+	_ flag.Getter = (*stringSlice)(nil)
+	_ flag.Getter = ipValue{}
+	_ flag.Getter = urlValue{}
+	_ flag.Getter = enumValue{}
+)
@@ -0,0 +1,3 @@
+module github.com/prashant1k99/GoLearn/lib
+
+go 1.23
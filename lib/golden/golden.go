@@ -0,0 +1,356 @@
+// Package golden checks the examples against the output they document.
+//
+// Several examples end with a block comment showing what they print, either
+// after an "Output:" line (RateLimiting.go, WorkerPool.go, AtomicCounter2.go)
+// or as a shell session of "$ ./program args" lines each followed by its
+// output (CmdFlags.go). Extract turns those blocks into Cases. Compare
+// normalizes the expected and the real output with a set of Rules, so that
+// timestamps, addresses and goroutine scheduling don't count as drift, and
+// reports the lines that differ.
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Wildcard, on a line of its own in an expected block, matches any number of
+// output lines, as the "..." lines in CmdFlags.go already do.
+const Wildcard = "..."
+
+// Case is one documented run of an example.
+type Case struct {
+	Args     []string // command-line arguments, from a "$ ./program args" line
+	Expected []string
+	Line     int // line of the source file the expected output starts on
+}
+
+var (
+	outputMarkerRe = regexp.MustCompile(`(?i)^(final )?output:?$`)
+	// Descriptions some blocks put between "Output:" and the output itself.
+	proseRe = regexp.MustCompile(`^The (output|program) `)
+)
+
+// Extract parses a Go source file and returns the cases documented in its
+// block comments.
+func Extract(filename string, src []byte) ([]Case, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var cases []Case
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, "/*") {
+				continue
+			}
+			body := strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+			line := fset.Position(c.Pos()).Line
+			cases = append(cases, extractBlock(strings.Split(body, "\n"), line)...)
+		}
+	}
+	return cases, nil
+}
+
+// extractBlock finds cases in the lines of one block comment; first is the
+// file line the comment starts on.
+func extractBlock(lines []string, first int) []Case {
+	var cases []Case
+
+	// Shell-session style: "$ ./prog args" starts a case that runs until a
+	// blank line. "$ go build ..." lines are instructions, not runs.
+	for i := 0; i < len(lines); i++ {
+		cmd, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), "$ ")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(cmd)
+		if len(fields) == 0 || fields[0] == "go" {
+			continue
+		}
+		c := Case{Args: fields[1:], Line: first + i + 1}
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+			if strings.HasPrefix(strings.TrimSpace(lines[i]), "$ ") {
+				i--
+				break
+			}
+			c.Expected = append(c.Expected, strings.TrimSpace(lines[i]))
+		}
+		cases = append(cases, c)
+	}
+	if len(cases) > 0 {
+		return cases
+	}
+
+	// "Output:" style: everything after the marker up to the first blank line
+	// that follows some output.
+	for i, l := range lines {
+		if !outputMarkerRe.MatchString(strings.TrimSpace(l)) {
+			continue
+		}
+		j := i + 1
+		for j < len(lines) && (strings.TrimSpace(lines[j]) == "" || proseRe.MatchString(strings.TrimSpace(lines[j]))) {
+			j++
+		}
+		c := Case{Line: first + j}
+		for ; j < len(lines) && strings.TrimSpace(lines[j]) != ""; j++ {
+			c.Expected = append(c.Expected, strings.TrimSpace(lines[j]))
+		}
+		if len(c.Expected) > 0 {
+			cases = append(cases, c)
+		}
+		break
+	}
+	return cases
+}
+
+// Rule rewrites every match of Pattern to Replace before lines are compared.
+type Rule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	Replace string `json:"replace"`
+
+	re *regexp.Regexp
+}
+
+func (r *Rule) compile() error {
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("golden: rule %q: %w", r.Name, err)
+	}
+	r.re = re
+	return nil
+}
+
+// DefaultRules cover what differs between any two runs of most examples.
+var DefaultRules = []Rule{
+	{Name: "timestamp", Pattern: `\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}(\.\d+)?( ?(Z|[+-]\d{2}:?\d{2}))?( [A-Z]{2,5})?( m=[+-]\d+\.\d+)?`, Replace: "<time>"},
+	{Name: "address", Pattern: `0x[0-9a-fA-F]+`, Replace: "<addr>"},
+	{Name: "duration", Pattern: `\b\d+(\.\d+)?(ns|µs|us|ms|s|m|h)\b`, Replace: "<dur>"},
+}
+
+// ExampleConfig adjusts the check for a single example.
+type ExampleConfig struct {
+	// Skip, when non-empty, says why the example isn't checked.
+	Skip string `json:"skip,omitempty"`
+	// Unordered lists patterns for lines whose order is up to the scheduler.
+	// Matching lines are compared as a multiset; the others keep their order.
+	Unordered []string `json:"unordered,omitempty"`
+	Rules     []Rule   `json:"rules,omitempty"`
+	// Stdin is fed to the example.
+	Stdin string `json:"stdin,omitempty"`
+	// Timeout overrides Config.Timeout.
+	Timeout Duration `json:"timeout,omitempty"`
+}
+
+// Config is the checker's configuration, usually loaded from golden.json.
+type Config struct {
+	Timeout  Duration                 `json:"timeout,omitempty"`
+	Rules    []Rule                   `json:"rules,omitempty"`
+	Examples map[string]ExampleConfig `json:"examples,omitempty"`
+}
+
+// Duration is a time.Duration written as "10s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// LoadConfig reads a JSON config file.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(b)
+}
+
+// ParseConfig decodes a JSON config.
+func ParseConfig(b []byte) (*Config, error) {
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("golden: config: %w", err)
+	}
+	return &c, nil
+}
+
+// Matcher compares output for one example.
+type Matcher struct {
+	rules     []Rule
+	unordered []*regexp.Regexp
+}
+
+// NewMatcher combines DefaultRules, the config-wide rules and the example's
+// own settings.
+func (c *Config) NewMatcher(example string) (*Matcher, error) {
+	ec := c.Examples[example]
+	m := &Matcher{}
+	for _, set := range [][]Rule{DefaultRules, c.Rules, ec.Rules} {
+		for _, r := range set {
+			if err := r.compile(); err != nil {
+				return nil, err
+			}
+			m.rules = append(m.rules, r)
+		}
+	}
+	for _, p := range ec.Unordered {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("golden: %s: unordered pattern: %w", example, err)
+		}
+		m.unordered = append(m.unordered, re)
+	}
+	return m, nil
+}
+
+// Normalize applies the rules to one line and trims its surrounding space.
+func (m *Matcher) Normalize(line string) string {
+	line = strings.TrimSpace(line)
+	for _, r := range m.rules {
+		line = r.re.ReplaceAllString(line, r.Replace)
+	}
+	return line
+}
+
+// Compare checks actual output against the expected lines and returns a
+// description of the differences, or "" when they match.
+func (m *Matcher) Compare(expected []string, actual string) string {
+	want := m.normalizeAll(expected)
+	got := m.normalizeAll(strings.Split(strings.TrimRight(actual, "\n"), "\n"))
+
+	wantOrdered, wantFree := m.partition(want)
+	gotOrdered, gotFree := m.partition(got)
+
+	var report strings.Builder
+	if !globMatch(wantOrdered, gotOrdered) {
+		report.WriteString(diff(wantOrdered, gotOrdered))
+	}
+	missing, extra := multisetDiff(wantFree, gotFree)
+	for _, l := range missing {
+		fmt.Fprintf(&report, "- %s   (unordered)\n", l)
+	}
+	for _, l := range extra {
+		fmt.Fprintf(&report, "+ %s   (unordered)\n", l)
+	}
+	return report.String()
+}
+
+func (m *Matcher) normalizeAll(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, m.Normalize(l))
+	}
+	return out
+}
+
+// partition splits lines into those whose order matters and those matching
+// an unordered pattern. A wildcard always stays with the ordered lines.
+func (m *Matcher) partition(lines []string) (ordered, free []string) {
+	for _, l := range lines {
+		if l != Wildcard && slices.ContainsFunc(m.unordered, func(re *regexp.Regexp) bool { return re.MatchString(l) }) {
+			free = append(free, l)
+		} else {
+			ordered = append(ordered, l)
+		}
+	}
+	return ordered, free
+}
+
+// globMatch matches lines against a pattern where a Wildcard line stands for
+// any run of lines, including none.
+func globMatch(pattern, lines []string) bool {
+	// match[i][j]: pattern[i:] matches lines[j:].
+	match := make([][]bool, len(pattern)+1)
+	for i := range match {
+		match[i] = make([]bool, len(lines)+1)
+	}
+	match[len(pattern)][len(lines)] = true
+	for i := len(pattern) - 1; i >= 0; i-- {
+		for j := len(lines); j >= 0; j-- {
+			if pattern[i] == Wildcard {
+				match[i][j] = match[i+1][j] || (j < len(lines) && match[i][j+1])
+			} else {
+				match[i][j] = j < len(lines) && pattern[i] == lines[j] && match[i+1][j+1]
+			}
+		}
+	}
+	return match[0][0]
+}
+
+// diff renders a line diff of want against got from their longest common
+// subsequence, treating wildcards as lines of their own.
+func diff(want, got []string) string {
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			fmt.Fprintf(&b, "  %s\n", want[i])
+			i++
+			j++
+		case j < len(got) && (i == len(want) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&b, "+ %s\n", got[j])
+			j++
+		default:
+			fmt.Fprintf(&b, "- %s\n", want[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+func multisetDiff(want, got []string) (missing, extra []string) {
+	count := make(map[string]int)
+	for _, l := range got {
+		count[l]++
+	}
+	for _, l := range want {
+		if count[l] > 0 {
+			count[l]--
+		} else {
+			missing = append(missing, l)
+		}
+	}
+	for _, l := range got {
+		if count[l] > 0 {
+			count[l]--
+			extra = append(extra, l)
+		}
+	}
+	return missing, extra
+}
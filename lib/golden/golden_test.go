@@ -0,0 +1,159 @@
+package golden
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	var tests = []struct {
+		name string
+		src  string
+		want []Case
+	}{
+		{"output block", `package main
+
+func main() {}
+
+/*
+Output:
+
+one
+two
+
+not output
+*/
+`, []Case{{Expected: []string{"one", "two"}, Line: 8}}},
+		{"prose before output", `package main
+
+/*
+Final output:
+The output will be like:
+  a
+  b
+*/
+`, []Case{{Expected: []string{"a", "b"}, Line: 6}}},
+		{"shell session", `package main
+
+/*
+$ go build prog.go
+$ ./prog -n=2 x
+n: 2
+tail: [x]
+$ ./prog -h
+Usage of ./prog:
+...
+
+Some prose.
+*/
+`, []Case{
+			{Args: []string{"-n=2", "x"}, Expected: []string{"n: 2", "tail: [x]"}, Line: 6},
+			{Args: []string{"-h"}, Expected: []string{"Usage of ./prog:", "..."}, Line: 9},
+		}},
+		{"line comments ignored", "package main\n\n// Output:\n// one\n", nil},
+		{"no output", "package main\n\n/* just a note */\n", nil},
+	}
+	for _, tt := range tests {
+		got, err := Extract("x.go", []byte(tt.src))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !slices.EqualFunc(got, tt.want, equalCase) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := Extract("bad.go", []byte("package main\nfunc main() {")); err == nil {
+		t.Error("unparsable source: got nil error")
+	}
+}
+
+func equalCase(a, b Case) bool {
+	return slices.Equal(a.Args, b.Args) && slices.Equal(a.Expected, b.Expected) && a.Line == b.Line
+}
+
+func TestGlobMatch(t *testing.T) {
+	var tests = []struct {
+		pattern, lines string
+		want           bool
+	}{
+		{"", "", true},
+		{"a", "a", true},
+		{"a", "b", false},
+		{"a b", "a", false},
+		{"a", "a b", false},
+		{"...", "", true},
+		{"...", "a b c", true},
+		{"a ...", "a", true},
+		{"a ...", "a b c", true},
+		{"... c", "a b c", true},
+		{"... c", "a b", false},
+		{"a ... c", "a c", true},
+		{"a ... c", "a b b c", true},
+		{"a ... c ... e", "a b c d e", true},
+		{"a ... c ... e", "a b d e", false},
+		{"... ...", "x", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(strings.Fields(tt.pattern), strings.Fields(tt.lines)); got != tt.want {
+			t.Errorf("globMatch(%q, %q): got %v, want %v", tt.pattern, tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{
+		"rules": [{"name": "pid", "pattern": "pid \\d+", "replace": "pid <n>"}],
+		"examples": {
+			"Pool": {
+				"unordered": ["^worker "],
+				"rules": [{"name": "id", "pattern": "^worker \\d+", "replace": "worker <n>"}]
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		example  string
+		expected []string
+		actual   string
+		want     string
+	}{
+		{"Plain", []string{"a", "b"}, "a\nb\n", ""},
+		{"Plain", []string{"  a  "}, "a", ""},
+		{"Plain", []string{"a", "b"}, "a\nc\n", "  a\n+ c\n- b\n"},
+		{"Plain", []string{"a"}, "a\nb\n", "  a\n+ b\n"},
+		{"Plain", []string{"a", "...", "z"}, "a\nb\nc\nz\n", ""},
+		{"Plain", []string{"took 1.5s at 0xc000010000"}, "took 20ms at 0xc0000a2000\n", ""},
+		{"Plain", []string{"2024-01-02 15:04:05 start"}, "2026-10-19 08:00:00.123 +0000 UTC start\n", ""},
+		{"Plain", []string{"pid 12"}, "pid 4711\n", ""},
+		{"Pool", []string{"start", "worker 1 done", "worker 2 done", "end"}, "start\nworker 9 done\nend\nworker 3 done\n", ""},
+		{"Pool", []string{"worker 1 done", "worker 2 done"}, "worker 1 done\n", "- worker <n> done   (unordered)\n"},
+		{"Pool", []string{"start"}, "start\nworker 1 done\n", "+ worker <n> done   (unordered)\n"},
+	}
+	for _, tt := range tests {
+		m, err := cfg.NewMatcher(tt.example)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Compare(tt.expected, tt.actual); got != tt.want {
+			t.Errorf("%s %q vs %q: got %q, want %q", tt.example, tt.expected, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestBadConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"examples": {"X": {"rules": [{"name": "broken", "pattern": "("}]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.NewMatcher("X"); err == nil || !strings.Contains(err.Error(), `rule "broken"`) {
+		t.Errorf("got %v, want an error naming the rule", err)
+	}
+	if _, err := ParseConfig([]byte(`{"timeout": "soon"}`)); err == nil {
+		t.Error("bad timeout: got nil error")
+	}
+}
@@ -0,0 +1,187 @@
+// Package group runs goroutines as a unit, the way WaitGroups.go and
+// ChannelSynchronization2.go wait for their workers, but with errors: a
+// Group collects what its goroutines return, cancels their shared context on
+// the first failure, can cap how many run at once, and turns panics into
+// errors carrying the stack.
+//
+// Scope ties a group's lifetime to a block of code: it returns only after
+// every goroutine started in it has, and scopes nest, so a worker can run a
+// group of its own that its parent's cancellation reaches:
+//
+//	err := group.Scope(ctx, func(ctx context.Context, g *group.Group) error {
+//		g.SetLimit(4)
+//		for _, url := range urls {
+//			g.Go(func(ctx context.Context) error { return fetch(ctx, url) })
+//		}
+//		return nil
+//	})
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Group is a set of goroutines working on the same task. The zero value
+// has no limit and no context to cancel; use WithContext or Scope for one
+// that cancels siblings.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// WithContext returns a new Group and the context its goroutines get. The
+// context is cancelled when a goroutine fails or Wait returns, whichever is
+// first.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	c, cancel := context.WithCancelCause(ctx)
+	return &Group{ctx: c, cancel: cancel}, c
+}
+
+// SetLimit caps the number of goroutines running at once at n; Go blocks
+// until one finishes. A negative n removes the cap. It panics if called
+// while goroutines are running.
+func (g *Group) SetLimit(n int) {
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("group: SetLimit called with %d goroutines running", len(g.sem)))
+	}
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+func (g *Group) context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
+}
+
+// Go runs f in a new goroutine, waiting first for a free slot if the group
+// has a limit. f is skipped if the group has already failed by the time it
+// would start.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(f)
+}
+
+// TryGo is Go without the wait: it reports false, and doesn't run f, if the
+// group is at its limit.
+func (g *Group) TryGo(f func(ctx context.Context) error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(f)
+	return true
+}
+
+func (g *Group) start(f func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		ctx := g.context()
+		if ctx.Err() != nil && g.failed() {
+			return
+		}
+		g.record(call(ctx, f))
+	}()
+}
+
+// call runs f, turning a panic into a *PanicError.
+func call(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return f(ctx)
+}
+
+func (g *Group) failed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.errs) > 0
+}
+
+// record keeps err and, if it is the first, cancels the group. Cancellation
+// errors from goroutines that stopped because a sibling failed are dropped:
+// they are the group's own doing, not news.
+func (g *Group) record(err error) {
+	if err == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) > 0 && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return
+	}
+	g.errs = append(g.errs, err)
+	if len(g.errs) == 1 && g.cancel != nil {
+		g.cancel(err)
+	}
+}
+
+// Wait waits for every goroutine started with Go or TryGo and returns their
+// errors: nil, the only error, or all of them joined with errors.Join in the
+// order they happened.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch len(g.errs) {
+	case 0:
+		return nil
+	case 1:
+		return g.errs[0]
+	}
+	return errors.Join(g.errs...)
+}
+
+// Scope runs fn with a new group and waits for all of the group's goroutines
+// before returning, so none outlive the call. fn runs in the calling
+// goroutine as a member of the group: its error or panic fails the group
+// like any other. The group's context is derived from ctx, so scopes nest.
+func Scope(ctx context.Context, fn func(ctx context.Context, g *Group) error) error {
+	g, gctx := WithContext(ctx)
+	g.record(call(gctx, func(ctx context.Context) error { return fn(ctx, g) }))
+	return g.Wait()
+}
+
+// PanicError is a panic recovered from a goroutine in a group.
+type PanicError struct {
+	Value any    // what was passed to panic
+	Stack []byte // the goroutine's stack when it panicked
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("group: panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error, so errors.Is sees
+// through a panic(err).
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
@@ -0,0 +1,265 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitNoErrors(t *testing.T) {
+	var g Group
+	var n atomic.Int32
+	for i := 0; i < 10; i++ {
+		g.Go(func(context.Context) error {
+			n.Add(1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if n.Load() != 10 {
+		t.Errorf("ran %d goroutines", n.Load())
+	}
+}
+
+func TestFirstErrorCancelsSiblings(t *testing.T) {
+	boom := errors.New("boom")
+	g, ctx := WithContext(context.Background())
+	for i := 0; i < 5; i++ {
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+	g.Go(func(context.Context) error { return boom })
+
+	if err := g.Wait(); err != boom {
+		t.Errorf("Wait() = %v, want only boom", err)
+	}
+	if cause := context.Cause(ctx); cause != boom {
+		t.Errorf("context cause = %v", cause)
+	}
+}
+
+func TestCollectsAllErrors(t *testing.T) {
+	e1, e2 := errors.New("one"), errors.New("two")
+	var g Group
+	g.Go(func(context.Context) error { return e1 })
+	g.Go(func(context.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return e2
+	})
+	err := g.Wait()
+	if !errors.Is(err, e1) || !errors.Is(err, e2) {
+		t.Errorf("Wait() = %v, want both errors", err)
+	}
+	if err.Error() != "one\ntwo" {
+		t.Errorf("errors not in the order they happened: %q", err.Error())
+	}
+}
+
+func TestGoAfterFailureIsSkipped(t *testing.T) {
+	g, _ := WithContext(context.Background())
+	g.Go(func(context.Context) error { return errors.New("boom") })
+	time.Sleep(5 * time.Millisecond)
+	ran := false
+	g.Go(func(context.Context) error {
+		ran = true
+		return nil
+	})
+	g.Wait()
+	if ran {
+		t.Error("a goroutine started after the group failed")
+	}
+}
+
+func TestLimit(t *testing.T) {
+	var g Group
+	g.SetLimit(3)
+	var running, peak atomic.Int32
+	for i := 0; i < 20; i++ {
+		g.Go(func(context.Context) error {
+			r := running.Add(1)
+			for {
+				p := peak.Load()
+				if r <= p || peak.CompareAndSwap(p, r) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if p := peak.Load(); p != 3 {
+		t.Errorf("peak concurrency %d, want 3", p)
+	}
+}
+
+func TestTryGo(t *testing.T) {
+	var g Group
+	g.SetLimit(1)
+	release := make(chan struct{})
+	if !g.TryGo(func(context.Context) error { <-release; return nil }) {
+		t.Fatal("TryGo failed on an empty group")
+	}
+	if g.TryGo(func(context.Context) error { return nil }) {
+		t.Error("TryGo succeeded past the limit")
+	}
+	close(release)
+	g.Wait()
+	if !g.TryGo(func(context.Context) error { return nil }) {
+		t.Error("TryGo failed after the group drained")
+	}
+	g.Wait()
+}
+
+func TestSetLimitWhileRunningPanics(t *testing.T) {
+	var g Group
+	g.SetLimit(2)
+	release := make(chan struct{})
+	g.Go(func(context.Context) error { <-release; return nil })
+	defer func() {
+		close(release)
+		g.Wait()
+		if recover() == nil {
+			t.Error("SetLimit with a goroutine running did not panic")
+		}
+	}()
+	g.SetLimit(5)
+}
+
+func TestPanicBecomesError(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	var g Group
+	g.Go(func(context.Context) error { return panicker(sentinel) })
+	err := g.Wait()
+
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Wait() = %v, want a *PanicError", err)
+	}
+	if !errors.Is(err, sentinel) {
+		t.Error("errors.Is doesn't see the panicked error")
+	}
+	if !strings.Contains(string(pe.Stack), "panicker") {
+		t.Errorf("stack doesn't show where the panic happened:\n%s", pe.Stack)
+	}
+	if !strings.HasPrefix(err.Error(), "group: panic: sentinel\n") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func panicker(v any) error {
+	panic(v)
+}
+
+func TestScopeWaitsForAll(t *testing.T) {
+	before := runtime.NumGoroutine()
+	var done atomic.Int32
+	err := Scope(context.Background(), func(ctx context.Context, g *Group) error {
+		for i := 0; i < 5; i++ {
+			g.Go(func(context.Context) error {
+				time.Sleep(2 * time.Millisecond)
+				done.Add(1)
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.Load() != 5 {
+		t.Errorf("Scope returned with %d of 5 goroutines done", done.Load())
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines before, %d after", before, after)
+	}
+}
+
+func TestScopeBodyErrorCancels(t *testing.T) {
+	bad := errors.New("bad input")
+	err := Scope(context.Background(), func(ctx context.Context, g *Group) error {
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		return bad
+	})
+	if err != bad {
+		t.Errorf("Scope() = %v", err)
+	}
+}
+
+func TestNestedScopes(t *testing.T) {
+	inner := errors.New("inner failure")
+	var siblingCancelled atomic.Bool
+	siblingStarted := make(chan struct{})
+	err := Scope(context.Background(), func(ctx context.Context, g *Group) error {
+		// A sibling of the failing worker, in the outer scope. It must be
+		// running before the failure, or the group rightly skips it.
+		g.Go(func(ctx context.Context) error {
+			close(siblingStarted)
+			<-ctx.Done()
+			siblingCancelled.Store(true)
+			return ctx.Err()
+		})
+		// A worker running a scope of its own, one of whose goroutines fails.
+		g.Go(func(ctx context.Context) error {
+			return Scope(ctx, func(ctx context.Context, g *Group) error {
+				g.Go(func(context.Context) error {
+					<-siblingStarted
+					return inner
+				})
+				return nil
+			})
+		})
+		return nil
+	})
+	if err != inner {
+		t.Errorf("Scope() = %v", err)
+	}
+	if !siblingCancelled.Load() {
+		t.Error("the outer sibling wasn't cancelled")
+	}
+}
+
+func TestParentCancelReachesNestedScope(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(5*time.Millisecond, cancel)
+	err := Scope(ctx, func(ctx context.Context, g *Group) error {
+		g.Go(func(ctx context.Context) error {
+			return Scope(ctx, func(ctx context.Context, g *Group) error {
+				g.Go(func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+				return nil
+			})
+		})
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Scope() = %v", err)
+	}
+}
+
+func BenchmarkGo(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g, _ := WithContext(context.Background())
+		for j := 0; j < 10; j++ {
+			g.Go(func(context.Context) error { return nil })
+		}
+		g.Wait()
+	}
+}
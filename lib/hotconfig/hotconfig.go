@@ -0,0 +1,226 @@
+// Package hotconfig reloads a config while the program runs.
+//
+// Signals.go catches SIGINT and SIGTERM to exit; a long-running server
+// usually also takes SIGHUP to mean "read your config again", so a log level
+// or a rate limit can change without a restart. A Manager holds the current
+// config, loaded by a config.Loader, and reloads it on SIGHUP or when the
+// config file changes (watched with inotify on Linux, polled elsewhere):
+//
+//	type Config struct {
+//		Level slog.Level `default:"info"`
+//		Rate  int        `default:"100" min:"1"`
+//	}
+//
+//	m, err := hotconfig.New[Config](&config.Loader{File: "app.toml"}, nil)
+//	...
+//	var level slog.LevelVar
+//	level.Set(m.Current().Level)
+//	m.Subscribe(func(old, new *Config) error {
+//		level.Set(new.Level)
+//		return nil
+//	})
+//	go m.Watch(ctx)
+//
+// A reload reads into a new value and checks it, with the config tags and
+// the Manager's own validation; a bad file leaves the old config in place.
+// Subscribers hear of the change next, while Current still returns the old
+// config, and if one of them fails the Manager keeps the old config and
+// tells the ones already told. Only then does it switch, with an atomic
+// pointer swap, so readers calling Current never see half a config or one a
+// subscriber refused.
+package hotconfig
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/config"
+)
+
+// Manager holds a config of type T and reloads it.
+type Manager[T any] struct {
+	// Debounce is how long Watch waits after the file last changed before
+	// reloading, since editors and deploy tools often write a file in more
+	// than one step. New sets it to 100ms.
+	Debounce time.Duration
+	// OnError is called with the errors of reloads Watch starts. Nil means
+	// log.Print.
+	OnError func(error)
+
+	loader   *config.Loader
+	validate func(*T) error
+	state    atomic.Pointer[state[T]]
+
+	reload sync.Mutex // held while reloading
+
+	subsMu sync.Mutex
+	subs   []*subscriber[T]
+}
+
+type state[T any] struct {
+	cfg    *T
+	report *config.Report
+}
+
+type subscriber[T any] struct {
+	f func(old, new *T) error
+}
+
+// New loads the first config with l and returns a Manager holding it.
+// validate, if not nil, checks a config beyond what its tags say, for rules
+// across fields; it is called on every load.
+func New[T any](l *config.Loader, validate func(*T) error) (*Manager[T], error) {
+	m := &Manager[T]{
+		Debounce: 100 * time.Millisecond,
+		loader:   l,
+		validate: validate,
+	}
+	s, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	m.state.Store(s)
+	return m, nil
+}
+
+// Current returns the config in use. It is shared, so treat it as read-only;
+// a reload replaces it rather than changing it.
+func (m *Manager[T]) Current() *T {
+	return m.state.Load().cfg
+}
+
+// Report returns the config.Report of the config in use.
+func (m *Manager[T]) Report() *config.Report {
+	return m.state.Load().report
+}
+
+func (m *Manager[T]) load() (*state[T], error) {
+	cfg := new(T)
+	r, err := m.loader.Load(cfg)
+	if err == nil && m.validate != nil {
+		err = m.validate(cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("hotconfig: %w", err)
+	}
+	return &state[T]{cfg, r}, nil
+}
+
+// Subscribe calls f with the old and new config after every reload that
+// changes it, in the order subscribers were added, one reload at a time,
+// before Current returns the new config. If f returns an error the Manager
+// keeps the old config, calls the subscribers before f again with the
+// configs the other way round, and Reload returns the error. The returned
+// func unsubscribes f.
+func (m *Manager[T]) Subscribe(f func(old, new *T) error) (unsubscribe func()) {
+	s := &subscriber[T]{f}
+	m.subsMu.Lock()
+	m.subs = append(m.subs, s)
+	m.subsMu.Unlock()
+	return func() {
+		m.subsMu.Lock()
+		defer m.subsMu.Unlock()
+		for i, x := range m.subs {
+			if x == s {
+				m.subs = append(m.subs[:i:i], m.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Reload loads the config again and, if it is valid and different, tells the
+// subscribers and switches to it. On any error the config in use stays and
+// the error is returned.
+func (m *Manager[T]) Reload() error {
+	m.reload.Lock()
+	defer m.reload.Unlock()
+
+	next, err := m.load()
+	if err != nil {
+		return err
+	}
+	old := m.state.Load()
+	if reflect.DeepEqual(old.cfg, next.cfg) {
+		// Same values, though the report may say some came from elsewhere.
+		m.state.Store(next)
+		return nil
+	}
+
+	m.subsMu.Lock()
+	subs := append([]*subscriber[T](nil), m.subs...)
+	m.subsMu.Unlock()
+	for i, s := range subs {
+		if err := s.f(old.cfg, next.cfg); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				subs[j].f(next.cfg, old.cfg)
+			}
+			return fmt.Errorf("hotconfig: rolled back: %w", err)
+		}
+	}
+	m.state.Store(next)
+	return nil
+}
+
+// Watch reloads the config on SIGHUP, and when the config file changes,
+// until ctx is done. Reload errors go to OnError and the old config stays.
+// It returns ctx's error, or an error if the file can't be watched.
+func (m *Manager[T]) Watch(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var changed <-chan struct{}
+	if file := m.Report().File; file != "" {
+		c := make(chan struct{}, 1)
+		stop, err := watchFile(file, c)
+		if err != nil {
+			return fmt.Errorf("hotconfig: watching %s: %w", file, err)
+		}
+		defer stop()
+		changed = c
+	}
+
+	var debounce <-chan time.Time
+	var timer *time.Timer
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return ctx.Err()
+		case <-hup:
+			m.reloadAndReport()
+		case <-changed:
+			if timer == nil {
+				timer = time.NewTimer(m.Debounce)
+				debounce = timer.C
+			} else {
+				timer.Reset(m.Debounce)
+			}
+		case <-debounce:
+			m.reloadAndReport()
+		}
+	}
+}
+
+func (m *Manager[T]) reloadAndReport() {
+	err := m.Reload()
+	if err == nil {
+		return
+	}
+	if m.OnError != nil {
+		m.OnError(err)
+		return
+	}
+	log.Print(err)
+}
@@ -0,0 +1,305 @@
+package hotconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/config"
+)
+
+type testConfig struct {
+	Level slog.Level `default:"info"`
+	Rate  int        `default:"100" min:"1"`
+	Burst int        `default:"10"`
+}
+
+func noEnv(string) (string, bool) { return "", false }
+
+// newManager writes content to a config file and loads it.
+func newManager(t *testing.T, content string, validate func(*testConfig) error) (*Manager[testConfig], string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.toml")
+	write(t, path, content)
+	m, err := New(&config.Loader{File: path, LookupEnv: noEnv}, validate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, path
+}
+
+// write replaces the file the way editors do, by renaming a new one over it.
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// burstAtMostRate is a rule across fields, which tags can't express.
+func burstAtMostRate(c *testConfig) error {
+	if c.Burst > c.Rate {
+		return fmt.Errorf("burst %d is more than rate %d", c.Burst, c.Rate)
+	}
+	return nil
+}
+
+type change struct{ old, new testConfig }
+
+func record(changes *[]change) func(old, new *testConfig) error {
+	return func(old, new *testConfig) error {
+		*changes = append(*changes, change{*old, *new})
+		return nil
+	}
+}
+
+func TestReload(t *testing.T) {
+	m, path := newManager(t, "level = \"warn\"\nrate = 50\n", burstAtMostRate)
+	if c := m.Current(); c.Level != slog.LevelWarn || c.Rate != 50 || c.Burst != 10 {
+		t.Fatalf("got %+v", *c)
+	}
+	var changes []change
+	m.Subscribe(record(&changes))
+
+	var tests = []struct {
+		content string
+		err     string // "" if the reload should work
+		want    testConfig
+	}{
+		{"level = \"debug\"\nrate = 50\n", "", testConfig{slog.LevelDebug, 50, 10}},
+		{"level = \"debug\"\nrate = 0\n", "rate", testConfig{slog.LevelDebug, 50, 10}},
+		{"level = \"loud\"\n", "level", testConfig{slog.LevelDebug, 50, 10}},
+		{"level = \"debug\"\nrate = 5\n", "burst 10 is more than rate 5", testConfig{slog.LevelDebug, 50, 10}},
+		{"level = \"debug\"\nrate = 50\nnope = 1\n", "unknown key", testConfig{slog.LevelDebug, 50, 10}},
+		{"rate = 20\nburst = 20\n", "", testConfig{slog.LevelInfo, 20, 20}},
+	}
+	for _, tt := range tests {
+		write(t, path, tt.content)
+		before := *m.Current()
+		n := len(changes)
+		err := m.Reload()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%q: got error %v, want %q", tt.content, err, tt.err)
+		}
+		if got := *m.Current(); got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.content, got, tt.want)
+		}
+		switch {
+		case tt.err != "" && len(changes) != n:
+			t.Errorf("%q: subscriber told of a failed reload", tt.content)
+		case tt.err == "" && (len(changes) != n+1 || changes[n] != change{before, tt.want}):
+			t.Errorf("%q: changes %+v", tt.content, changes[n:])
+		}
+	}
+}
+
+func TestReloadUnchanged(t *testing.T) {
+	m, path := newManager(t, "rate = 50\n", nil)
+	var changes []change
+	m.Subscribe(record(&changes))
+	write(t, path, "# same values\nrate = 50\nburst = 10\n")
+	if err := m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("got %+v, want no changes", changes)
+	}
+	if from := m.Report().Field("burst").From; !strings.HasPrefix(from, "file") {
+		t.Errorf("report says burst is from %q, want the file", from)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	m, path := newManager(t, "rate = 50\n", nil)
+	var first, third []change
+	m.Subscribe(record(&first))
+	refuse := errors.New("can't apply")
+	m.Subscribe(func(old, new *testConfig) error { return refuse })
+	m.Subscribe(record(&third))
+
+	write(t, path, "rate = 60\n")
+	if err := m.Reload(); !errors.Is(err, refuse) {
+		t.Fatalf("got %v, want %v", err, refuse)
+	}
+	if m.Current().Rate != 50 {
+		t.Errorf("got rate %d, want 50 restored", m.Current().Rate)
+	}
+	a, b := testConfig{slog.LevelInfo, 50, 10}, testConfig{slog.LevelInfo, 60, 10}
+	if len(first) != 2 || first[0] != (change{a, b}) || first[1] != (change{b, a}) {
+		t.Errorf("first subscriber got %+v, want the change and its undoing", first)
+	}
+	if len(third) != 0 {
+		t.Errorf("third subscriber got %+v", third)
+	}
+}
+
+// TestSwitchAfterSubscribers checks that Current keeps returning the old
+// config while subscribers are told, so none of them can see a config
+// another is about to refuse.
+func TestSwitchAfterSubscribers(t *testing.T) {
+	m, path := newManager(t, "rate = 50\n", nil)
+	var during []int
+	m.Subscribe(func(old, new *testConfig) error {
+		during = append(during, m.Current().Rate)
+		return nil
+	})
+	write(t, path, "rate = 60\n")
+	if err := m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(during) != 1 || during[0] != 50 {
+		t.Errorf("Current during the change: got %v, want [50]", during)
+	}
+	if m.Current().Rate != 60 {
+		t.Errorf("got rate %d after the reload, want 60", m.Current().Rate)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	m, path := newManager(t, "rate = 50\n", nil)
+	var a, b []change
+	unsubA := m.Subscribe(record(&a))
+	m.Subscribe(record(&b))
+	unsubA()
+	unsubA()
+	write(t, path, "rate = 60\n")
+	if err := m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 0 || len(b) != 1 {
+		t.Errorf("got %d and %d changes, want 0 and 1", len(a), len(b))
+	}
+}
+
+func TestNewError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	write(t, path, "rate = 0\n")
+	if _, err := New[testConfig](&config.Loader{File: path, LookupEnv: noEnv}, nil); err == nil {
+		t.Error("no error for an invalid first config")
+	}
+}
+
+// TestConcurrentReads is for the race detector: readers never see a config
+// being written.
+func TestConcurrentReads(t *testing.T) {
+	m, path := newManager(t, "rate = 50\nburst = 50\n", nil)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if c := m.Current(); c.Rate != c.Burst {
+					t.Errorf("torn config %+v", *c)
+					return
+				}
+			}
+		}()
+	}
+	for i := range 20 {
+		write(t, path, fmt.Sprintf("rate = %d\nburst = %[1]d\n", 51+i))
+		if err := m.Reload(); err != nil {
+			t.Error(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestWatchFile(t *testing.T) {
+	m, path := newManager(t, "rate = 50\n", nil)
+	m.Debounce = 20 * time.Millisecond
+	errs := make(chan error, 10)
+	m.OnError = func(err error) { errs <- err }
+	changes := make(chan change, 10)
+	m.Subscribe(func(old, new *testConfig) error {
+		changes <- change{*old, *new}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Watch(ctx) }()
+	// Give Watch time to start watching before changing the file.
+	time.Sleep(50 * time.Millisecond)
+
+	write(t, path, "rate = 0\n")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "rate") {
+			t.Errorf("got %v", err)
+		}
+	case c := <-changes:
+		t.Fatalf("invalid file applied: %+v", c)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after an invalid write")
+	}
+
+	// In-place writes in quick succession, which the debounce should
+	// take as one change.
+	for i := range 3 {
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("rate = %d\n", 60+i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for rate := 50; rate != 62; {
+		select {
+		case c := <-changes:
+			if c.old.Rate != rate {
+				t.Fatalf("got %+v, want a change from %d", c, rate)
+			}
+			rate = c.new.Rate
+		case err := <-errs:
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no reload to rate 62 after the file changed; at %d", rate)
+		}
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch returned %v", err)
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	os.WriteFile(a, []byte("x"), 0644)
+	os.WriteFile(b, []byte("x"), 0644)
+	infoA, infoB := statFile(a), statFile(b)
+	os.WriteFile(a, []byte("xy"), 0644)
+	var tests = []struct {
+		name      string
+		last, now os.FileInfo
+		want      bool
+	}{
+		{"same", infoA, infoA, false},
+		{"gone", infoA, nil, false},
+		{"back", nil, infoA, true},
+		{"other file", infoA, infoB, true},
+		{"rewritten", infoA, statFile(a), true},
+	}
+	for _, tt := range tests {
+		if got := changedSince(tt.last, tt.now); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
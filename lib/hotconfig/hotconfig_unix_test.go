@@ -0,0 +1,45 @@
+//go:build unix
+
+package hotconfig
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWatchSIGHUP(t *testing.T) {
+	// With nothing listening for SIGHUP it would end the test binary, so
+	// listen before Watch does.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	m, path := newManager(t, "rate = 50\n", nil)
+	// Put off the reload the file change starts, so SIGHUP's comes first.
+	m.Debounce = time.Hour
+	changes := make(chan change, 1)
+	m.Subscribe(func(old, new *testConfig) error {
+		changes <- change{*old, *new}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Watch(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	write(t, path, "rate = 70\n")
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	select {
+	case c := <-changes:
+		if c.new.Rate != 70 {
+			t.Errorf("got %+v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after SIGHUP")
+	}
+}
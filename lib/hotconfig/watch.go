@@ -0,0 +1,35 @@
+package hotconfig
+
+import "os"
+
+// statFile returns what the file at path is now, or nil if there is none,
+// as for a moment while it is being replaced.
+func statFile(path string) os.FileInfo {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// changedSince reports whether now is a different file, or a different
+// version of the file, from last. A file that has gone hasn't changed yet:
+// it has when it comes back.
+func changedSince(last, now os.FileInfo) bool {
+	switch {
+	case now == nil:
+		return false
+	case last == nil:
+		return true
+	}
+	return !os.SameFile(last, now) || !last.ModTime().Equal(now.ModTime()) || last.Size() != now.Size()
+}
+
+// notify sends on a channel with room for one, without waiting: a change
+// already waiting to be seen covers this one too.
+func notify(c chan<- struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,50 @@
+package hotconfig
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// watchFile sends on changed whenever the file at path changes, until stop
+// is called, using inotify.
+func watchFile(path string, changed chan<- struct{}) (stop func(), err error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	// Watch the directory rather than the file. Editors and deploy tools
+	// replace a file by renaming a new one over it, and Kubernetes swaps a
+	// symlink; a watch on the file itself sees neither.
+	const mask = syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+		syscall.IN_MOVED_TO | syscall.IN_DELETE
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Dir(path), mask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+	// A non-blocking descriptor goes through the runtime's poller, so
+	// closing the file wakes the Read below.
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	last := statFile(path)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 4096)
+		for {
+			// Which events they were doesn't matter: anything happening in
+			// the directory is a reason to look at the file again.
+			if _, err := f.Read(buf); err != nil {
+				return
+			}
+			if now := statFile(path); changedSince(last, now) {
+				last = now
+				notify(changed)
+			}
+		}
+	}()
+	return func() {
+		f.Close()
+		<-done
+	}, nil
+}
@@ -0,0 +1,37 @@
+//go:build !linux
+
+package hotconfig
+
+import "time"
+
+// pollInterval is how often watchFile looks at the file where there is no
+// inotify.
+var pollInterval = time.Second
+
+// watchFile sends on changed whenever the file at path changes, until stop
+// is called, by polling its size and modification time.
+func watchFile(path string, changed chan<- struct{}) (stop func(), err error) {
+	last := statFile(path)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		t := time.NewTicker(pollInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-t.C:
+				if now := statFile(path); changedSince(last, now) {
+					last = now
+					notify(changed)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-done
+	}, nil
+}
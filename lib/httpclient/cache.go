@@ -0,0 +1,425 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores serialized responses by key. Implementations must be safe for
+// concurrent use; a cache that fails to write should simply drop the entry.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, b []byte)
+	Delete(key string)
+}
+
+// MemoryCache keeps entries in a map for the lifetime of the process.
+type MemoryCache struct {
+	mu sync.RWMutex
+	m  map[string][]byte
+}
+
+// NewMemoryCache returns an empty in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{m: make(map[string][]byte)}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	b, ok := c.m[key]
+	return b, ok
+}
+
+func (c *MemoryCache) Set(key string, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = b
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.m, key)
+}
+
+// DiskCache keeps one file per entry in Dir, named by the SHA256 of the key,
+// so cached responses survive between runs of a batch job.
+type DiskCache struct {
+	Dir string
+}
+
+// NewDiskCache creates dir if needed and returns a cache rooted there.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{Dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// Set writes to a temp file first and renames it into place, so a reader
+// never sees a half-written entry.
+func (c *DiskCache) Set(key string, b []byte) {
+	f, err := os.CreateTemp(c.Dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return
+	}
+	if err := f.Close(); err != nil {
+		return
+	}
+	os.Rename(f.Name(), c.path(key))
+}
+
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
+
+// CacheStats counts what a CacheTransport did with the requests it saw.
+type CacheStats struct {
+	Hits          int64 // served from cache without touching the network
+	Misses        int64 // fetched from upstream
+	Revalidations int64 // upstream answered 304 Not Modified to a conditional request
+	Stores        int64 // responses written to the cache
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d revalidated=%d stored=%d", s.Hits, s.Misses, s.Revalidations, s.Stores)
+}
+
+// Headers the transport keeps alongside a cached response.
+const (
+	// XCache is set on every response returned by a CacheTransport to HIT,
+	// MISS or REVALIDATED.
+	XCache = "X-Cache"
+
+	storedAtHeader = "X-Cache-Stored-At"
+	varyPrefix     = "X-Cache-Vary-"
+)
+
+// CacheTransport is an http.RoundTripper that answers GET requests from a
+// Cache when it can. It follows the private-cache rules of RFC 9111:
+// Cache-Control max-age / no-cache / no-store on responses and requests,
+// Expires, and revalidation with
+// If-None-Match and If-Modified-Since when an entry has gone stale.
+type CacheTransport struct {
+	// Transport performs the actual requests. http.DefaultTransport is used
+	// when nil.
+	Transport http.RoundTripper
+	Cache     Cache
+	// Now returns the current time; it is overridable so freshness can be
+	// tested without waiting.
+	Now func() time.Time
+
+	hits, misses, revalidations, stores atomic.Int64
+}
+
+// NewCacheTransport returns a CacheTransport over http.DefaultTransport.
+func NewCacheTransport(c Cache) *CacheTransport {
+	return &CacheTransport{Cache: c}
+}
+
+// Client returns an http.Client that sends its requests through t.
+func (t *CacheTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Stats returns a snapshot of the transport's counters.
+func (t *CacheTransport) Stats() CacheStats {
+	return CacheStats{
+		Hits:          t.hits.Load(),
+		Misses:        t.misses.Load(),
+		Revalidations: t.revalidations.Load(),
+		Stores:        t.stores.Load(),
+	}
+}
+
+func (t *CacheTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+func (t *CacheTransport) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+func cacheKey(req *http.Request) string {
+	return req.URL.String()
+}
+
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := cacheKey(req)
+	if req.Method != http.MethodGet {
+		resp, err := t.transport().RoundTrip(req)
+		// A successful unsafe request invalidates whatever we hold for the URL.
+		if err == nil && req.Method != http.MethodHead && resp.StatusCode < 400 {
+			t.Cache.Delete(key)
+		}
+		return resp, err
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		t.misses.Add(1)
+		return t.transport().RoundTrip(req)
+	}
+
+	cached := t.lookup(req, key)
+	if cached != nil {
+		_, reqNoCache := reqCC["no-cache"]
+		if !reqNoCache && t.fresh(cached, reqCC) {
+			t.hits.Add(1)
+			return finish(cached, "HIT"), nil
+		}
+		etag := cached.Header.Get("Etag")
+		lastMod := cached.Header.Get("Last-Modified")
+		if etag != "" || lastMod != "" {
+			cond := req.Clone(req.Context())
+			if etag != "" {
+				cond.Header.Set("If-None-Match", etag)
+			}
+			if lastMod != "" {
+				cond.Header.Set("If-Modified-Since", lastMod)
+			}
+			resp, err := t.transport().RoundTrip(cond)
+			if err != nil {
+				cached.Body.Close()
+				return nil, err
+			}
+			if resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				t.revalidations.Add(1)
+				// A 304 carries updated metadata for the stored response.
+				for name, values := range resp.Header {
+					cached.Header[name] = values
+				}
+				if err := t.store(req, key, cached); err != nil {
+					return nil, err
+				}
+				return finish(cached, "REVALIDATED"), nil
+			}
+			cached.Body.Close()
+			return t.miss(req, key, resp)
+		}
+		cached.Body.Close()
+	}
+
+	resp, err := t.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.miss(req, key, resp)
+}
+
+// miss records a response that came from upstream and stores it if its
+// headers allow it.
+func (t *CacheTransport) miss(req *http.Request, key string, resp *http.Response) (*http.Response, error) {
+	t.misses.Add(1)
+	if !cacheable(resp) {
+		resp.Header.Set(XCache, "MISS")
+		return resp, nil
+	}
+	if err := t.store(req, key, resp); err != nil {
+		return nil, err
+	}
+	return finish(resp, "MISS"), nil
+}
+
+// store serializes resp into the cache. The body is read fully and replaced
+// with an in-memory copy so the caller can still consume it.
+func (t *CacheTransport) store(req *http.Request, key string, resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	resp.Header.Set(storedAtHeader, strconv.FormatInt(t.now().UnixNano(), 10))
+	for _, name := range varyHeaders(resp.Header) {
+		resp.Header.Set(varyPrefix+name, req.Header.Get(name))
+	}
+	resp.ContentLength = int64(len(body))
+	resp.TransferEncoding = nil
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.Cache.Set(key, dump)
+	t.stores.Add(1)
+	return nil
+}
+
+// lookup returns the cached response for req, or nil when there is none or
+// the stored entry was selected by different Vary header values.
+func (t *CacheTransport) lookup(req *http.Request, key string) *http.Response {
+	b, ok := t.Cache.Get(key)
+	if !ok {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), req)
+	if err != nil {
+		t.Cache.Delete(key)
+		return nil
+	}
+	for _, name := range varyHeaders(resp.Header) {
+		if resp.Header.Get(varyPrefix+name) != req.Header.Get(name) {
+			resp.Body.Close()
+			return nil
+		}
+	}
+	return resp
+}
+
+// fresh reports whether the stored response can still be served without
+// asking upstream. A max-age in the request's Cache-Control, reqCC, caps
+// the age it accepts.
+func (t *CacheTransport) fresh(resp *http.Response, reqCC map[string]string) bool {
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+	storedAt, err := strconv.ParseInt(resp.Header.Get(storedAtHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+	received := time.Unix(0, storedAt)
+	age := t.now().Sub(received)
+	if s, err := strconv.Atoi(resp.Header.Get("Age")); err == nil {
+		age += time.Duration(s) * time.Second
+	}
+	if v, ok := reqCC["max-age"]; ok {
+		s, err := strconv.Atoi(v)
+		if err != nil || age > time.Duration(s)*time.Second {
+			return false
+		}
+	}
+	return age < lifetime(resp.Header, cc, received)
+}
+
+// lifetime is the freshness lifetime given by max-age, or failing that by
+// Expires relative to Date. Without a usable Date, Expires is taken relative
+// to when the response was received, as RFC 9111 section 4.2.1 says.
+func lifetime(h http.Header, cc map[string]string, received time.Time) time.Duration {
+	if v, ok := cc["max-age"]; ok {
+		if s, err := strconv.Atoi(v); err == nil {
+			return time.Duration(s) * time.Second
+		}
+		return 0
+	}
+	if exp := h.Get("Expires"); exp != "" {
+		expires, err := http.ParseTime(exp)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = received
+		}
+		return expires.Sub(date)
+	}
+	return 0
+}
+
+// cacheable reports whether resp may be stored at all. Responses without an
+// explicit lifetime are still kept when they carry a validator, since they
+// can then be revalidated cheaply.
+func cacheable(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusNotFound,
+		http.StatusMethodNotAllowed, http.StatusGone, http.StatusRequestURITooLong,
+		http.StatusNotImplemented:
+	default:
+		return false
+	}
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	for _, name := range varyHeaders(resp.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+	if _, ok := cc["max-age"]; ok {
+		return true
+	}
+	return resp.Header.Get("Expires") != "" || resp.Header.Get("Etag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+func finish(resp *http.Response, status string) *http.Response {
+	resp.Header.Del(storedAtHeader)
+	for name := range resp.Header {
+		if strings.HasPrefix(name, varyPrefix) {
+			resp.Header.Del(name)
+		}
+	}
+	resp.Header.Set(XCache, status)
+	return resp
+}
+
+// parseCacheControl splits a Cache-Control header into its directives, keyed
+// by lower-cased name with any quotes stripped from the value.
+func parseCacheControl(h http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, line := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(part, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return cc
+}
+
+func varyHeaders(h http.Header) []string {
+	var names []string
+	for _, line := range h.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
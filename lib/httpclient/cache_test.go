@@ -0,0 +1,272 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// origin is a test server whose handler the test sets, and which counts the
+// requests that reach it.
+type origin struct {
+	*httptest.Server
+	hits    atomic.Int64
+	handler http.HandlerFunc
+}
+
+func newOrigin(t *testing.T, h http.HandlerFunc) *origin {
+	o := &origin{handler: h}
+	o.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o.hits.Add(1)
+		o.handler(w, r)
+	}))
+	t.Cleanup(o.Close)
+	return o
+}
+
+// newTransport returns a transport over a memory cache whose clock the
+// returned pointer controls.
+func newTransport() (*CacheTransport, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	t := NewCacheTransport(NewMemoryCache())
+	t.Now = func() time.Time { return now }
+	return t, &now
+}
+
+// get fetches url and returns the body and the X-Cache header.
+func get(t *testing.T, c *http.Client, url string, header ...string) (string, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), resp.Header.Get(XCache)
+}
+
+func TestFreshness(t *testing.T) {
+	var tests = []struct {
+		name   string
+		header map[string]string
+		after  time.Duration
+		want   string
+	}{
+		{"max-age fresh", map[string]string{"Cache-Control": "max-age=60"}, 59 * time.Second, "HIT"},
+		{"max-age stale", map[string]string{"Cache-Control": "max-age=60"}, 60 * time.Second, "MISS"},
+		{"Age counts", map[string]string{"Cache-Control": "max-age=60", "Age": "50"}, 20 * time.Second, "MISS"},
+		{"Expires", map[string]string{"Date": "Mon, 01 Jan 2024 12:00:00 GMT", "Expires": "Mon, 01 Jan 2024 12:01:00 GMT"}, 30 * time.Second, "HIT"},
+		// An empty value removes the header; net/http adds Date otherwise.
+		{"Expires without Date", map[string]string{"Date": "", "Expires": "Mon, 01 Jan 2024 12:01:00 GMT"}, 30 * time.Second, "HIT"},
+		{"Expires without Date stale", map[string]string{"Date": "", "Expires": "Mon, 01 Jan 2024 12:01:00 GMT"}, 61 * time.Second, "MISS"},
+		{"no-cache", map[string]string{"Cache-Control": "max-age=60, no-cache"}, 0, "MISS"},
+		{"no-store", map[string]string{"Cache-Control": "no-store, max-age=60"}, 0, "MISS"},
+		{"no lifetime or validator", nil, 0, "MISS"},
+		{"Vary *", map[string]string{"Cache-Control": "max-age=60", "Vary": "*"}, 0, "MISS"},
+	}
+	for _, tt := range tests {
+		o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range tt.header {
+				if v == "" {
+					w.Header()[k] = nil
+					continue
+				}
+				w.Header().Set(k, v)
+			}
+			fmt.Fprint(w, "body")
+		})
+		tr, now := newTransport()
+		c := tr.Client()
+		if _, x := get(t, c, o.URL); x != "MISS" {
+			t.Errorf("%s: first request %s", tt.name, x)
+		}
+		*now = now.Add(tt.after)
+		if body, x := get(t, c, o.URL); x != tt.want || body != "body" {
+			t.Errorf("%s: got %s %q, want %s", tt.name, x, body, tt.want)
+		}
+	}
+}
+
+func TestRequestMaxAge(t *testing.T) {
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, "body")
+	})
+	tr, now := newTransport()
+	c := tr.Client()
+	get(t, c, o.URL)
+	*now = now.Add(20 * time.Second)
+	var tests = []struct {
+		cc   string
+		want string
+	}{
+		{"", "HIT"},
+		{"max-age=30", "HIT"},
+		{"max-age=10", "MISS"},
+		{"max-age=bogus", "MISS"},
+	}
+	for _, tt := range tests {
+		// A MISS stores a new copy; age it to 20 seconds like the first.
+		if _, x := get(t, c, o.URL, "Cache-Control", tt.cc); x != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.cc, x, tt.want)
+		}
+		if tt.want == "MISS" {
+			*now = now.Add(20 * time.Second)
+		}
+	}
+}
+
+func TestRevalidate(t *testing.T) {
+	version := "v1"
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + version + `"`
+		w.Header().Set("Cache-Control", "max-age=10")
+		w.Header().Set("Etag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, version)
+	})
+	tr, now := newTransport()
+	c := tr.Client()
+
+	steps := []struct {
+		after   time.Duration
+		version string
+		body, x string
+	}{
+		{0, "v1", "v1", "MISS"},
+		{5 * time.Second, "v1", "v1", "HIT"},
+		{10 * time.Second, "v1", "v1", "REVALIDATED"},
+		// The 304 renewed the entry.
+		{5 * time.Second, "v1", "v1", "HIT"},
+		{10 * time.Second, "v2", "v2", "MISS"},
+		{0, "v2", "v2", "HIT"},
+	}
+	for i, s := range steps {
+		*now = now.Add(s.after)
+		version = s.version
+		if body, x := get(t, c, o.URL); body != s.body || x != s.x {
+			t.Errorf("step %d: got %s %q, want %s %q", i, x, body, s.x, s.body)
+		}
+	}
+	if got := tr.Stats(); got.Hits != 3 || got.Misses != 2 || got.Revalidations != 1 {
+		t.Errorf("stats %v", got)
+	}
+	if o.hits.Load() != 3 {
+		t.Errorf("origin saw %d requests, want 3", o.hits.Load())
+	}
+}
+
+func TestRevalidateLastModified(t *testing.T) {
+	const lastMod = "Mon, 01 Jan 2024 00:00:00 GMT"
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", lastMod)
+		if r.Header.Get("If-Modified-Since") == lastMod {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "body")
+	})
+	tr, _ := newTransport()
+	c := tr.Client()
+	get(t, c, o.URL)
+	if body, x := get(t, c, o.URL); body != "body" || x != "REVALIDATED" {
+		t.Errorf("got %s %q", x, body)
+	}
+}
+
+func TestRequestNoStoreAndNoCache(t *testing.T) {
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Etag", `"x"`)
+		if r.Header.Get("If-None-Match") == `"x"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "body")
+	})
+	tr, _ := newTransport()
+	c := tr.Client()
+	if _, x := get(t, c, o.URL, "Cache-Control", "no-store"); x != "" {
+		t.Errorf("no-store request: X-Cache %q, want none", x)
+	}
+	if _, x := get(t, c, o.URL); x != "MISS" {
+		t.Errorf("a no-store request was stored: %s", x)
+	}
+	if _, x := get(t, c, o.URL, "Cache-Control", "no-cache"); x != "REVALIDATED" {
+		t.Errorf("no-cache request: %s, want REVALIDATED", x)
+	}
+}
+
+func TestVary(t *testing.T) {
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		fmt.Fprint(w, r.Header.Get("Accept-Language"))
+	})
+	tr, _ := newTransport()
+	c := tr.Client()
+	var tests = []struct {
+		lang, x string
+	}{
+		{"en", "MISS"},
+		{"en", "HIT"},
+		{"fr", "MISS"},
+		{"fr", "HIT"},
+		{"en", "MISS"}, // one entry per URL: fr replaced en
+	}
+	for i, tt := range tests {
+		if body, x := get(t, c, o.URL, "Accept-Language", tt.lang); body != tt.lang || x != tt.x {
+			t.Errorf("%d: %s got %s %q, want %s", i, tt.lang, x, body, tt.x)
+		}
+	}
+}
+
+func TestUnsafeMethodInvalidates(t *testing.T) {
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, "body")
+	})
+	tr, _ := newTransport()
+	c := tr.Client()
+	get(t, c, o.URL)
+	resp, err := c.Post(o.URL, "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, x := get(t, c, o.URL); x != "MISS" {
+		t.Errorf("after POST: %s, want MISS", x)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("k"); ok {
+		t.Error("hit in an empty cache")
+	}
+	c.Set("k", []byte("v"))
+	if b, ok := c.Get("k"); !ok || string(b) != "v" {
+		t.Errorf("got %q %v", b, ok)
+	}
+	c.Delete("k")
+	if _, ok := c.Get("k"); ok {
+		t.Error("hit after Delete")
+	}
+}
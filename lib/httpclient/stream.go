@@ -0,0 +1,278 @@
+// Package httpclient grows the HTTPClient.go example into reusable helpers.
+//
+// HTTPClient.go reads the first few lines of a body with a bufio.Scanner. The
+// helpers here decode a body piece by piece instead, so a large feed can be
+// processed without ever holding all of it in memory. Every decoder is an
+// iter.Seq2 that yields a value or an error, and every one of them stops as
+// soon as its context is cancelled.
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxLineSize is the longest line (or NDJSON record) the decoders accept.
+const MaxLineSize = 1 << 20
+
+// Get issues a GET request bound to ctx and returns the response once the
+// status line is in. Non-2xx responses are turned into an error so callers
+// can hand the body straight to one of the decoders. The caller must close
+// the body.
+func Get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("httpclient: GET %s: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+// Lines yields r one line at a time, without the trailing newline.
+func Lines(ctx context.Context, r io.Reader) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		defer closeOnDone(ctx, r)()
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+		for sc.Scan() {
+			if err := ctx.Err(); err != nil {
+				yield("", err)
+				return
+			}
+			if !yield(sc.Text(), nil) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			yield("", contextErr(ctx, err))
+		}
+	}
+}
+
+// NDJSON yields one decoded value per line of newline-delimited JSON. Blank
+// lines are skipped. A record that fails to decode ends the iteration with an
+// error naming its line number.
+func NDJSON[T any](ctx context.Context, r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		n := 0
+		for line, err := range Lines(ctx, r) {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			n++
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var v T
+			if err := json.Unmarshal([]byte(line), &v); err != nil {
+				yield(zero, fmt.Errorf("httpclient: ndjson line %d: %w", n, err))
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// JSONArray yields the elements of a top-level JSON array one by one, so a
+// response like `[{...}, {...}, ...]` never has to be decoded in one piece.
+func JSONArray[T any](ctx context.Context, r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		defer closeOnDone(ctx, r)()
+		dec := json.NewDecoder(r)
+		tok, err := dec.Token()
+		if err != nil {
+			yield(zero, contextErr(ctx, err))
+			return
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			yield(zero, fmt.Errorf("httpclient: expected JSON array, got %v", tok))
+			return
+		}
+		for i := 0; dec.More(); i++ {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			var v T
+			if err := dec.Decode(&v); err != nil {
+				yield(zero, fmt.Errorf("httpclient: array element %d: %w", i, contextErr(ctx, err)))
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			yield(zero, contextErr(ctx, err))
+		}
+	}
+}
+
+// Event is one Server-Sent Event as dispatched by a text/event-stream body.
+type Event struct {
+	ID    string // last event ID seen on the stream, carried over between events
+	Type  string // "message" unless the server set an event field
+	Data  string // data lines joined with "\n"
+	Retry time.Duration
+}
+
+// Events parses a text/event-stream body following the WHATWG rules: comment
+// lines are ignored, data lines are joined with newlines, and an event is only
+// dispatched on a blank line when it carries data.
+func Events(ctx context.Context, r io.Reader) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		defer closeOnDone(ctx, r)()
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+		sc.Split(scanEventLines)
+
+		var (
+			lastID string
+			retry  time.Duration
+			typ    string
+			data   strings.Builder
+			seen   bool
+		)
+		for sc.Scan() {
+			if err := ctx.Err(); err != nil {
+				yield(Event{}, err)
+				return
+			}
+			line := sc.Text()
+			if line == "" {
+				if seen {
+					ev := Event{ID: lastID, Type: typ, Data: strings.TrimSuffix(data.String(), "\n"), Retry: retry}
+					if ev.Type == "" {
+						ev.Type = "message"
+					}
+					if !yield(ev, nil) {
+						return
+					}
+				}
+				typ, seen = "", false
+				data.Reset()
+				continue
+			}
+			if strings.HasPrefix(line, ":") {
+				continue
+			}
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				typ = value
+			case "data":
+				data.WriteString(value)
+				data.WriteByte('\n')
+				seen = true
+			case "id":
+				if !strings.ContainsRune(value, 0) {
+					lastID = value
+				}
+			case "retry":
+				if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+					retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+		if err := sc.Err(); err != nil {
+			yield(Event{}, contextErr(ctx, err))
+		}
+	}
+}
+
+// scanEventLines is bufio.ScanLines extended to the three line endings the
+// event-stream format allows: "\r\n", "\n" and a lone "\r".
+func scanEventLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A "\r" at the very end of the buffer may be the first half of "\r\n".
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Chan runs seq on its own goroutine and delivers the values on a channel, for
+// callers that would rather select over a stream than range over it. The value
+// channel is closed when the sequence ends; the error channel then receives
+// the error that stopped it, if any, and is closed too.
+func Chan[T any](ctx context.Context, seq iter.Seq2[T, error]) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(out)
+		for v, err := range seq {
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return out, errc
+}
+
+// closeOnDone closes r when ctx is cancelled so a Read blocked on a slow
+// server returns straight away. The returned func stops the watch.
+func closeOnDone(ctx context.Context, r io.Reader) func() {
+	c, ok := r.(io.Closer)
+	if !ok {
+		return func() {}
+	}
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	return func() { stop() }
+}
+
+// contextErr prefers the context's error over whatever a read on a closed body
+// happened to report.
+func contextErr(ctx context.Context, err error) error {
+	if cerr := ctx.Err(); cerr != nil && !errors.Is(err, cerr) {
+		return cerr
+	}
+	return err
+}
@@ -0,0 +1,215 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLines(t *testing.T) {
+	var got []string
+	for line, err := range Lines(context.Background(), strings.NewReader("a\nb\r\n\nc")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, line)
+	}
+	if want := []string{"a", "b", "", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLinesTooLong(t *testing.T) {
+	var err error
+	for _, err = range Lines(context.Background(), strings.NewReader(strings.Repeat("x", MaxLineSize+1))) {
+	}
+	if err == nil {
+		t.Error("no error for a line over MaxLineSize")
+	}
+}
+
+type record struct {
+	N int `json:"n"`
+}
+
+func TestNDJSON(t *testing.T) {
+	var tests = []struct {
+		body string
+		want []record
+		err  string
+	}{
+		{`{"n":1}` + "\n\n" + `{"n":2}` + "\n", []record{{1}, {2}}, ""},
+		{`{"n":1}` + "\n" + `{"n":` + "\n", []record{{1}}, "ndjson line 2"},
+		{"", nil, ""},
+	}
+	for _, tt := range tests {
+		var got []record
+		var err error
+		for v, e := range NDJSON[record](context.Background(), strings.NewReader(tt.body)) {
+			if e != nil {
+				err = e
+				break
+			}
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.body, got, tt.want)
+		}
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%q: got error %v, want %q", tt.body, err, tt.err)
+		}
+	}
+}
+
+func TestJSONArray(t *testing.T) {
+	var tests = []struct {
+		body string
+		want []record
+		err  string
+	}{
+		{`[{"n":1}, {"n":2}]`, []record{{1}, {2}}, ""},
+		{`[]`, nil, ""},
+		{`{"n":1}`, nil, "expected JSON array"},
+		{`[{"n":1}, {"n":"x"}]`, []record{{1}}, "array element 1"},
+		{`[{"n":1}`, []record{{1}}, "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		var got []record
+		var err error
+		for v, e := range JSONArray[record](context.Background(), strings.NewReader(tt.body)) {
+			if e != nil {
+				err = e
+				break
+			}
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.body, got, tt.want)
+		}
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%q: got error %v, want %q", tt.body, err, tt.err)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	body := ": comment\r\n" +
+		"retry: 1500\r" +
+		"data: one\n\n" +
+		"event: update\nid: 7\ndata: two\ndata:three\n\n" +
+		"id: 8\n\n" + // no data: nothing dispatched, but the ID sticks
+		"data: four\n\n" +
+		"data: unterminated"
+	var got []Event
+	for ev, err := range Events(context.Background(), strings.NewReader(body)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ev)
+	}
+	want := []Event{
+		{Type: "message", Data: "one", Retry: 1500 * time.Millisecond},
+		{ID: "7", Type: "update", Data: "two\nthree", Retry: 1500 * time.Millisecond},
+		{ID: "8", Type: "message", Data: "four", Retry: 1500 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// stall serves the lines given, then holds the response open until the
+// request goes away.
+func stall(lines ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, l := range lines {
+			fmt.Fprintln(w, l)
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+}
+
+func TestCancelStopsBlockedRead(t *testing.T) {
+	srv := stall(`{"n":1}`)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp, err := Get(ctx, srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for v, e := range NDJSON[record](ctx, resp.Body) {
+			if e != nil {
+				err = e
+				break
+			}
+			if v.N == 1 {
+				cancel() // the next read blocks until this takes effect
+			}
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("iteration didn't stop after cancel")
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if _, err := Get(context.Background(), srv.Client(), srv.URL); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("got %v, want a 404 error", err)
+	}
+}
+
+func TestChan(t *testing.T) {
+	body := `{"n":1}` + "\n" + `{"n":2}` + "\n" + `oops` + "\n"
+	out, errc := Chan(context.Background(), NDJSON[record](context.Background(), strings.NewReader(body)))
+	var got []record
+	for v := range out {
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, []record{{1}, {2}}) {
+		t.Errorf("got %v", got)
+	}
+	if err := <-errc; err == nil || !strings.Contains(err.Error(), "ndjson line 3") {
+		t.Errorf("got %v, want the line 3 error", err)
+	}
+}
+
+func TestChanCancel(t *testing.T) {
+	srv := stall(`{"n":1}`, `{"n":2}`)
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := Get(ctx, srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	out, errc := Chan(ctx, NDJSON[record](ctx, resp.Body))
+	<-out // take one and walk away
+	cancel()
+	for range out {
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,96 @@
+package humantime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Units time.Duration stops at.
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// FormatDuration writes d like time.Duration.String, but with days and weeks
+// as well: "1w2d3h4m5s" rather than "219h4m5s". Parts that are zero are left
+// out, and durations under a second print as time.Duration does.
+func FormatDuration(d time.Duration) string {
+	if d > -time.Second && d < time.Second {
+		return d.String()
+	}
+	var b strings.Builder
+	// Work with the magnitude as a uint64 so math.MinInt64 doesn't overflow.
+	u := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		u = -u
+	}
+	for _, p := range []struct {
+		size uint64
+		unit string
+	}{
+		{uint64(Week), "w"},
+		{uint64(Day), "d"},
+		{uint64(time.Hour), "h"},
+		{uint64(time.Minute), "m"},
+	} {
+		if n := u / p.size; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, p.unit)
+			u %= p.size
+		}
+	}
+	if u > 0 {
+		b.WriteString(time.Duration(u).String())
+	}
+	return b.String()
+}
+
+var approxUnits = []struct {
+	size time.Duration
+	name string
+}{
+	{time.Second, "second"},
+	{time.Minute, "minute"},
+	{time.Hour, "hour"},
+	{Day, "day"},
+	{Week, "week"},
+	{30 * Day, "month"},
+	{365 * Day, "year"},
+}
+
+// Approx describes the size of d in its largest whole unit, rounded to the
+// nearest: "45 seconds", "1 minute", "3 hours", "2 weeks", "4 months".
+// Months are 30 days and years 365. The sign of d is ignored.
+func Approx(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	i := 0
+	for i+1 < len(approxUnits) && d >= approxUnits[i+1].size {
+		i++
+	}
+	u := approxUnits[i]
+	n := (d + u.size/2) / u.size
+	// 59.6 minutes rounds to "1 hour", not "60 minutes".
+	if i+1 < len(approxUnits) && n*u.size >= approxUnits[i+1].size {
+		u, n = approxUnits[i+1], 1
+	}
+	if n == 1 {
+		return "1 " + u.name
+	}
+	return fmt.Sprintf("%d %ss", n, u.name)
+}
+
+// Relative describes t as seen from now: "in 5 minutes", "3 days ago", or
+// "now" when they are less than a second apart.
+func Relative(t, now time.Time) string {
+	d := t.Sub(now)
+	switch {
+	case d > -time.Second && d < time.Second:
+		return "now"
+	case d > 0:
+		return "in " + Approx(d)
+	}
+	return Approx(d) + " ago"
+}
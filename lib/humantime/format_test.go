@@ -0,0 +1,55 @@
+package humantime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	var tests = []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{500 * time.Millisecond, "500ms"},
+		{90 * time.Second, "1m30s"},
+		{36 * time.Hour, "1d12h"},
+		{Week + 2*Day + 3*time.Hour + 4*time.Minute + 5*time.Second, "1w2d3h4m5s"},
+		{2 * Week, "2w"},
+		{Day + 1500*time.Millisecond, "1d1.5s"},
+		{-3 * Day, "-3d"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v): got %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestRelative(t *testing.T) {
+	now := time.Date(2024, 8, 20, 14, 16, 43, 0, time.UTC)
+	var tests = []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "now"},
+		{400 * time.Millisecond, "now"},
+		{5 * time.Minute, "in 5 minutes"},
+		{-5 * time.Minute, "5 minutes ago"},
+		{time.Minute, "in 1 minute"},
+		{45 * time.Second, "in 45 seconds"},
+		{90 * time.Second, "in 2 minutes"},
+		{59*time.Minute + 40*time.Second, "in 1 hour"},
+		{-26 * time.Hour, "1 day ago"},
+		{-3 * Day, "3 days ago"},
+		{10 * Day, "in 1 week"},
+		{29 * Day, "in 4 weeks"},
+		{-45 * Day, "2 months ago"},
+		{400 * Day, "in 1 year"},
+	}
+	for _, tt := range tests {
+		if got := Relative(now.Add(tt.d), now); got != tt.want {
+			t.Errorf("Relative(now%+v): got %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+// Package humantime parses and prints times the way people write them.
+//
+// TimeFormatting.go shows time.Parse needing the exact layout up front: it
+// fails on "8:41PM" given ANSIC. Parse tries the common layouts itself
+// (RFC 3339, RFC 1123, ANSIC, "2006-01-02" and friends), Unix seconds or
+// milliseconds as Epoch.go prints them, and relative expressions such as
+// "3 days ago", "in 2 hours", "tomorrow noon" or "next monday 9am". Relative
+// prints a time as "in 5 minutes" or "3 days ago", and FormatDuration and
+// ParseDuration extend time.Duration's notation with days and weeks.
+package humantime
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts with a zone or offset of their own, tried in order.
+var zonedLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.RubyDate,
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700 MST", // time.Time.String, without the monotonic reading
+	"2006-01-02 15:04:05 -0700",
+}
+
+// Layouts without a zone, read in the location of the reference time.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	time.ANSIC,
+	"Jan 2, 2006 3:04PM",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"January 2, 2006",
+	"January 2 2006",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Mon, 2 Jan 2006",
+	"Monday, January 2, 2006",
+}
+
+// yearlessLayouts are parsed in now's year. time.Stamp is what syslog
+// writes; fractional seconds after it are accepted as with any layout.
+var yearlessLayouts = []string{
+	time.Stamp,
+}
+
+// Parse parses s as a time. Relative expressions are taken from now, and
+// inputs without a zone are in now's location. It accepts:
+//
+//   - the layouts of RFC 3339, RFC 1123, RFC 850, RFC 822, ANSIC, UnixDate,
+//     RubyDate and time.Time.String
+//   - dates like "2006-01-02", "2006/01/02", "Jan 2, 2006" and "2 January
+//     2006", optionally followed by a time
+//   - syslog stamps, "Jan  2 15:04:05", in now's year
+//   - Unix times: "@1724081884" is always seconds; a bare number is seconds,
+//     milliseconds, microseconds or nanoseconds depending on its size, and
+//     one with a fraction, "1724081884.716", is seconds
+//   - "now", "today", "tomorrow", "yesterday", a weekday, or "this", "next"
+//     or "last" and a weekday, optionally followed by a time of day such as
+//     "9am", "9:30 pm", "21:00", "noon" or "midnight" ("at" is optional)
+//   - a time of day alone, meaning today
+//   - "3 days ago", "in 2 hours", "an hour from now", "in 1h30m", "2w ago"
+//   - "next week", "last month", "next year"
+//
+// Days, weeks, months and years in relative expressions are calendar ones:
+// "tomorrow 9am" is 9am even across a daylight saving change.
+func Parse(s string, now time.Time) (time.Time, error) {
+	in := strings.TrimSpace(s)
+	if in == "" {
+		return time.Time{}, fmt.Errorf("humantime: empty time")
+	}
+	if t, ok := parseUnix(in, now.Location()); ok {
+		return t, nil
+	}
+	for _, layout := range zonedLayouts {
+		if t, err := time.Parse(layout, in); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, in, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range yearlessLayouts {
+		p, err := time.ParseInLocation(layout, in, now.Location())
+		if err != nil {
+			continue
+		}
+		t := time.Date(now.Year(), p.Month(), p.Day(), p.Hour(), p.Minute(), p.Second(), p.Nanosecond(), p.Location())
+		// Year 0 is a leap year, so "Feb 29" parses; outside a leap year
+		// time.Date would roll it over to Mar 1 instead of failing.
+		if t.Day() != p.Day() {
+			return time.Time{}, fmt.Errorf("humantime: %q: day out of range for %d", s, now.Year())
+		}
+		return t, nil
+	}
+	if t, ok := parseRelative(in, now); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("humantime: can't parse %q as a time", s)
+}
+
+var unixRe = regexp.MustCompile(`^(@?)(-?\d+)(?:\.(\d{1,9}))?$`)
+
+// parseUnix reads a Unix timestamp, guessing its unit from its size when
+// it has no "@" or fraction to say it is seconds: values below 1e11 are
+// seconds (up to the year 5138), then milliseconds, microseconds and
+// nanoseconds in steps of 1000.
+func parseUnix(s string, loc *time.Location) (time.Time, bool) {
+	m := unixRe.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if m[1] == "@" || m[3] != "" {
+		frac := 0
+		if m[3] != "" {
+			frac, _ = strconv.Atoi(m[3] + strings.Repeat("0", 9-len(m[3])))
+			if n < 0 || m[2] == "-0" {
+				frac = -frac
+			}
+		}
+		return time.Unix(n, int64(frac)).In(loc), true
+	}
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(n, 0).In(loc), true
+	case abs < 1e14:
+		return time.UnixMilli(n).In(loc), true
+	case abs < 1e17:
+		return time.UnixMicro(n).In(loc), true
+	}
+	return time.Unix(0, n).In(loc), true
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday, "friday": time.Friday,
+	"saturday": time.Saturday,
+	"sun":      time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wed": time.Wednesday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday,
+}
+
+// calendarUnit is a relative unit counted on the calendar, by AddDate.
+type calendarUnit struct{ years, months, days int }
+
+var units = map[string]any{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "microsecond": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hour": time.Hour,
+	"d": calendarUnit{days: 1}, "day": calendarUnit{days: 1},
+	"w": calendarUnit{days: 7}, "wk": calendarUnit{days: 7}, "week": calendarUnit{days: 7},
+	"fortnight": calendarUnit{days: 14},
+	"mo":        calendarUnit{months: 1}, "month": calendarUnit{months: 1},
+	"y": calendarUnit{years: 1}, "yr": calendarUnit{years: 1}, "year": calendarUnit{years: 1},
+}
+
+// unit looks up a unit name, singular or plural.
+func unit(name string) (any, bool) {
+	if u, ok := units[name]; ok {
+		return u, true
+	}
+	if strings.HasSuffix(name, "s") {
+		u, ok := units[strings.TrimSuffix(name, "s")]
+		return u, ok
+	}
+	return nil, false
+}
+
+// shift moves t by n of unit u.
+func shift(t time.Time, n int, u any) time.Time {
+	switch u := u.(type) {
+	case time.Duration:
+		return t.Add(time.Duration(n) * u)
+	case calendarUnit:
+		return t.AddDate(n*u.years, n*u.months, n*u.days)
+	}
+	return t
+}
+
+func parseRelative(s string, now time.Time) (time.Time, bool) {
+	var words []string
+	for _, w := range strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " "))) {
+		if w != "at" {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+
+	// "in 3 days", "3 days ago", "3 days from now"
+	switch {
+	case words[0] == "in":
+		return offset(now, words[1:], 1)
+	case words[len(words)-1] == "ago":
+		return offset(now, words[:len(words)-1], -1)
+	case len(words) > 2 && words[len(words)-2] == "from" && words[len(words)-1] == "now":
+		return offset(now, words[:len(words)-2], 1)
+	}
+
+	if words[0] == "now" && len(words) == 1 {
+		return now, true
+	}
+
+	// "next week", "last month"
+	if len(words) == 2 && (words[0] == "next" || words[0] == "last") {
+		if u, ok := units[words[1]]; ok {
+			if _, ok := u.(calendarUnit); ok {
+				return shift(now, direction(words[0]), u), true
+			}
+		}
+	}
+
+	// A day, then an optional time of day.
+	day, rest, ok := parseDay(words, now)
+	if !ok {
+		day, rest = midnight(now), words
+	}
+	if len(rest) == 0 {
+		return day, ok
+	}
+	h, m, sec, tok := parseClock(strings.Join(rest, ""))
+	if !tok {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, sec, 0, now.Location()), true
+}
+
+func direction(word string) int {
+	if word == "last" {
+		return -1
+	}
+	return 1
+}
+
+// offset reads "<n> <unit>", "a day", "an hour" or a duration like "1h30m"
+// or "2d", and moves now by it in direction sign.
+func offset(now time.Time, words []string, sign int) (time.Time, bool) {
+	switch len(words) {
+	case 1:
+		d, err := ParseDuration(words[0])
+		if err != nil {
+			return time.Time{}, false
+		}
+		// Whole days and weeks go by the calendar, like "3 days" does;
+		// "24h" is 24 hours.
+		if calendarRe.MatchString(words[0]) {
+			return now.AddDate(0, 0, sign*int(d/Day)), true
+		}
+		return now.Add(time.Duration(sign) * d), true
+	case 2:
+		var n int
+		switch words[0] {
+		case "a", "an", "one":
+			n = 1
+		default:
+			var err error
+			if n, err = strconv.Atoi(words[0]); err != nil || n < 0 {
+				return time.Time{}, false
+			}
+		}
+		u, ok := unit(words[1])
+		if !ok {
+			return time.Time{}, false
+		}
+		return shift(now, sign*n, u), true
+	}
+	return time.Time{}, false
+}
+
+// parseDay reads a day from the start of words and returns its midnight and
+// the words after it.
+func parseDay(words []string, now time.Time) (time.Time, []string, bool) {
+	today := midnight(now)
+	switch words[0] {
+	case "today", "tonight":
+		return today, words[1:], true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), words[1:], true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), words[1:], true
+	}
+
+	which, name := "this", words[0]
+	switch words[0] {
+	case "this", "next", "last":
+		if len(words) < 2 {
+			return time.Time{}, nil, false
+		}
+		which, name = words[0], words[1]
+		words = words[1:]
+	}
+	wd, ok := weekdays[name]
+	if !ok {
+		return time.Time{}, nil, false
+	}
+	diff := int(wd - now.Weekday())
+	switch which {
+	case "this":
+		// The coming one, today included.
+		diff = (diff + 7) % 7
+	case "next":
+		// The coming one, today excluded.
+		diff = (diff+6)%7 + 1
+	case "last":
+		diff = -((-diff+6)%7 + 1)
+	}
+	return today.AddDate(0, 0, diff), words[1:], true
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+var clockRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?(am|pm|a\.m\.|p\.m\.)?$`)
+
+// parseClock reads a time of day with the spaces taken out: "9am", "9:30pm",
+// "21:00", "21:00:05", "noon" or "midnight".
+func parseClock(s string) (h, m, sec int, ok bool) {
+	switch s {
+	case "noon", "midday":
+		return 12, 0, 0, true
+	case "midnight":
+		return 0, 0, 0, true
+	}
+	g := clockRe.FindStringSubmatch(s)
+	if g == nil || g[2] == "" && g[4] == "" {
+		// A bare number is not a time of day.
+		return 0, 0, 0, false
+	}
+	h, _ = strconv.Atoi(g[1])
+	m, _ = strconv.Atoi(g[2])
+	sec, _ = strconv.Atoi(g[3])
+	if m > 59 || sec > 59 {
+		return 0, 0, 0, false
+	}
+	switch strings.ReplaceAll(g[4], ".", "") {
+	case "":
+		if h > 23 {
+			return 0, 0, 0, false
+		}
+	case "am":
+		if h < 1 || h > 12 {
+			return 0, 0, 0, false
+		}
+		h %= 12
+	case "pm":
+		if h < 1 || h > 12 {
+			return 0, 0, 0, false
+		}
+		h = h%12 + 12
+	}
+	return h, m, sec, true
+}
+
+var (
+	calendarRe    = regexp.MustCompile(`^(\d+[dw])+$`)
+	durationRe    = regexp.MustCompile(`^(\d+\.?\d*|\.\d+)(ns|us|µs|ms|s|m|h|d|w)`)
+	durationUnits = map[string]time.Duration{
+		"ns": time.Nanosecond, "us": time.Microsecond, "µs": time.Microsecond,
+		"ms": time.Millisecond, "s": time.Second, "m": time.Minute, "h": time.Hour,
+		"d": Day, "w": Week,
+	}
+)
+
+// ParseDuration is time.ParseDuration with two more units: "d" for 24 hours
+// and "w" for 7 days, as in "1w2d" or "1.5d". Spaces between the parts are
+// allowed, so FormatDuration's output parses back.
+func ParseDuration(s string) (time.Duration, error) {
+	orig := s
+	s = strings.ReplaceAll(s, " ", "")
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, fmt.Errorf("humantime: invalid duration %q", orig)
+	}
+	var total float64
+	for s != "" {
+		m := durationRe.FindStringSubmatch(s)
+		if m == nil {
+			return 0, fmt.Errorf("humantime: invalid duration %q", orig)
+		}
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("humantime: invalid duration %q", orig)
+		}
+		total += v * float64(durationUnits[m[2]])
+		s = s[len(m[0]):]
+	}
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("humantime: duration %q out of range", orig)
+	}
+	d := time.Duration(math.Round(total))
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
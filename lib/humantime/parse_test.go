@@ -0,0 +1,231 @@
+package humantime
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoad(t testing.TB, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	ist := mustLoad(t, "Asia/Kolkata")
+	// The moment TimeFormatting.go was run: a Tuesday afternoon in India.
+	now := time.Date(2024, 8, 20, 14, 16, 43, 646467000, ist)
+	at := func(y int, m time.Month, d, h, min, s int) time.Time {
+		return time.Date(y, m, d, h, min, s, 0, ist)
+	}
+
+	var tests = []struct {
+		in   string
+		want time.Time
+	}{
+		// Layouts with zones.
+		{"2012-11-01T22:08:41+00:00", time.Date(2012, 11, 1, 22, 8, 41, 0, time.UTC)},
+		{"2024-08-20T14:16:43.646467+05:30", now},
+		{"Tue, 20 Aug 2024 08:46:43 GMT", time.Date(2024, 8, 20, 8, 46, 43, 0, time.UTC)},
+		{"Tue, 20 Aug 2024 14:16:43 +0530", at(2024, 8, 20, 14, 16, 43)},
+		{"2024-08-19 21:06:27 +0530 IST", time.Date(2024, 8, 19, 15, 36, 27, 0, time.UTC)},
+		// Layouts without zones are in now's location.
+		{"2024-08-20", at(2024, 8, 20, 0, 0, 0)},
+		{"2024-08-20 09:30", at(2024, 8, 20, 9, 30, 0)},
+		{"2024-08-20T09:30:15", at(2024, 8, 20, 9, 30, 15)},
+		{"2024/08/20", at(2024, 8, 20, 0, 0, 0)},
+		{"Tue Aug 20 14:16:43 2024", at(2024, 8, 20, 14, 16, 43)},
+		{"Aug 20, 2024", at(2024, 8, 20, 0, 0, 0)},
+		{"Aug 20, 2024 8:41PM", at(2024, 8, 20, 20, 41, 0)},
+		{"20 August 2024", at(2024, 8, 20, 0, 0, 0)},
+		// Syslog stamps have no year and take now's.
+		{"Aug 20 14:16:43", at(2024, 8, 20, 14, 16, 43)},
+		{"Jan  2 03:04:05", at(2024, 1, 2, 3, 4, 5)},
+		{"Dec 31 23:59:59.5", at(2024, 12, 31, 23, 59, 59).Add(500 * time.Millisecond)},
+		{"Feb 29 12:00:00", at(2024, 2, 29, 12, 0, 0)},
+		// Epoch.go's numbers.
+		{"1724081884", time.Unix(1724081884, 0)},
+		{"1724081884716", time.UnixMilli(1724081884716)},
+		{"1724081884716818", time.UnixMicro(1724081884716818)},
+		{"1724081884716818000", time.Unix(0, 1724081884716818000)},
+		{"1724081884.716", time.Unix(1724081884, 716000000)},
+		{"@1724081884716", time.Unix(1724081884716, 0)},
+		// Times of day are today.
+		{"8:41PM", at(2024, 8, 20, 20, 41, 0)},
+		{"8:41 pm", at(2024, 8, 20, 20, 41, 0)},
+		{"9am", at(2024, 8, 20, 9, 0, 0)},
+		{"12am", at(2024, 8, 20, 0, 0, 0)},
+		{"12pm", at(2024, 8, 20, 12, 0, 0)},
+		{"21:05", at(2024, 8, 20, 21, 5, 0)},
+		{"noon", at(2024, 8, 20, 12, 0, 0)},
+		// Days.
+		{"now", now},
+		{"today", at(2024, 8, 20, 0, 0, 0)},
+		{"Tomorrow", at(2024, 8, 21, 0, 0, 0)},
+		{"tomorrow 9am", at(2024, 8, 21, 9, 0, 0)},
+		{"yesterday at 17:30", at(2024, 8, 19, 17, 30, 0)},
+		{"tuesday", at(2024, 8, 20, 0, 0, 0)},
+		{"this friday", at(2024, 8, 23, 0, 0, 0)},
+		{"monday", at(2024, 8, 26, 0, 0, 0)},
+		{"next monday 9am", at(2024, 8, 26, 9, 0, 0)},
+		{"next tuesday", at(2024, 8, 27, 0, 0, 0)},
+		{"next wed at 10:15 am", at(2024, 8, 21, 10, 15, 0)},
+		{"last tuesday", at(2024, 8, 13, 0, 0, 0)},
+		{"last sunday noon", at(2024, 8, 18, 12, 0, 0)},
+		// Offsets.
+		{"3 days ago", at(2024, 8, 17, 14, 16, 43).Add(646467 * time.Microsecond)},
+		{"in 5 minutes", now.Add(5 * time.Minute)},
+		{"an hour ago", now.Add(-time.Hour)},
+		{"2 weeks from now", now.AddDate(0, 0, 14)},
+		{"in 1h30m", now.Add(90 * time.Minute)},
+		{"2d ago", now.AddDate(0, 0, -2)},
+		{"in 1 month", now.AddDate(0, 1, 0)},
+		{"next week", now.AddDate(0, 0, 7)},
+		{"last year", now.AddDate(-1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	now := time.Date(2024, 8, 20, 14, 16, 43, 0, time.UTC)
+	for _, in := range []string{
+		"",
+		"soon",
+		"13pm",
+		"25:00",
+		"9:60",
+		"next",
+		"next fooday",
+		"in a while",
+		"3 parsecs ago",
+		"tomorrow at teatime",
+		"2024-13-01",
+		"Feb 30 12:00:00",
+	} {
+		if got, err := Parse(in, now); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", in, got)
+		}
+	}
+}
+
+func TestParseYearlessOutsideLeapYear(t *testing.T) {
+	now := time.Date(2023, 8, 20, 14, 16, 43, 0, time.UTC)
+	got, err := Parse("Feb 28 12:00:00", now)
+	if want := time.Date(2023, 2, 28, 12, 0, 0, 0, time.UTC); err != nil || !got.Equal(want) {
+		t.Errorf("Feb 28: got %v, %v, want %v", got, err, want)
+	}
+	if got, err := Parse("Feb 29 12:00:00", now); err == nil {
+		t.Errorf("Feb 29 in 2023: got %v, want an error", got)
+	}
+}
+
+// TestParseAcrossDST checks that "tomorrow 9am" and "in 1 day" keep the wall
+// clock when New York springs forward overnight.
+func TestParseAcrossDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	now := time.Date(2024, 3, 9, 9, 0, 0, 0, ny)
+	for _, in := range []string{"tomorrow 9am", "in 1 day", "in 1d"} {
+		got, err := Parse(in, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := time.Date(2024, 3, 10, 9, 0, 0, 0, ny); !got.Equal(want) {
+			t.Errorf("%s: got %v, want %v", in, got, want)
+		}
+		if got.Sub(now) != 23*time.Hour {
+			t.Errorf("%s: %v after now, want 23h", in, got.Sub(now))
+		}
+	}
+	if got, _ := Parse("in 24h", now); got.Sub(now) != 24*time.Hour {
+		t.Errorf("in 24h: %v after now", got.Sub(now))
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	var tests = []struct {
+		in   string
+		want time.Duration
+	}{
+		{"0", 0},
+		{"1s", time.Second},
+		{"1h30m", 90 * time.Minute},
+		{"1d", Day},
+		{"1.5d", 36 * time.Hour},
+		{"1w2d3h4m5s", Week + 2*Day + 3*time.Hour + 4*time.Minute + 5*time.Second},
+		{"1w 2d", 9 * Day},
+		{"-2w", -2 * Week},
+		{"500ms", 500 * time.Millisecond},
+		{"1m500ms", time.Minute + 500*time.Millisecond},
+		{"1µs", time.Microsecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDuration(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	for _, in := range []string{"", "-", "d", "1x", "1d2", "100000000w"} {
+		if _, err := ParseDuration(in); err == nil {
+			t.Errorf("ParseDuration(%q) succeeded", in)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{"2024-08-20", "next monday 9am", "3 days ago", "1724081884716", "in 1w2d"} {
+		f.Add(s)
+	}
+	now := time.Date(2024, 8, 20, 14, 16, 43, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, s string) {
+		Parse(s, now) // must not panic
+	})
+}
+
+func FuzzDurationRoundTrip(f *testing.F) {
+	for _, d := range []int64{0, 1, 999999999, int64(time.Second), int64(Week + 3*time.Hour + 7), -int64(Day)} {
+		f.Add(d)
+	}
+	f.Fuzz(func(t *testing.T, n int64) {
+		d := time.Duration(n)
+		s := FormatDuration(d)
+		got, err := ParseDuration(s)
+		if err != nil {
+			t.Fatalf("ParseDuration(%q): %v", s, err)
+		}
+		// Parsing goes through a float64, which holds 53 bits exactly.
+		if diff := got - d; diff > 1<<11 || diff < -1<<11 {
+			t.Errorf("FormatDuration(%d) = %q, parsed back as %d", n, s, got)
+		}
+	})
+}
+
+func BenchmarkParse(b *testing.B) {
+	now := time.Date(2024, 8, 20, 14, 16, 43, 0, time.UTC)
+	for _, in := range []string{"2024-08-20T14:16:43Z", "Aug 20, 2024", "next monday 9am"} {
+		b.Run(in, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Parse(in, now)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+// Package intutils holds the IntMin function that TestingAndBenchmarking.go
+// tests. That file declares its tests inside package main, where go test
+// never looks for them; here they sit in intutils_test.go, as the example's
+// own comments recommend.
+package intutils
+
+// IntMin returns the smaller of a and b.
+func IntMin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
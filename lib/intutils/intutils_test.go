@@ -0,0 +1,66 @@
+package intutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestIntMinBasic(t *testing.T) {
+	ans := IntMin(2, -2)
+	if ans != -2 {
+		t.Errorf("IntMin(2, -2) = %d; want -2", ans)
+	}
+}
+
+func TestIntMinTableDriven(t *testing.T) {
+	var tests = []struct {
+		a, b int
+		want int
+	}{
+		{0, 1, 0},
+		{1, 0, 0},
+		{2, -2, -2},
+		{0, -1, -1},
+		{-1, 0, -1},
+		{5, 5, 5},
+		{math.MinInt, math.MaxInt, math.MinInt},
+		{math.MaxInt, math.MinInt, math.MinInt},
+	}
+
+	for _, tt := range tests {
+		testname := fmt.Sprintf("%d,%d", tt.a, tt.b)
+		t.Run(testname, func(t *testing.T) {
+			ans := IntMin(tt.a, tt.b)
+			if ans != tt.want {
+				t.Errorf("got %d, want %d", ans, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzIntMin checks the properties any minimum must have: the result is one
+// of the inputs and no larger than either.
+func FuzzIntMin(f *testing.F) {
+	f.Add(0, 1)
+	f.Add(2, -2)
+	f.Add(math.MinInt, math.MaxInt)
+	f.Fuzz(func(t *testing.T, a, b int) {
+		m := IntMin(a, b)
+		if m != a && m != b {
+			t.Errorf("IntMin(%d, %d) = %d, which is neither input", a, b, m)
+		}
+		if m > a || m > b {
+			t.Errorf("IntMin(%d, %d) = %d, which is not the minimum", a, b, m)
+		}
+		if IntMin(b, a) != m {
+			t.Errorf("IntMin(%d, %d) != IntMin(%d, %d)", a, b, b, a)
+		}
+	})
+}
+
+func BenchmarkIntMin(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		IntMin(1, 2)
+	}
+}
@@ -0,0 +1,197 @@
+package notes
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This is a deliberately small Markdown renderer: the notes only use
+// headings, fenced code, paragraphs, lists, block quotes and a little inline
+// markup, and pulling in a full CommonMark library for that isn't worth a
+// dependency.
+
+var (
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listItemRe = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	ruleRe     = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	linkRe     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldRe     = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicRe   = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*`)
+)
+
+// RenderMarkdown converts Markdown to an HTML fragment.
+func RenderMarkdown(src string) string {
+	var out strings.Builder
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var para []string
+
+	flush := func() {
+		if len(para) > 0 {
+			out.WriteString("<p>" + renderInline(strings.Join(para, "\n")) + "</p>\n")
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			class := ""
+			if lang != "" {
+				class = ` class="language-` + html.EscapeString(lang) + `"`
+			}
+			out.WriteString("<pre><code" + class + ">" + html.EscapeString(dedent(code)) + "</code></pre>\n")
+
+		case trimmed == "":
+			flush()
+
+		case headingRe.MatchString(trimmed):
+			flush()
+			m := headingRe.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(m[1]))
+			out.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+
+		case ruleRe.MatchString(line):
+			flush()
+			out.WriteString("<hr>\n")
+
+		case listItemRe.MatchString(line):
+			flush()
+			i = renderList(&out, lines, i) - 1
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+			}
+			i--
+			out.WriteString("<blockquote>\n" + RenderMarkdown(strings.Join(quote, "\n")) + "</blockquote>\n")
+
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// renderList renders the list starting at lines[start] and returns the index
+// of the first line after it. Items indented deeper than the first become a
+// nested list.
+func renderList(out *strings.Builder, lines []string, start int) int {
+	first := listItemRe.FindStringSubmatch(lines[start])
+	indent := len(expandTabs(first[1]))
+	tag := "ul"
+	if first[2] != "-" && first[2] != "*" && first[2] != "+" {
+		tag = "ol"
+	}
+	out.WriteString("<" + tag + ">\n")
+
+	i := start
+	open := false
+	for i < len(lines) {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			// A blank line ends the list unless another item follows.
+			if i+1 < len(lines) && listItemRe.MatchString(lines[i+1]) {
+				i++
+				continue
+			}
+			break
+		}
+		m := listItemRe.FindStringSubmatch(line)
+		if m == nil {
+			if !open || strings.HasPrefix(strings.TrimSpace(line), "```") {
+				break
+			}
+			// A lazy continuation of the current item.
+			out.WriteString(" " + renderInline(strings.TrimSpace(line)))
+			i++
+			continue
+		}
+		depth := len(expandTabs(m[1]))
+		if depth < indent {
+			break
+		}
+		if depth > indent {
+			i = renderList(out, lines, i)
+			continue
+		}
+		if open {
+			out.WriteString("</li>\n")
+		}
+		out.WriteString("<li>" + renderInline(m[3]))
+		open = true
+		i++
+	}
+	if open {
+		out.WriteString("</li>\n")
+	}
+	out.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// renderInline escapes text and applies code spans, links, bold and italics.
+// Code spans are cut out first so nothing inside them is touched.
+func renderInline(s string) string {
+	var out strings.Builder
+	parts := strings.Split(s, "`")
+	for i, part := range parts {
+		// Odd-numbered parts sit between backticks, unless the last backtick
+		// is unmatched.
+		if i%2 == 1 && i < len(parts)-1 {
+			out.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			out.WriteString("`")
+		}
+		t := html.EscapeString(part)
+		t = linkRe.ReplaceAllString(t, `<a href="$2">$1</a>`)
+		t = boldRe.ReplaceAllString(t, "<strong>$1</strong>")
+		t = italicRe.ReplaceAllString(t, "$1<em>$2</em>")
+		out.WriteString(t)
+	}
+	return out.String()
+}
+
+// dedent removes the indentation shared by every non-blank line, which the
+// notes' code blocks often carry over from the file they were copied from.
+func dedent(lines []string) string {
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		lead := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix, first = lead, false
+			continue
+		}
+		for !strings.HasPrefix(lead, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = strings.TrimPrefix(l, prefix)
+	}
+	return strings.Join(out, "\n")
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", "    ")
+}
@@ -0,0 +1,31 @@
+package notes
+
+import "testing"
+
+func TestRenderMarkdown(t *testing.T) {
+	var tests = []struct {
+		name, in, want string
+	}{
+		{"paragraphs", "one\ntwo\n\nthree", "<p>one\ntwo</p>\n<p>three</p>\n"},
+		{"crlf", "one\r\n\r\ntwo", "<p>one</p>\n<p>two</p>\n"},
+		{"heading", "## Maps ##", "<h2>Maps</h2>\n"},
+		{"escape", "a < b && c", "<p>a &lt; b &amp;&amp; c</p>\n"},
+		{"inline", "**bold**, *it* and `x * y` [go](https://go.dev)",
+			`<p><strong>bold</strong>, <em>it</em> and <code>x * y</code> <a href="https://go.dev">go</a></p>` + "\n"},
+		{"code span keeps markup", "`**not bold** <b>`", "<p><code>**not bold** &lt;b&gt;</code></p>\n"},
+		{"unmatched backtick", "a ` b", "<p>a ` b</p>\n"},
+		{"fence", "```go\n\tif a < b {\n\t\treturn\n\t}\n```", "<pre><code class=\"language-go\">if a &lt; b {\n\treturn\n}</code></pre>\n"},
+		{"unclosed fence", "```\ncode", "<pre><code>code</code></pre>\n"},
+		{"rule", "a\n\n---\n\nb", "<p>a</p>\n<hr>\n<p>b</p>\n"},
+		{"list", "- one\n- two\n  more\n- three", "<ul>\n<li>one</li>\n<li>two more</li>\n<li>three</li>\n</ul>\n"},
+		{"ordered", "1. one\n2) two", "<ol>\n<li>one</li>\n<li>two</li>\n</ol>\n"},
+		{"nested", "- a\n    - b\n- c", "<ul>\n<li>a<ul>\n<li>b</li>\n</ul>\n</li>\n<li>c</li>\n</ul>\n"},
+		{"loose list", "- a\n\n- b\n\nafter", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n<p>after</p>\n"},
+		{"quote", "> **note**\n> more", "<blockquote>\n<p><strong>note</strong>\nmore</p>\n</blockquote>\n"},
+	}
+	for _, tt := range tests {
+		if got := RenderMarkdown(tt.in); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
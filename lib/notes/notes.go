@@ -0,0 +1,279 @@
+// Package notes turns the Learning*.md notes into a cross-linked HTML site.
+//
+// Each notes file is a run of numbered "### N) Topic:" sections. Load splits
+// them into Topics, works out which example file (WorkerPool.go, HTTPClient.go,
+// ...) every topic is about, and Build renders an index with a table of
+// contents, one page per topic and one page per example.
+package notes
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Topic is one "### N) Title:" section.
+type Topic struct {
+	Number   int
+	Title    string
+	Slug     string   // unique, used for the page's file name
+	Source   string   // notes file it came from, e.g. "Learning3.md"
+	Body     string   // Markdown after the heading
+	Examples []string // example files the topic is about, e.g. "Mutexes.go"
+}
+
+// Source is one notes file.
+type Source struct {
+	Name   string
+	Intro  string // Markdown before the first topic
+	Topics []*Topic
+}
+
+// Notes is everything Load found.
+type Notes struct {
+	Sources  []*Source
+	Topics   []*Topic // in reading order across all sources
+	Examples []string // every .go file next to the notes
+}
+
+// The notes aren't perfectly consistent: "### 6} Switch:", "### 18) Structs;"
+// and "### 76) HTTP server" all have to be recognised.
+var topicRe = regexp.MustCompile(`^###\s*(\d+)\s*[)}\].]\s*(.*?)\s*[:;]?\s*$`)
+
+// Load reads Learning.md, Learning2.md, ... and the example .go files from the
+// top level of fsys. Copies of the notes in subdirectories, like folder/, are
+// not read.
+func Load(fsys fs.FS) (*Notes, error) {
+	names, err := fs.Glob(fsys, "Learning*.md")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("notes: no Learning*.md files found")
+	}
+	slices.SortFunc(names, func(a, b string) int { return sourceIndex(a) - sourceIndex(b) })
+
+	examples, err := fs.Glob(fsys, "*.go")
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notes{Examples: examples}
+	slugs := make(map[string]int)
+	for _, name := range names {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		src := parse(name, string(b))
+		for _, t := range src.Topics {
+			t.Slug = slugify(t.Title)
+			if k := slugs[t.Slug]; k > 0 {
+				slugs[t.Slug]++
+				t.Slug = fmt.Sprintf("%s-%d", t.Slug, k+1)
+			} else {
+				slugs[t.Slug] = 1
+			}
+			t.Examples = MatchExamples(t.Title, examples)
+			n.Topics = append(n.Topics, t)
+		}
+		n.Sources = append(n.Sources, src)
+	}
+	return n, nil
+}
+
+// sourceIndex orders Learning.md before Learning2.md ... Learning10.md.
+func sourceIndex(name string) int {
+	digits := strings.TrimSuffix(strings.TrimPrefix(name, "Learning"), ".md")
+	if digits == "" {
+		return 1
+	}
+	i, err := strconv.Atoi(digits)
+	if err != nil {
+		return 1 << 30
+	}
+	return i
+}
+
+func parse(name, text string) *Source {
+	src := &Source{Name: name}
+	var cur *Topic
+	var body []string
+	finish := func() {
+		content := strings.Trim(strings.Join(body, "\n"), "\n")
+		if cur == nil {
+			src.Intro = content
+		} else {
+			cur.Body = content
+		}
+		body = nil
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if m := topicRe.FindStringSubmatch(line); m != nil {
+			finish()
+			num, _ := strconv.Atoi(m[1])
+			cur = &Topic{Number: num, Title: m[2], Source: name}
+			src.Topics = append(src.Topics, cur)
+			continue
+		}
+		body = append(body, line)
+	}
+	finish()
+	return src
+}
+
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// MatchExamples returns the example files a topic title refers to. Names are
+// compared word by word, so "Worker Pool" finds WorkerPool.go, "Command Line
+// Arguments" finds CmdArg.go and "Sorting by Functions" prefers
+// SortingFunction.go over Sorting.go. Numbered variants (Mutexes2.go) come
+// along with their base file.
+func MatchExamples(title string, examples []string) []string {
+	topicWords := splitWords(title)
+	joined := strings.Join(topicWords, "")
+
+	type candidate struct {
+		base  string
+		score int
+	}
+	var full, partial []candidate
+	bases := make(map[string][]string)
+	for _, ex := range examples {
+		base := strings.TrimRight(strings.TrimSuffix(ex, ".go"), "0123456789")
+		if _, seen := bases[base]; !seen {
+			fileWords := splitWords(base)
+			switch {
+			case strings.Join(fileWords, "") == joined:
+				full = append(full, candidate{base, 1000})
+			default:
+				if score, ok := coverScore(fileWords, topicWords); ok {
+					full = append(full, candidate{base, score})
+				} else if score, ok := coverScore(topicWords, fileWords); ok {
+					partial = append(partial, candidate{base, score})
+				}
+			}
+		}
+		bases[base] = append(bases[base], ex)
+	}
+
+	pool := full
+	if len(pool) == 0 {
+		pool = partial
+	}
+	if len(pool) == 0 {
+		return nil
+	}
+	best := slices.MaxFunc(pool, func(a, b candidate) int { return a.score - b.score })
+	return bases[best.base]
+}
+
+// coverScore reports whether every word in need is matched by some word in
+// have, and how good the matches are: exact words count most, then shared
+// prefixes, then abbreviations like "fn" for "functions".
+func coverScore(need, have []string) (int, bool) {
+	score := 0
+	for _, n := range need {
+		best := 0
+		for _, h := range have {
+			best = max(best, wordMatch(n, h))
+		}
+		if best == 0 {
+			return 0, false
+		}
+		score += best
+	}
+	return score, true
+}
+
+func wordMatch(a, b string) int {
+	switch {
+	case a == b:
+		return 3
+	case strings.HasPrefix(a, b) || strings.HasPrefix(b, a):
+		return 2
+	case commonPrefix(a, b) >= 4:
+		// Tolerates typos and inflections: "templates"/"templating",
+		// "channles"/"channels".
+		return 2
+	case len(a) < len(b) && a[0] == b[0] && isSubsequence(a, b):
+		return 1
+	}
+	return 0
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func isSubsequence(short, long string) bool {
+	i := 0
+	for j := 0; i < len(short) && j < len(long); j++ {
+		if short[i] == long[j] {
+			i++
+		}
+	}
+	return i == len(short)
+}
+
+// splitWords lower-cases s and breaks it at spaces, punctuation and camel-case
+// boundaries. Acronyms stay whole: "HTTPClient" is "http", "client".
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	rs := []rune(s)
+	emit := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = nil
+		}
+	}
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			emit()
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				emit()
+			}
+		}
+		cur = append(cur, r)
+	}
+	emit()
+	return words
+}
+
+// pageFor returns the site path of a topic's page.
+func pageFor(t *Topic) string {
+	return path.Join("topics", t.Slug+".html")
+}
+
+// exampleFor returns the site path of an example's page.
+func exampleFor(name string) string {
+	return path.Join("examples", name+".html")
+}
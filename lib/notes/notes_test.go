@@ -0,0 +1,60 @@
+package notes
+
+import (
+	"slices"
+	"testing"
+)
+
+var examples = []string{
+	"CmdArg.go", "CmdFlags.go", "CmdSubCommands.go", "Function.go", "HTTPClient.go",
+	"HTTPServer.go", "Mutexes.go", "Mutexes2.go", "MultipleReturnFn.go", "RangeOverChannels.go",
+	"Range.go", "Sorting.go", "SortingFunction.go", "TextTemplating.go", "TextTemplating2.go",
+	"VariadicFunction.go", "WorkerPool.go", "Directories.go", "EmbedDirective.go",
+}
+
+func TestMatchExamples(t *testing.T) {
+	var tests = []struct {
+		title string
+		want  []string
+	}{
+		{"Worker Pool", []string{"WorkerPool.go"}},
+		{"Command Line Arguments", []string{"CmdArg.go"}},
+		{"Command Line Flags", []string{"CmdFlags.go"}},
+		{"Command Line SubCommands", []string{"CmdSubCommands.go"}},
+		{"Sorting", []string{"Sorting.go"}},
+		{"Sorting by Functions", []string{"SortingFunction.go"}},
+		{"Mutexes", []string{"Mutexes.go", "Mutexes2.go"}},
+		{"Text Templates", []string{"TextTemplating.go", "TextTemplating2.go"}},
+		{"Range", []string{"Range.go"}},
+		{"Range over Channles", []string{"RangeOverChannels.go"}},
+		{"Multiple Return Functions", []string{"MultipleReturnFn.go"}},
+		{"Variadic Functions", []string{"VariadicFunction.go"}},
+		{"HTTP Client", []string{"HTTPClient.go"}},
+		{"Direcotries", []string{"Directories.go"}},
+		{"Embed Directive", []string{"EmbedDirective.go"}},
+		{"Print Hello World!", nil},
+	}
+	for _, tt := range tests {
+		if got := MatchExamples(tt.title, examples); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	var tests = []struct {
+		in   string
+		want []string
+	}{
+		{"HTTPClient", []string{"http", "client"}},
+		{"SHA256Hash", []string{"sha256", "hash"}},
+		{"Mutexes2", []string{"mutexes2"}},
+		{"Range over Channles:", []string{"range", "over", "channles"}},
+		{"StructEmbedding", []string{"struct", "embedding"}},
+	}
+	for _, tt := range tests {
+		if got := splitWords(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package notes
+
+import (
+	"bytes"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Site is a generated site: file contents keyed by slash-separated path.
+type Site map[string][]byte
+
+// FS returns the site as an fs.FS, ready for static.New.
+func (s Site) FS() fs.FS {
+	return siteFS(s)
+}
+
+// WriteDir writes the site under dir, creating directories as needed.
+func (s Site) WriteDir(dir string) error {
+	for name, b := range s {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type link struct {
+	Href  string
+	Title string
+}
+
+type page struct {
+	Title   string
+	Root    string // relative path back to the site root, "" or "../"
+	Prev    *link
+	Next    *link
+	Content template.HTML
+	Links   []link // example pages for a topic, topic pages for an example
+	Label   string // heading for Links
+}
+
+type indexPage struct {
+	Title    string
+	Root     string
+	Intro    template.HTML
+	Sources  []indexSource
+	Examples []indexExample
+}
+
+type indexSource struct {
+	Name   string
+	Topics []indexTopic
+}
+
+type indexTopic struct {
+	Number   int
+	Title    string
+	Href     string
+	Examples []link
+}
+
+type indexExample struct {
+	Name   string
+	Href   string
+	Topics []link
+}
+
+var tmpl = template.Must(template.New("").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - GoLang Notes</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<nav><a href="{{.Root}}index.html">GoLang Notes</a></nav>
+<main>
+{{end}}
+
+{{define "foot"}}</main>
+</body>
+</html>
+{{end}}
+
+{{define "page"}}{{template "head" .}}
+<h1>{{.Title}}</h1>
+{{if .Links}}<p class="links">{{.Label}}: {{range $i, $l := .Links}}{{if $i}}, {{end}}<a href="{{$.Root}}{{$l.Href}}">{{$l.Title}}</a>{{end}}</p>{{end}}
+{{.Content}}
+<footer class="pager">
+{{with .Prev}}<a class="prev" href="{{$.Root}}{{.Href}}">&larr; {{.Title}}</a>{{end}}
+{{with .Next}}<a class="next" href="{{$.Root}}{{.Href}}">{{.Title}} &rarr;</a>{{end}}
+</footer>
+{{template "foot" .}}{{end}}
+
+{{define "index"}}{{template "head" .}}
+{{.Intro}}
+<h2>Contents</h2>
+{{range .Sources}}<section>
+<h3>{{.Name}}</h3>
+<ol class="toc">
+{{range .Topics}}<li value="{{.Number}}"><a href="{{.Href}}">{{.Title}}</a>{{range .Examples}} <a class="example" href="{{.Href}}">{{.Title}}</a>{{end}}</li>
+{{end}}</ol>
+</section>
+{{end}}
+<h2>Examples</h2>
+<ul class="examples">
+{{range .Examples}}<li><a href="{{.Href}}">{{.Name}}</a>{{range .Topics}} &middot; <a href="{{.Href}}">{{.Title}}</a>{{end}}</li>
+{{end}}</ul>
+{{template "foot" .}}{{end}}
+`))
+
+const stylesheet = `body { margin: 0; font: 16px/1.6 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #222; }
+nav { padding: .75rem 1.5rem; background: #00add8; }
+nav a { color: #fff; font-weight: bold; text-decoration: none; }
+main { max-width: 52rem; margin: 0 auto; padding: 1rem 1.5rem 3rem; }
+a { color: #007d9c; }
+pre { background: #f6f8fa; padding: 1rem; overflow-x: auto; border-radius: 4px; tab-size: 4; }
+code { font: 14px/1.45 Menlo, Consolas, monospace; }
+p code, li code { background: #f6f8fa; padding: 0 .25em; }
+.toc a.example, .links a { font-size: .85em; }
+.toc a.example { color: #666; }
+.pager { display: flex; justify-content: space-between; margin-top: 2rem; border-top: 1px solid #ddd; padding-top: 1rem; }
+.pager .next { margin-left: auto; }
+`
+
+// Build renders the whole site. Example source files are read from the same
+// fsys the notes were loaded from.
+func (n *Notes) Build(fsys fs.FS) (Site, error) {
+	site := Site{"style.css": []byte(stylesheet)}
+
+	// Which topics mention each example, for the back links.
+	topicsFor := make(map[string][]link)
+	for _, t := range n.Topics {
+		for _, ex := range t.Examples {
+			topicsFor[ex] = append(topicsFor[ex], link{Href: pageFor(t), Title: t.Title})
+		}
+	}
+
+	for i, t := range n.Topics {
+		p := page{
+			Title:   t.Title,
+			Root:    "../",
+			Content: template.HTML(RenderMarkdown(t.Body)),
+			Label:   "Example",
+		}
+		if i > 0 {
+			p.Prev = &link{Href: pageFor(n.Topics[i-1]), Title: n.Topics[i-1].Title}
+		}
+		if i+1 < len(n.Topics) {
+			p.Next = &link{Href: pageFor(n.Topics[i+1]), Title: n.Topics[i+1].Title}
+		}
+		for _, ex := range t.Examples {
+			p.Links = append(p.Links, link{Href: exampleFor(ex), Title: ex})
+		}
+		if err := render(site, pageFor(t), "page", p); err != nil {
+			return nil, err
+		}
+	}
+
+	examples := slices.Clone(n.Examples)
+	slices.Sort(examples)
+	for i, ex := range examples {
+		src, err := fs.ReadFile(fsys, ex)
+		if err != nil {
+			return nil, err
+		}
+		p := page{
+			Title:   ex,
+			Root:    "../",
+			Content: template.HTML(`<pre><code class="language-go">` + template.HTMLEscapeString(string(src)) + "</code></pre>"),
+			Links:   topicsFor[ex],
+			Label:   "Notes",
+		}
+		if i > 0 {
+			p.Prev = &link{Href: exampleFor(examples[i-1]), Title: examples[i-1]}
+		}
+		if i+1 < len(examples) {
+			p.Next = &link{Href: exampleFor(examples[i+1]), Title: examples[i+1]}
+		}
+		if err := render(site, exampleFor(ex), "page", p); err != nil {
+			return nil, err
+		}
+	}
+
+	idx := indexPage{Title: "Contents"}
+	for _, src := range n.Sources {
+		if idx.Intro == "" && src.Intro != "" {
+			idx.Intro = template.HTML(RenderMarkdown(src.Intro))
+		}
+		s := indexSource{Name: strings.TrimSuffix(src.Name, ".md")}
+		for _, t := range src.Topics {
+			it := indexTopic{Number: t.Number, Title: t.Title, Href: pageFor(t)}
+			for _, ex := range t.Examples {
+				it.Examples = append(it.Examples, link{Href: exampleFor(ex), Title: ex})
+			}
+			s.Topics = append(s.Topics, it)
+		}
+		idx.Sources = append(idx.Sources, s)
+	}
+	for _, ex := range examples {
+		idx.Examples = append(idx.Examples, indexExample{Name: ex, Href: exampleFor(ex), Topics: topicsFor[ex]})
+	}
+	if err := render(site, "index.html", "index", idx); err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+func render(site Site, name, tmplName string, data any) error {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, tmplName, data); err != nil {
+		return err
+	}
+	site[path.Clean(name)] = buf.Bytes()
+	return nil
+}
@@ -0,0 +1,24 @@
+package notes
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSiteFS(t *testing.T) {
+	site := Site{
+		"index.html":           []byte("<h1>index</h1>"),
+		"style.css":            []byte("body{}"),
+		"topics/maps.html":     []byte("maps"),
+		"examples/Map.go.html": []byte("package main"),
+	}
+	fsys := site.FS()
+	if err := fstest.TestFS(fsys, "index.html", "style.css", "topics/maps.html", "examples/Map.go.html"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(fsys, "topics/none.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,320 @@
+// Package pipeline builds multi-stage channel pipelines like the
+// sender/receiver pair in ChannelDirection2.go, but with many stages, many
+// workers per stage and errors.
+//
+// A Pipeline owns a context and a group of goroutines. Source starts the
+// first stage, Stage adds one that maps values with a number of workers
+// (fanning out to them and back in, in order or not), Merge and FanOut join
+// and split channels, and ForEach consumes the end. Every stage sends and
+// receives under the pipeline's context, so the first error cancels it, all
+// stages return and Wait reports that error with no goroutines left behind:
+//
+//	p := pipeline.New(ctx)
+//	urls := pipeline.FromSlice(p, list)
+//	pages := pipeline.Stage(p, urls, pipeline.Options{Name: "fetch", Workers: 8, Ordered: true}, fetch)
+//	pipeline.ForEach(p, pages, save)
+//	if err := p.Wait(); err != nil {
+//		...
+//	}
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Skip, returned by a Stage function, drops the value instead of failing
+// the pipeline.
+var Skip = errors.New("pipeline: skip value")
+
+// Pipeline is one run of a pipeline. Build it with the functions in this
+// package, then call Wait once.
+type Pipeline struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// New returns a Pipeline whose stages stop when ctx is done.
+func New(ctx context.Context) *Pipeline {
+	c, cancel := context.WithCancelCause(ctx)
+	return &Pipeline{parent: ctx, ctx: c, cancel: cancel}
+}
+
+// Context returns the context stages run under. It is cancelled on the first
+// error, with that error as its cause.
+func (p *Pipeline) Context() context.Context { return p.ctx }
+
+// Wait waits for every stage to return and reports the first error, or the
+// parent context's error if that stopped the pipeline.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel(nil)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	return p.parent.Err()
+}
+
+func (p *Pipeline) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+		p.cancel(err)
+	}
+}
+
+// goroutine runs f as part of the pipeline; an error fails the pipeline.
+func (p *Pipeline) goroutine(f func() error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := f(); err != nil {
+			p.fail(err)
+		}
+	}()
+}
+
+// send sends v on ch unless the pipeline is cancelled first.
+func send[T any](ctx context.Context, ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recv receives from ch unless the pipeline is cancelled first. ok is false
+// in either case of the channel being closed or the pipeline cancelled.
+func recv[T any](ctx context.Context, ch <-chan T) (v T, ok bool) {
+	select {
+	case v, ok = <-ch:
+		return v, ok
+	case <-ctx.Done():
+		return v, false
+	}
+}
+
+// Source starts a stage that produces values by calling emit, and returns
+// its output channel, which has a buffer of buf values. emit fails once the
+// pipeline is cancelled; gen should return its error then.
+func Source[T any](p *Pipeline, buf int, gen func(ctx context.Context, emit func(T) error) error) <-chan T {
+	out := make(chan T, buf)
+	emit := func(v T) error {
+		if !send(p.ctx, out, v) {
+			return p.ctx.Err()
+		}
+		return nil
+	}
+	p.goroutine(func() error {
+		defer close(out)
+		return gen(p.ctx, emit)
+	})
+	return out
+}
+
+// FromSlice starts a stage that sends the values of vs.
+func FromSlice[T any](p *Pipeline, vs []T) <-chan T {
+	return Source(p, 0, func(ctx context.Context, emit func(T) error) error {
+		for _, v := range vs {
+			if err := emit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Options configure a Stage.
+type Options struct {
+	// Name prefixes the stage's errors.
+	Name string
+	// Workers is the number of goroutines running the stage function. Less
+	// than 1 means 1.
+	Workers int
+	// Buffer is the capacity of the stage's output channel, which bounds how
+	// far the stage can run ahead of the next one.
+	Buffer int
+	// Ordered keeps outputs in the order of their inputs when there is more
+	// than one worker. It costs a goroutine and, while an early value is
+	// slow, holds back the values after it.
+	Ordered bool
+}
+
+func (o Options) wrap(err error) error {
+	if o.Name == "" {
+		return err
+	}
+	return fmt.Errorf("pipeline: %s: %w", o.Name, err)
+}
+
+// Stage starts a stage that applies f to every value from in and returns its
+// output channel. An error from f other than Skip fails the pipeline.
+func Stage[T, U any](p *Pipeline, in <-chan T, opts Options, f func(context.Context, T) (U, error)) <-chan U {
+	opts.Workers = max(opts.Workers, 1)
+	out := make(chan U, opts.Buffer)
+	if opts.Ordered && opts.Workers > 1 {
+		orderedStage(p, in, out, opts, f)
+		return out
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		p.goroutine(func() error {
+			defer wg.Done()
+			for {
+				v, ok := recv(p.ctx, in)
+				if !ok {
+					return nil
+				}
+				u, err := f(p.ctx, v)
+				if errors.Is(err, Skip) {
+					continue
+				}
+				if err != nil {
+					return opts.wrap(err)
+				}
+				if !send(p.ctx, out, u) {
+					return nil
+				}
+			}
+		})
+	}
+	p.goroutine(func() error {
+		wg.Wait()
+		close(out)
+		return nil
+	})
+	return out
+}
+
+type result[U any] struct {
+	v    U
+	skip bool
+}
+
+type job[T, U any] struct {
+	v      T
+	result chan result[U]
+}
+
+// orderedStage fans values out to the workers and back in through a queue of
+// one-shot result channels kept in input order. The queue's capacity bounds
+// the values in flight.
+func orderedStage[T, U any](p *Pipeline, in <-chan T, out chan<- U, opts Options, f func(context.Context, T) (U, error)) {
+	pending := make(chan chan result[U], opts.Workers+opts.Buffer)
+	jobs := make(chan job[T, U])
+
+	p.goroutine(func() error {
+		defer close(pending)
+		defer close(jobs)
+		for {
+			v, ok := recv(p.ctx, in)
+			if !ok {
+				return nil
+			}
+			r := make(chan result[U], 1)
+			if !send(p.ctx, pending, r) || !send(p.ctx, jobs, job[T, U]{v, r}) {
+				return nil
+			}
+		}
+	})
+	for i := 0; i < opts.Workers; i++ {
+		p.goroutine(func() error {
+			for j := range jobs {
+				u, err := f(p.ctx, j.v)
+				if err != nil && !errors.Is(err, Skip) {
+					return opts.wrap(err)
+				}
+				j.result <- result[U]{v: u, skip: err != nil}
+			}
+			return nil
+		})
+	}
+	p.goroutine(func() error {
+		defer close(out)
+		for r := range pending {
+			res, ok := recv(p.ctx, r)
+			if !ok {
+				return nil
+			}
+			if !res.skip && !send(p.ctx, out, res.v) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Merge fans several channels in to one, with a buffer of buf values. Values
+// keep their order within each input but not across them.
+func Merge[T any](p *Pipeline, buf int, ins ...<-chan T) <-chan T {
+	out := make(chan T, buf)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		p.goroutine(func() error {
+			defer wg.Done()
+			for {
+				v, ok := recv(p.ctx, in)
+				if !ok || !send(p.ctx, out, v) {
+					return nil
+				}
+			}
+		})
+	}
+	p.goroutine(func() error {
+		wg.Wait()
+		close(out)
+		return nil
+	})
+	return out
+}
+
+// FanOut splits in into n channels, each with a buffer of buf values. Every
+// value goes to exactly one of them, whichever is ready first, so a slow
+// branch gets fewer values.
+func FanOut[T any](p *Pipeline, in <-chan T, n, buf int) []<-chan T {
+	outs := make([]<-chan T, n)
+	for i := range outs {
+		out := make(chan T, buf)
+		outs[i] = out
+		p.goroutine(func() error {
+			defer close(out)
+			for {
+				v, ok := recv(p.ctx, in)
+				if !ok || !send(p.ctx, out, v) {
+					return nil
+				}
+			}
+		})
+	}
+	return outs
+}
+
+// ForEach starts the final stage, calling f for every value from in. An
+// error from f fails the pipeline.
+func ForEach[T any](p *Pipeline, in <-chan T, f func(context.Context, T) error) {
+	p.goroutine(func() error {
+		for {
+			v, ok := recv(p.ctx, in)
+			if !ok {
+				return nil
+			}
+			if err := f(p.ctx, v); err != nil {
+				return err
+			}
+		}
+	})
+}
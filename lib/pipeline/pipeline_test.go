@@ -0,0 +1,266 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ints(n int) []int {
+	vs := make([]int, n)
+	for i := range vs {
+		vs[i] = i
+	}
+	return vs
+}
+
+// slowDouble takes longer for smaller values, so unordered workers finish
+// out of order.
+func slowDouble(ctx context.Context, n int) (int, error) {
+	time.Sleep(time.Duration(50-n%50) * 10 * time.Microsecond)
+	return n * 2, nil
+}
+
+// checkNoLeaks fails if goroutines started during the test are still
+// running. Goroutines can take a moment to exit after Wait returns their
+// last value, so it retries briefly.
+func checkNoLeaks(t *testing.T, before int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("%d goroutines before, %d after", before, runtime.NumGoroutine())
+}
+
+func TestStageOrdered(t *testing.T) {
+	p := New(context.Background())
+	out := Stage(p, FromSlice(p, ints(200)), Options{Workers: 8, Ordered: true}, slowDouble)
+	var got []int
+	ForEach(p, out, func(_ context.Context, n int) error {
+		got = append(got, n)
+		return nil
+	})
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("got[%d] = %d", i, v)
+		}
+	}
+	if len(got) != 200 {
+		t.Errorf("got %d values", len(got))
+	}
+}
+
+func TestStageUnordered(t *testing.T) {
+	p := New(context.Background())
+	out := Stage(p, FromSlice(p, ints(200)), Options{Workers: 8, Buffer: 4}, slowDouble)
+	var got []int
+	ForEach(p, out, func(_ context.Context, n int) error {
+		got = append(got, n)
+		return nil
+	})
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("sorted got[%d] = %d", i, v)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	for _, ordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ordered=%v", ordered), func(t *testing.T) {
+			p := New(context.Background())
+			odd := Stage(p, FromSlice(p, ints(10)), Options{Workers: 3, Ordered: ordered}, func(_ context.Context, n int) (int, error) {
+				if n%2 == 0 {
+					return 0, Skip
+				}
+				return n, nil
+			})
+			var got []int
+			ForEach(p, odd, func(_ context.Context, n int) error {
+				got = append(got, n)
+				return nil
+			})
+			if err := p.Wait(); err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, []int{1, 3, 5, 7, 9}) {
+				t.Errorf("got %v", got)
+			}
+		})
+	}
+}
+
+func TestFirstErrorCancels(t *testing.T) {
+	boom := errors.New("boom")
+	for _, ordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ordered=%v", ordered), func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			p := New(context.Background())
+			// An endless source: only cancellation stops it.
+			src := Source(p, 0, func(ctx context.Context, emit func(int) error) error {
+				for i := 0; ; i++ {
+					if err := emit(i); err != nil {
+						return err
+					}
+				}
+			})
+			mid := Stage(p, src, Options{Name: "check", Workers: 4, Ordered: ordered}, func(_ context.Context, n int) (int, error) {
+				if n == 100 {
+					return 0, boom
+				}
+				return n, nil
+			})
+			// A later stage that would block forever on its own.
+			last := Stage(p, mid, Options{Workers: 2}, func(ctx context.Context, n int) (int, error) {
+				return n, nil
+			})
+			ForEach(p, last, func(context.Context, int) error { return nil })
+
+			err := p.Wait()
+			if !errors.Is(err, boom) || err.Error() != "pipeline: check: boom" {
+				t.Errorf("Wait() = %v", err)
+			}
+			if cause := context.Cause(p.Context()); !errors.Is(cause, boom) {
+				t.Errorf("context cause = %v", cause)
+			}
+			checkNoLeaks(t, before)
+		})
+	}
+}
+
+func TestSinkErrorStopsUpstream(t *testing.T) {
+	before := runtime.NumGoroutine()
+	p := New(context.Background())
+	var produced atomic.Int32
+	src := Source(p, 0, func(ctx context.Context, emit func(int) error) error {
+		for i := 0; ; i++ {
+			produced.Add(1)
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+	})
+	full := errors.New("disk full")
+	ForEach(p, src, func(_ context.Context, n int) error {
+		if n == 5 {
+			return full
+		}
+		return nil
+	})
+	if err := p.Wait(); err != full {
+		t.Errorf("Wait() = %v", err)
+	}
+	if n := produced.Load(); n > 7 {
+		t.Errorf("source produced %d values after the sink failed at 5", n)
+	}
+	checkNoLeaks(t, before)
+}
+
+func TestParentCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(ctx)
+	// A channel from outside the pipeline that is never closed.
+	never := make(chan int)
+	out := Stage(p, never, Options{Workers: 3, Ordered: true}, func(_ context.Context, n int) (int, error) { return n, nil })
+	ForEach(p, out, func(context.Context, int) error { return nil })
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := p.Wait(); err != context.Canceled {
+		t.Errorf("Wait() = %v", err)
+	}
+	checkNoLeaks(t, before)
+}
+
+func TestBoundedBuffer(t *testing.T) {
+	p := New(context.Background())
+	var produced atomic.Int32
+	src := Source(p, 2, func(ctx context.Context, emit func(int) error) error {
+		for i := 0; i < 100; i++ {
+			produced.Add(1)
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	mid := Stage(p, src, Options{Workers: 1, Buffer: 3}, func(_ context.Context, n int) (int, error) { return n, nil })
+	release := make(chan struct{})
+	ForEach(p, mid, func(context.Context, int) error {
+		<-release
+		return nil
+	})
+	time.Sleep(20 * time.Millisecond)
+	// With the sink stuck on its first value: 3 in mid's buffer, 1 held by
+	// the mid worker, 2 in the source's buffer and 1 being emitted.
+	if n := produced.Load(); n > 1+3+1+2+1 {
+		t.Errorf("source ran %d values ahead of a stuck sink", n)
+	}
+	close(release)
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if n := produced.Load(); n != 100 {
+		t.Errorf("produced %d values", n)
+	}
+}
+
+func TestFanOutMerge(t *testing.T) {
+	p := New(context.Background())
+	branches := FanOut(p, FromSlice(p, ints(100)), 3, 1)
+	var doubled []<-chan int
+	for i, b := range branches {
+		doubled = append(doubled, Stage(p, b, Options{Name: fmt.Sprint("branch", i)}, slowDouble))
+	}
+	var got []int
+	ForEach(p, Merge(p, 0, doubled...), func(_ context.Context, n int) error {
+		got = append(got, n)
+		return nil
+	})
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("sorted got[%d] = %d", i, v)
+		}
+	}
+	if len(got) != 100 {
+		t.Errorf("got %d values", len(got))
+	}
+}
+
+func BenchmarkStage(b *testing.B) {
+	for _, workers := range []int{1, 4} {
+		for _, ordered := range []bool{false, true} {
+			b.Run(fmt.Sprintf("workers=%d/ordered=%v", workers, ordered), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					p := New(context.Background())
+					out := Stage(p, FromSlice(p, ints(1000)), Options{Workers: workers, Ordered: ordered, Buffer: 16},
+						func(_ context.Context, n int) (int, error) { return n + 1, nil })
+					ForEach(p, out, func(context.Context, int) error { return nil })
+					if err := p.Wait(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package ratelimit is the token bucket from RateLimitingTokenBucket.go as a
+// reusable type.
+//
+// The example refills a channel from a time.Ticker goroutine and its
+// requests block on the channel. A Limiter does the same arithmetic without
+// the goroutine: tokens that would have been added since the last call are
+// counted from the Clock when a request arrives, so a clock.Fake drives it in
+// tests and nothing needs stopping when it's no longer used.
+//
+//	l := ratelimit.New(200*time.Millisecond, 3, clock.Real)
+//	for req := range requests {
+//		if err := l.Wait(ctx); err != nil {
+//			return err
+//		}
+//		handle(req)
+//	}
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/clock"
+)
+
+// Limiter lets through one request per interval on average, and bursts of
+// up to its capacity after a quiet spell.
+type Limiter struct {
+	clock    clock.Clock
+	interval time.Duration
+	burst    int
+
+	mu     sync.Mutex
+	tokens int
+	last   time.Time // when tokens was last brought up to date
+}
+
+// New returns a limiter adding a token every interval to a bucket holding
+// at most burst, which starts full. It panics if interval or burst is not
+// positive.
+func New(interval time.Duration, burst int, c clock.Clock) *Limiter {
+	if interval <= 0 {
+		panic("ratelimit: non-positive interval")
+	}
+	if burst <= 0 {
+		panic("ratelimit: non-positive burst")
+	}
+	return &Limiter{clock: c, interval: interval, burst: burst, tokens: burst, last: c.Now()}
+}
+
+// refill adds the tokens earned since l.last. Time that didn't earn a whole
+// token carries over to the next call, unless the bucket is full.
+func (l *Limiter) refill(now time.Time) {
+	if n := int(now.Sub(l.last) / l.interval); n > 0 {
+		l.tokens = min(l.burst, l.tokens+n)
+		l.last = l.last.Add(time.Duration(n) * l.interval)
+	}
+	if l.tokens == l.burst {
+		l.last = now
+	}
+}
+
+// Allow takes a token if one is available and reports whether it did.
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.clock.Now())
+	if l.tokens == 0 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until it can take a token or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.clock.Now()
+		l.refill(now)
+		if l.tokens > 0 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := l.last.Add(l.interval).Sub(now)
+		l.mu.Unlock()
+
+		// Another waiter may take the token first, so check again after
+		// the timer rather than assuming it's ours.
+		t := l.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Tokens returns how many requests would be allowed right now.
+func (l *Limiter) Tokens() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.clock.Now())
+	return l.tokens
+}
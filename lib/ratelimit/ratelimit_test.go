@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/clock"
+)
+
+var epoch = time.Date(2024, 8, 19, 13, 3, 50, 0, time.UTC)
+
+func TestAllow(t *testing.T) {
+	f := clock.NewFake(epoch)
+	l := New(time.Second, 3, f)
+
+	var tests = []struct {
+		advance time.Duration
+		allowed int
+	}{
+		{0, 3},                      // starts full
+		{500 * time.Millisecond, 0}, // half a token
+		{500 * time.Millisecond, 1}, // the half carried over
+		{2500 * time.Millisecond, 2},
+		{500 * time.Millisecond, 1},
+		{time.Hour, 3}, // never more than the burst
+	}
+	for i, tt := range tests {
+		f.Advance(tt.advance)
+		got := 0
+		for l.Allow() {
+			got++
+		}
+		if got != tt.allowed {
+			t.Errorf("step %d (+%v): allowed %d, want %d", i, tt.advance, got, tt.allowed)
+		}
+	}
+}
+
+// TestWait is RateLimitingTokenBucket.go on a fake clock: five requests
+// against a bucket of three refilled once a second.
+func TestWait(t *testing.T) {
+	f := clock.NewFake(epoch)
+	l := New(time.Second, 3, f)
+
+	served := make(chan time.Duration)
+	go func() {
+		for i := 0; i < 5; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+			served <- f.Since(epoch)
+		}
+	}()
+	var at []time.Duration
+	for i := 0; i < 5; i++ {
+		if i >= 3 {
+			f.BlockUntil(1)
+			f.Advance(time.Second)
+		}
+		at = append(at, <-served)
+	}
+	want := []time.Duration{0, 0, 0, time.Second, 2 * time.Second}
+	if !slices.Equal(at, want) {
+		t.Errorf("served at %v, want %v", at, want)
+	}
+}
+
+func TestWaitCancel(t *testing.T) {
+	f := clock.NewFake(epoch)
+	l := New(time.Second, 1, f)
+	l.Allow()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.Wait(ctx) }()
+	f.BlockUntil(1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if n := f.Pending(); n != 0 {
+		t.Errorf("%d timers left pending after cancel", n)
+	}
+	if got := l.Tokens(); got != 0 {
+		t.Errorf("cancelled Wait changed the bucket: %d tokens", got)
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	var tests = []struct {
+		interval time.Duration
+		burst    int
+	}{
+		{0, 1},
+		{time.Second, 0},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("New(%v, %d) did not panic", tt.interval, tt.burst)
+				}
+			}()
+			New(tt.interval, tt.burst, clock.Real)
+		}()
+	}
+}
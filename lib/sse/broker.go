@@ -0,0 +1,341 @@
+// Package sse pushes events to browsers over Server-Sent Events and long-poll
+// requests.
+//
+// Context.go showed a handler that waits for work or for ctx.Done(). A Broker
+// turns that into a push subsystem: Publish fans an event out to every
+// subscriber, each SSE connection lives until its request context is
+// cancelled, and a bounded replay buffer lets a reconnecting client resume
+// from the Last-Event-ID it saw.
+//
+//	b := sse.NewBroker(256)
+//	http.Handle("/events", b)
+//	http.HandleFunc("/poll", b.Poll)
+//	b.Publish("job-42", "progress", `{"done": 10}`)
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is one published message. ID is assigned by the broker and increases
+// by one with every Publish.
+type Event struct {
+	ID    uint64 `json:"id"`
+	Topic string `json:"topic,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Data  string `json:"data"`
+}
+
+// ErrClosed is returned by Publish after the broker has been closed.
+var ErrClosed = errors.New("sse: broker closed")
+
+// Broker fans published events out to its subscribers. The zero value is not
+// usable; create one with NewBroker.
+type Broker struct {
+	// Heartbeat is how often an idle SSE connection gets a comment line, which
+	// keeps proxies from timing it out. Zero disables heartbeats.
+	Heartbeat time.Duration
+	// Retry, when set, is sent to clients as the reconnection delay.
+	Retry time.Duration
+	// MaxPollWait caps the timeout a long-poll client can ask for.
+	MaxPollWait time.Duration
+	// SubscriberBuffer is how many events may queue up for one subscriber
+	// before it is considered too slow and disconnected. A disconnected
+	// client catches up from the replay buffer when it reconnects.
+	SubscriberBuffer int
+
+	mu     sync.Mutex
+	nextID uint64
+	replay []Event // ring buffer, oldest at start
+	start  int
+	size   int
+	subs   map[*subscriber]struct{}
+	closed bool
+}
+
+type subscriber struct {
+	topic string
+	ch    chan Event
+}
+
+func (s *subscriber) wants(ev Event) bool {
+	return s.topic == "" || s.topic == ev.Topic
+}
+
+// NewBroker returns a broker that remembers the last replaySize events for
+// clients that reconnect.
+func NewBroker(replaySize int) *Broker {
+	if replaySize < 0 {
+		replaySize = 0
+	}
+	return &Broker{
+		Heartbeat:        15 * time.Second,
+		MaxPollWait:      60 * time.Second,
+		SubscriberBuffer: 64,
+		nextID:           1,
+		replay:           make([]Event, replaySize),
+		subs:             make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish assigns the next ID to an event and delivers it to every subscriber
+// of its topic (and to subscribers of all topics). The type can't contain a
+// line break, which would let it add fields of its own to the stream.
+func (b *Broker) Publish(topic, typ, data string) (Event, error) {
+	if strings.ContainsAny(typ, "\r\n") {
+		return Event{}, fmt.Errorf("sse: event type %q contains a line break", typ)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return Event{}, ErrClosed
+	}
+	ev := Event{ID: b.nextID, Topic: topic, Type: typ, Data: data}
+	b.nextID++
+
+	if n := len(b.replay); n > 0 {
+		if b.size < n {
+			b.replay[(b.start+b.size)%n] = ev
+			b.size++
+		} else {
+			b.replay[b.start] = ev
+			b.start = (b.start + 1) % n
+		}
+	}
+
+	for s := range b.subs {
+		if !s.wants(ev) {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			// Never let one slow reader hold up the others.
+			delete(b.subs, s)
+			close(s.ch)
+		}
+	}
+	return ev, nil
+}
+
+// Subscribe registers a subscriber for topic ("" means every topic). The
+// returned slice holds the buffered events newer than lastID, which must be
+// delivered before anything read from the channel. The channel is closed
+// when the broker closes or drops the subscriber; cancel removes it early.
+func (b *Broker) Subscribe(topic string, lastID uint64) (replay []Event, events <-chan Event, cancel func()) {
+	s := &subscriber{topic: topic, ch: make(chan Event, max(b.SubscriberBuffer, 1))}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := 0; i < b.size; i++ {
+		ev := b.replay[(b.start+i)%len(b.replay)]
+		if ev.ID > lastID && s.wants(ev) {
+			replay = append(replay, ev)
+		}
+	}
+	if b.closed {
+		close(s.ch)
+		return replay, s.ch, func() {}
+	}
+	b.subs[s] = struct{}{}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[s]; ok {
+			delete(b.subs, s)
+			close(s.ch)
+		}
+	}
+	return replay, s.ch, cancel
+}
+
+// Subscribers returns how many subscribers are currently connected.
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close disconnects every subscriber. Later Publish calls fail with ErrClosed.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for s := range b.subs {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
+
+// ServeHTTP streams events as text/event-stream. The topic comes from the
+// "topic" query parameter, and a reconnecting browser's Last-Event-ID header
+// (or a "lastEventId" query parameter) selects where to resume.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	rc := http.NewResponseController(w)
+
+	lastID := parseID(req.Header.Get("Last-Event-ID"))
+	if v := req.URL.Query().Get("lastEventId"); v != "" {
+		lastID = parseID(v)
+	}
+	replay, events, cancel := b.Subscribe(req.URL.Query().Get("topic"), lastID)
+	defer cancel()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if b.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", b.Retry.Milliseconds())
+	}
+	for _, ev := range replay {
+		if err := writeEvent(w, ev); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	var heartbeat <-chan time.Time
+	if b.Heartbeat > 0 {
+		t := time.NewTicker(b.Heartbeat)
+		defer t.Stop()
+		heartbeat = t.C
+	}
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// pollResponse is the JSON body returned to long-poll clients. LastID is the
+// value to send as "after" on the next request.
+type pollResponse struct {
+	Events []Event `json:"events"`
+	LastID uint64  `json:"lastId"`
+}
+
+// Poll serves the long-poll variant for clients that can't hold a stream
+// open. It answers as soon as there is at least one event newer than the
+// "after" query parameter, or with an empty list once "timeout" (a Go
+// duration, default 30s) passes.
+func (b *Broker) Poll(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	after := parseID(q.Get("after"))
+	wait := 30 * time.Second
+	if v := q.Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			http.Error(w, "invalid timeout", http.StatusBadRequest)
+			return
+		}
+		wait = d
+	}
+	if b.MaxPollWait > 0 && wait > b.MaxPollWait {
+		wait = b.MaxPollWait
+	}
+
+	replay, events, cancel := b.Subscribe(q.Get("topic"), after)
+	defer cancel()
+
+	resp := pollResponse{Events: replay, LastID: after}
+	if len(replay) == 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case ev, ok := <-events:
+			if ok {
+				resp.Events = append(resp.Events, ev)
+			}
+		case <-timer.C:
+		case <-req.Context().Done():
+			return
+		}
+	}
+	// Pick up anything else that is already queued without waiting again.
+drain:
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				break drain
+			}
+			resp.Events = append(resp.Events, ev)
+		default:
+			break drain
+		}
+	}
+	if resp.Events == nil {
+		resp.Events = []Event{}
+	}
+	if n := len(resp.Events); n > 0 {
+		resp.LastID = resp.Events[n-1].ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// lineBreaks turns each of the event-stream format's line endings into "\n".
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// writeEvent writes ev in event-stream framing. Multi-line data is split
+// over several data fields, which the browser joins back with newlines. The
+// browser ends a line at "\r\n", "\n" or a lone "\r", so all three are
+// split on; a "\r" left in a data field would start a field of the data's
+// choosing.
+func writeEvent(w io.Writer, ev Event) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "id: %d\n", ev.ID)
+	if ev.Type != "" {
+		fmt.Fprintf(&sb, "event: %s\n", ev.Type)
+	}
+	data := lineBreaks.Replace(ev.Data)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", line)
+	}
+	sb.WriteByte('\n')
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func parseID(s string) uint64 {
+	id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,241 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteEvent(t *testing.T) {
+	var tests = []struct {
+		ev   Event
+		want string
+	}{
+		{Event{ID: 1, Data: "hi"}, "id: 1\ndata: hi\n\n"},
+		{Event{ID: 2, Type: "update", Data: "a\nb"}, "id: 2\nevent: update\ndata: a\ndata: b\n\n"},
+		{Event{ID: 3, Data: "a\r\nb\rc"}, "id: 3\ndata: a\ndata: b\ndata: c\n\n"},
+		// A lone "\r" ends a line for the browser: it must not smuggle in
+		// fields of its own.
+		{Event{ID: 4, Data: "x\rid: 99\revent: admin"}, "id: 4\ndata: x\ndata: id: 99\ndata: event: admin\n\n"},
+		{Event{ID: 5, Data: ""}, "id: 5\ndata: \n\n"},
+	}
+	for _, tt := range tests {
+		var sb strings.Builder
+		if err := writeEvent(&sb, tt.ev); err != nil {
+			t.Fatal(err)
+		}
+		if sb.String() != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.ev, sb.String(), tt.want)
+		}
+	}
+}
+
+func TestPublishRejectsLineBreakInType(t *testing.T) {
+	b := NewBroker(4)
+	for _, typ := range []string{"a\nid: 9", "a\rb", "a\r\n"} {
+		if _, err := b.Publish("", typ, "x"); err == nil {
+			t.Errorf("type %q: no error", typ)
+		}
+	}
+	if ev, err := b.Publish("", "ok", "x"); err != nil || ev.ID != 1 {
+		t.Errorf("got %+v, %v; a rejected event mustn't use up an ID", ev, err)
+	}
+}
+
+func ids(evs []Event) []uint64 {
+	var out []uint64
+	for _, ev := range evs {
+		out = append(out, ev.ID)
+	}
+	return out
+}
+
+func TestReplay(t *testing.T) {
+	b := NewBroker(3)
+	for _, topic := range []string{"a", "b", "a", "a", "b"} {
+		b.Publish(topic, "", topic)
+	}
+	// The buffer holds 3, 4 and 5.
+	var tests = []struct {
+		topic  string
+		lastID uint64
+		want   []uint64
+	}{
+		{"", 0, []uint64{3, 4, 5}},
+		{"", 3, []uint64{4, 5}},
+		{"", 5, nil},
+		{"a", 0, []uint64{3, 4}},
+		{"b", 4, []uint64{5}},
+	}
+	for _, tt := range tests {
+		replay, _, cancel := b.Subscribe(tt.topic, tt.lastID)
+		cancel()
+		if got := ids(replay); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Subscribe(%q, %d): got %v, want %v", tt.topic, tt.lastID, got, tt.want)
+		}
+	}
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	b := NewBroker(0)
+	b.SubscriberBuffer = 2
+	_, slow, _ := b.Subscribe("", 0)
+	_, fast, cancel := b.Subscribe("", 0)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		b.Publish("", "", "x")
+		<-fast
+	}
+	if b.Subscribers() != 1 {
+		t.Errorf("got %d subscribers, want 1", b.Subscribers())
+	}
+	n := 0
+	for range slow {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("slow subscriber got %d events before being dropped, want 2", n)
+	}
+}
+
+func TestCloseEndsSubscribers(t *testing.T) {
+	b := NewBroker(0)
+	_, events, _ := b.Subscribe("", 0)
+	b.Close()
+	if _, ok := <-events; ok {
+		t.Error("channel still open after Close")
+	}
+	if _, err := b.Publish("", "", "x"); err != ErrClosed {
+		t.Errorf("got %v, want ErrClosed", err)
+	}
+}
+
+// readEvents reads n events from an event stream as "id type data" strings.
+func readEvents(t *testing.T, sc *bufio.Scanner, n int) []string {
+	t.Helper()
+	var out []string
+	var cur []string
+	for len(out) < n && sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if len(cur) > 0 {
+				out = append(out, strings.Join(cur, " "))
+				cur = nil
+			}
+		case strings.HasPrefix(line, "id: "), strings.HasPrefix(line, "event: "), strings.HasPrefix(line, "data: "):
+			_, v, _ := strings.Cut(line, ": ")
+			cur = append(cur, v)
+		}
+	}
+	if len(out) < n {
+		t.Fatalf("stream ended after %q: %v", out, sc.Err())
+	}
+	return out
+}
+
+func TestServeHTTPResumes(t *testing.T) {
+	b := NewBroker(8)
+	b.Heartbeat = 0
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+	defer b.Close()
+
+	b.Publish("", "", "one")
+	b.Publish("", "tick", "two")
+	b.Publish("", "", "three")
+
+	var tests = []struct {
+		via    string
+		lastID string
+		replay []string
+		live   string
+	}{
+		{"header", "1", []string{"2 tick two", "3 three"}, "4 live"},
+		{"query", "2", []string{"3 three", "4 live"}, "5 live"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if tt.via == "header" {
+			req.Header.Set("Last-Event-ID", tt.lastID)
+		} else {
+			req.URL.RawQuery = "lastEventId=" + tt.lastID
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type %q", ct)
+		}
+		sc := bufio.NewScanner(resp.Body)
+		if got := readEvents(t, sc, len(tt.replay)); !reflect.DeepEqual(got, tt.replay) {
+			t.Errorf("%s: replay %q, want %q", tt.via, got, tt.replay)
+		}
+		b.Publish("", "", "live")
+		if got := readEvents(t, sc, 1)[0]; got != tt.live {
+			t.Errorf("%s: live event %q, want %q", tt.via, got, tt.live)
+		}
+		resp.Body.Close()
+	}
+}
+
+func poll(t *testing.T, url string) (pollResponse, int) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var pr pollResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pr, resp.StatusCode
+}
+
+func TestPoll(t *testing.T) {
+	b := NewBroker(8)
+	srv := httptest.NewServer(http.HandlerFunc(b.Poll))
+	defer srv.Close()
+	b.Publish("a", "", "one")
+	b.Publish("b", "", "two")
+
+	// Buffered events come back at once.
+	pr, _ := poll(t, srv.URL+"?after=0")
+	if got := ids(pr.Events); !reflect.DeepEqual(got, []uint64{1, 2}) || pr.LastID != 2 {
+		t.Errorf("got %v last %d", got, pr.LastID)
+	}
+	pr, _ = poll(t, srv.URL+"?after=0&topic=b")
+	if got := ids(pr.Events); !reflect.DeepEqual(got, []uint64{2}) {
+		t.Errorf("topic b: got %v", got)
+	}
+
+	// Nothing new: empty list, "after" handed back, once the timeout passes.
+	start := time.Now()
+	pr, _ = poll(t, srv.URL+"?after=2&timeout=50ms")
+	if len(pr.Events) != 0 || pr.LastID != 2 || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("got %+v after %v", pr, time.Since(start))
+	}
+
+	// A publish while waiting answers the poll.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		b.Publish("a", "", "three")
+	}()
+	pr, _ = poll(t, srv.URL+"?after=2&timeout=5s")
+	if got := ids(pr.Events); !reflect.DeepEqual(got, []uint64{3}) || pr.LastID != 3 {
+		t.Errorf("got %v last %d", got, pr.LastID)
+	}
+
+	if _, code := poll(t, srv.URL+"?timeout=soon"); code != http.StatusBadRequest {
+		t.Errorf("bad timeout: got %d", code)
+	}
+}
@@ -0,0 +1,231 @@
+// Package static serves a file tree, typically an embed.FS, over HTTP.
+//
+// EmbedDirective.go embeds `folder` into the binary and reads files out of it
+// one at a time. Handler serves such a tree to browsers, so a single binary
+// can carry its whole UI:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	sub, _ := fs.Sub(dist, "dist")
+//	h, err := static.New(sub)
+//	h.Fallback = "index.html" // single-page app routing
+//	http.Handle("/", h)
+//
+// Every file is hashed once when New runs. A go:generate step could write
+// the ETags to a manifest and embed it instead, but hashing at startup
+// keeps New usable with any fs.FS and leaves nothing to regenerate; the
+// price is reading the whole tree once per start. Embedded content can't
+// change after the build, so a binary gets the same strong ETags every
+// time. Conditional and Range requests are answered by http.ServeContent,
+// and a "name.br" or "name.gz" file next to "name" is sent instead of it to
+// clients that accept that encoding.
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Precompressed variants, in order of preference.
+var encodings = []struct {
+	name string // Content-Encoding value
+	ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+type file struct {
+	path        string
+	etag        string
+	contentType string
+	variants    map[string]*file // by encoding
+}
+
+// Handler serves the files of an fs.FS.
+type Handler struct {
+	// Fallback is served for GET requests of unknown, extension-less paths
+	// from clients that accept HTML, so client-side routes such as
+	// /users/42 load the app. Empty disables the fallback.
+	Fallback string
+	// CacheControl, when set, is sent with every file.
+	CacheControl string
+
+	fsys  fs.FS
+	files map[string]*file
+}
+
+// New hashes every file in fsys and returns a Handler for it.
+func New(fsys fs.FS) (*Handler, error) {
+	h := &Handler{fsys: fsys, files: make(map[string]*file)}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		h.files[p] = &file{
+			path:        p,
+			etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+			contentType: contentType(p, b),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach foo.js.br and foo.js.gz to foo.js. They keep their own ETag,
+	// since their bytes differ, but take the original's Content-Type.
+	for p, f := range h.files {
+		for _, enc := range encodings {
+			v, ok := h.files[p+enc.ext]
+			if !ok {
+				continue
+			}
+			if f.variants == nil {
+				f.variants = make(map[string]*file)
+			}
+			f.variants[enc.name] = &file{path: v.path, etag: v.etag, contentType: f.contentType}
+		}
+	}
+	return h, nil
+}
+
+func contentType(name string, b []byte) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(b)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	f := h.lookup(req)
+	if f == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	hdr := w.Header()
+	serve := f
+	if len(f.variants) > 0 {
+		hdr.Add("Vary", "Accept-Encoding")
+		accepted := acceptedEncodings(req.Header.Get("Accept-Encoding"))
+		for _, enc := range encodings {
+			if v, ok := f.variants[enc.name]; ok && accepted[enc.name] {
+				hdr.Set("Content-Encoding", enc.name)
+				serve = v
+				break
+			}
+		}
+	}
+
+	content, err := h.open(serve.path)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if c, ok := content.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	hdr.Set("Content-Type", f.contentType)
+	hdr.Set("ETag", serve.etag)
+	if h.CacheControl != "" {
+		hdr.Set("Cache-Control", h.CacheControl)
+	}
+	// Embedded files have no modification time, so validation is by ETag only.
+	http.ServeContent(w, req, f.path, time.Time{}, content)
+}
+
+// lookup maps the request path to a file: the file itself, the index.html of
+// a directory, or the SPA fallback.
+func (h *Handler) lookup(req *http.Request) *file {
+	name := strings.TrimPrefix(path.Clean("/"+req.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+	if f, ok := h.files[name]; ok {
+		return f
+	}
+	if f, ok := h.files[path.Join(name, "index.html")]; ok {
+		return f
+	}
+	if h.Fallback != "" && path.Ext(name) == "" && acceptsHTML(req) {
+		return h.files[h.Fallback]
+	}
+	return nil
+}
+
+// open returns the file's content as an io.ReadSeeker. embed.FS files can
+// seek already; anything else is read into memory.
+func (h *Handler) open(name string) (io.ReadSeeker, error) {
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+func acceptsHTML(req *http.Request) bool {
+	accept := req.Header.Get("Accept")
+	return accept == "" || strings.Contains(accept, "text/html") || strings.Contains(accept, "*/*")
+}
+
+// acceptedEncodings parses Accept-Encoding, leaving out codings the client
+// refused with q=0.
+func acceptedEncodings(header string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.TrimSpace(k) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		accepted[name] = q > 0
+	}
+	if accepted["*"] {
+		for _, enc := range encodings {
+			if _, set := accepted[enc.name]; !set {
+				accepted[enc.name] = true
+			}
+		}
+	}
+	return accepted
+}
@@ -0,0 +1,200 @@
+package static
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+var tree = fstest.MapFS{
+	"index.html":      {Data: []byte("<h1>home</h1>")},
+	"app.js":          {Data: []byte("console.log('plain')")},
+	"app.js.gz":       {Data: []byte("gzip bytes")},
+	"app.js.br":       {Data: []byte("brotli bytes")},
+	"docs/index.html": {Data: []byte("<h1>docs</h1>")},
+	"data.txt":        {Data: []byte("0123456789")},
+}
+
+func newHandler(t *testing.T) *Handler {
+	t.Helper()
+	h, err := New(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Fallback = "index.html"
+	return h
+}
+
+func serve(h http.Handler, method, target string, header ...string) *http.Response {
+	req := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func body(resp *http.Response) string {
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+
+func TestLookup(t *testing.T) {
+	h := newHandler(t)
+	var tests = []struct {
+		path   string
+		header []string
+		status int
+		body   string
+	}{
+		{"/", nil, 200, "<h1>home</h1>"},
+		{"/docs/", nil, 200, "<h1>docs</h1>"},
+		{"/docs", nil, 200, "<h1>docs</h1>"},
+		{"/data.txt", nil, 200, "0123456789"},
+		{"/../data.txt", nil, 200, "0123456789"},
+		{"/users/42", []string{"Accept", "text/html,application/xhtml+xml"}, 200, "<h1>home</h1>"},
+		{"/users/42", nil, 200, "<h1>home</h1>"},
+		{"/users/42", []string{"Accept", "application/json"}, 404, "404 page not found\n"},
+		{"/missing.js", []string{"Accept", "text/html"}, 404, "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		resp := serve(h, "GET", tt.path, tt.header...)
+		if got := body(resp); resp.StatusCode != tt.status || got != tt.body {
+			t.Errorf("GET %s %v: got %d %q, want %d %q", tt.path, tt.header, resp.StatusCode, got, tt.status, tt.body)
+		}
+	}
+
+	h.Fallback = ""
+	if resp := serve(h, "GET", "/users/42", "Accept", "text/html"); resp.StatusCode != 404 {
+		t.Errorf("fallback disabled: got %d, want 404", resp.StatusCode)
+	}
+	if resp := serve(h, "POST", "/"); resp.StatusCode != 405 || resp.Header.Get("Allow") != "GET, HEAD" {
+		t.Errorf("POST: got %d Allow %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+}
+
+func TestContentType(t *testing.T) {
+	h := newHandler(t)
+	var tests = []struct{ path, want string }{
+		{"/index.html", "text/html; charset=utf-8"},
+		{"/app.js", "text/javascript; charset=utf-8"},
+		{"/data.txt", "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		if got := serve(h, "GET", tt.path).Header.Get("Content-Type"); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPrecompressed(t *testing.T) {
+	h := newHandler(t)
+	var tests = []struct {
+		accept   string
+		encoding string
+		body     string
+	}{
+		{"", "", "console.log('plain')"},
+		{"gzip", "gzip", "gzip bytes"},
+		{"gzip, br", "br", "brotli bytes"},
+		{"br;q=0, gzip", "gzip", "gzip bytes"},
+		{"br;q=0, gzip;q=0", "", "console.log('plain')"},
+		{"*", "br", "brotli bytes"},
+		{"*, br;q=0", "gzip", "gzip bytes"},
+		{"identity", "", "console.log('plain')"},
+	}
+	etags := make(map[string]string)
+	for _, tt := range tests {
+		resp := serve(h, "GET", "/app.js", "Accept-Encoding", tt.accept)
+		if got := resp.Header.Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%q: got encoding %q, want %q", tt.accept, got, tt.encoding)
+		}
+		if got := body(resp); got != tt.body {
+			t.Errorf("%q: got body %q, want %q", tt.accept, got, tt.body)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "text/javascript; charset=utf-8" {
+			t.Errorf("%q: got type %q", tt.accept, got)
+		}
+		if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%q: got Vary %q", tt.accept, got)
+		}
+		etags[tt.body] = resp.Header.Get("ETag")
+	}
+	if len(etags) != 3 || etags["gzip bytes"] == etags["brotli bytes"] || etags["gzip bytes"] == etags["console.log('plain')"] {
+		t.Errorf("each variant should have its own ETag, got %v", etags)
+	}
+
+	if got := serve(h, "GET", "/data.txt", "Accept-Encoding", "gzip").Header.Get("Vary"); got != "" {
+		t.Errorf("file without variants: got Vary %q", got)
+	}
+}
+
+func TestETag(t *testing.T) {
+	h := newHandler(t)
+	resp := serve(h, "GET", "/data.txt")
+	etag := resp.Header.Get("ETag")
+	if len(etag) != 34 || etag[0] != '"' {
+		t.Fatalf("got ETag %q, want a quoted 32-digit hash", etag)
+	}
+
+	h2 := newHandler(t)
+	if got := serve(h2, "GET", "/data.txt").Header.Get("ETag"); got != etag {
+		t.Errorf("ETag changed between handlers: %q, %q", etag, got)
+	}
+
+	var tests = []struct {
+		header, value string
+		status        int
+	}{
+		{"If-None-Match", etag, 304},
+		{"If-None-Match", `"other", ` + etag, 304},
+		{"If-None-Match", "W/" + etag, 304},
+		{"If-None-Match", `"other"`, 200},
+		{"If-None-Match", "*", 304},
+		{"If-Match", `"other"`, 412},
+		{"If-Match", etag, 200},
+	}
+	for _, tt := range tests {
+		resp := serve(h, "GET", "/data.txt", tt.header, tt.value)
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: %s: got %d, want %d", tt.header, tt.value, resp.StatusCode, tt.status)
+		}
+		if tt.status == 304 && body(resp) != "" {
+			t.Errorf("%s: %s: 304 with a body", tt.header, tt.value)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	h := newHandler(t)
+	etag := serve(h, "GET", "/data.txt").Header.Get("ETag")
+	var tests = []struct {
+		header []string
+		status int
+		body   string
+		cr     string
+	}{
+		{[]string{"Range", "bytes=2-5"}, 206, "2345", "bytes 2-5/10"},
+		{[]string{"Range", "bytes=7-"}, 206, "789", "bytes 7-9/10"},
+		{[]string{"Range", "bytes=-3"}, 206, "789", "bytes 7-9/10"},
+		{[]string{"Range", "bytes=20-"}, 416, "", "bytes */10"},
+		{[]string{"Range", "bytes=2-5", "If-Range", etag}, 206, "2345", "bytes 2-5/10"},
+		{[]string{"Range", "bytes=2-5", "If-Range", `"stale"`}, 200, "0123456789", ""},
+	}
+	for _, tt := range tests {
+		resp := serve(h, "GET", "/data.txt", tt.header...)
+		got := body(resp)
+		if resp.StatusCode != tt.status || resp.Header.Get("Content-Range") != tt.cr {
+			t.Errorf("%v: got %d %q, want %d %q", tt.header, resp.StatusCode, resp.Header.Get("Content-Range"), tt.status, tt.cr)
+		}
+		if tt.status != 416 && got != tt.body {
+			t.Errorf("%v: got body %q, want %q", tt.header, got, tt.body)
+		}
+	}
+	if got := serve(h, "HEAD", "/data.txt").Header.Get("Accept-Ranges"); got != "bytes" {
+		t.Errorf("got Accept-Ranges %q, want bytes", got)
+	}
+}
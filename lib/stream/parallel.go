@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"iter"
+	"sync"
+)
+
+// ParallelMap is Map with f run on up to workers goroutines at once, for
+// functions slow enough to be worth it: HTTP calls, hashing, image resizing.
+// Results come out in the same order as their inputs. At most workers values
+// are in flight, so a slow consumer holds back the reading of seq rather than
+// buffering without bound.
+//
+// seq is read from a goroutine of its own. Stopping the loop early stops
+// reading seq and waits for calls to f in progress before returning, so no
+// goroutines are left behind. ParallelMap panics if workers is less than 1;
+// a panic in f crashes the program, as in any goroutine.
+func ParallelMap[T, U any](seq iter.Seq[T], workers int, f func(T) U) iter.Seq[U] {
+	if workers < 1 {
+		panic("stream: ParallelMap needs at least 1 worker")
+	}
+	type job struct {
+		v      T
+		result chan U
+	}
+	return func(yield func(U) bool) {
+		// pending holds each job's result channel in input order; its
+		// capacity is what bounds the values in flight.
+		pending := make(chan chan U, workers)
+		jobs := make(chan job)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(pending)
+			defer close(jobs)
+			for v := range seq {
+				result := make(chan U, 1)
+				select {
+				case pending <- result:
+				case <-done:
+					return
+				}
+				select {
+				case jobs <- job{v, result}:
+				case <-done:
+					return
+				}
+			}
+		}()
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range jobs {
+					j.result <- f(j.v)
+				}
+			}()
+		}
+
+		defer wg.Wait()
+		for result := range pending {
+			if !yield(<-result) {
+				close(done)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelMapOrder(t *testing.T) {
+	in := make([]int, 100)
+	for i := range in {
+		in[i] = i
+	}
+	// Later values finish first, so only reordering keeps the output sorted.
+	got := slices.Collect(ParallelMap(slices.Values(in), 8, func(n int) int {
+		time.Sleep(time.Duration(100-n) * 20 * time.Microsecond)
+		return n * 2
+	}))
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("got[%d] = %d; output %v", i, v, got)
+		}
+	}
+	if len(got) != 100 {
+		t.Errorf("got %d values", len(got))
+	}
+}
+
+func TestParallelMapBounded(t *testing.T) {
+	var running, peak atomic.Int32
+	f := func(n int) int {
+		r := running.Add(1)
+		for {
+			p := peak.Load()
+			if r <= p || peak.CompareAndSwap(p, r) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return n
+	}
+	for range ParallelMap(slices.Values(make([]int, 50)), 4, f) {
+	}
+	if p := peak.Load(); p > 4 || p < 2 {
+		t.Errorf("peak concurrency %d, want 2 to 4", p)
+	}
+}
+
+func TestParallelMapEarlyStop(t *testing.T) {
+	before := runtime.NumGoroutine()
+	var read atomic.Int32
+	endless := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			read.Add(1)
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	var got []int
+	for v := range ParallelMap(endless, 4, func(n int) int { return n + 1 }) {
+		got = append(got, v)
+		if len(got) == 10 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Errorf("got %v", got)
+	}
+	// In flight is bounded by the workers, plus one value being handed over.
+	if r := read.Load(); r > 10+4+2 {
+		t.Errorf("read %d source values for 10 results", r)
+	}
+	// Every goroutine has finished by the time the loop returns.
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines before, %d after", before, after)
+	}
+}
+
+func TestParallelMapPanicsOnZeroWorkers(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("ParallelMap with 0 workers did not panic")
+		}
+	}()
+	ParallelMap(slices.Values([]int{1}), 0, func(n int) int { return n })
+}
+
+func BenchmarkParallelMap(b *testing.B) {
+	in := make([]int, 100)
+	slow := func(n int) int {
+		time.Sleep(10 * time.Microsecond)
+		return n
+	}
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for range ParallelMap(slices.Values(in), workers, slow) {
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Package stream is a small functional toolkit over Go 1.23 iterators: Map,
+// Filter, FlatMap, Reduce, GroupBy, Chunk, Window, Zip and Distinct, plus a
+// bounded, order-preserving ParallelMap.
+//
+// Everything works on iter.Seq. Slices, maps and channels turn into one and
+// back, so the same pipeline serves all of them:
+//
+//	words := stream.Filter(slices.Values(lines), notEmpty) // from a slice
+//	words := stream.Filter(maps.Keys(counts), notEmpty)    // from a map
+//	words := stream.Filter(stream.FromChan(ch), notEmpty)  // from a channel
+//	upper := stream.Map(words, strings.ToUpper)
+//	out := slices.Collect(upper)        // to a slice
+//	out := stream.ToChan(ctx, upper, 0) // to a channel
+//
+// That replaces the loops Slice.go, Sorting.go and RangeOverChannels.go write
+// out by hand. Stages are lazy: nothing runs until the result is ranged over,
+// and stopping early stops every stage before it.
+package stream
+
+import (
+	"context"
+	"iter"
+)
+
+// Map returns a sequence of f applied to each value of seq.
+func Map[T, U any](seq iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a sequence of the values of seq that keep accepts.
+func Filter[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FlatMap returns a sequence of every value of every sequence f returns, one
+// for each value of seq.
+func FlatMap[T, U any](seq iter.Seq[T], f func(T) iter.Seq[U]) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			for u := range f(v) {
+				if !yield(u) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Reduce folds seq into a single value, starting from init.
+func Reduce[T, A any](seq iter.Seq[T], init A, f func(A, T) A) A {
+	acc := init
+	for v := range seq {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// GroupBy collects the values of seq by key. Each group keeps the order its
+// values arrived in.
+func GroupBy[T any, K comparable](seq iter.Seq[T], key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for v := range seq {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// Chunk returns a sequence of consecutive, non-overlapping slices of size
+// values from seq. The last chunk is shorter if the values run out. Every
+// chunk is a new slice. Chunk panics if size is less than 1.
+func Chunk[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size < 1 {
+		panic("stream: Chunk size must be at least 1")
+	}
+	return func(yield func([]T) bool) {
+		chunk := make([]T, 0, size)
+		for v := range seq {
+			chunk = append(chunk, v)
+			if len(chunk) == size {
+				if !yield(chunk) {
+					return
+				}
+				chunk = make([]T, 0, size)
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Window returns a sequence of sliding windows of size values: seq's values
+// 0 to size-1, then 1 to size, and so on. A seq shorter than size yields
+// nothing. Every window is a new slice. Window panics if size is less than 1.
+func Window[T any](seq iter.Seq[T], size int) iter.Seq[[]T] {
+	if size < 1 {
+		panic("stream: Window size must be at least 1")
+	}
+	return func(yield func([]T) bool) {
+		var buf []T
+		for v := range seq {
+			buf = append(buf, v)
+			if len(buf) < size {
+				continue
+			}
+			window := make([]T, size)
+			copy(window, buf[len(buf)-size:])
+			if !yield(window) {
+				return
+			}
+			// Keep the last size-1 values, reusing the buffer.
+			buf = append(buf[:0], buf[len(buf)-size+1:]...)
+		}
+	}
+}
+
+// Zip returns a sequence of pairs taking one value from a and one from b at
+// a time. It stops when either runs out.
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		next, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := next()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// Distinct returns a sequence of the values of seq with repeats dropped,
+// keeping the first of each.
+func Distinct[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return DistinctBy(seq, func(v T) T { return v })
+}
+
+// DistinctBy is like Distinct but compares values by key.
+func DistinctBy[T any, K comparable](seq iter.Seq[T], key func(T) K) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[K]struct{})
+		for v := range seq {
+			k := key(v)
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FromChan returns a sequence of the values received from ch until it is
+// closed.
+func FromChan[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ToChan runs seq in a new goroutine and sends its values on the returned
+// channel, which has a buffer of size buf and is closed at the end of seq.
+// Cancel ctx to stop early if the receiver goes away, or the goroutine
+// leaks.
+func ToChan[T any](ctx context.Context, seq iter.Seq[T], buf int) <-chan T {
+	ch := make(chan T, buf)
+	go func() {
+		defer close(ch)
+		for v := range seq {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,195 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMapFilter(t *testing.T) {
+	nums := slices.Values([]int{1, 2, 3, 4, 5, 6})
+	even := Filter(nums, func(n int) bool { return n%2 == 0 })
+	got := slices.Collect(Map(even, strconv.Itoa))
+	if !slices.Equal(got, []string{"2", "4", "6"}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	lines := slices.Values([]string{"a b", "", "c"})
+	got := slices.Collect(FlatMap(lines, func(l string) iter.Seq[string] {
+		return slices.Values(strings.Fields(l))
+	}))
+	if !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce(slices.Values([]int{1, 2, 3, 4}), 0, func(acc, n int) int { return acc + n })
+	if sum != 10 {
+		t.Errorf("sum = %d", sum)
+	}
+	joined := Reduce(slices.Values([]int{1, 2}), "", func(acc string, n int) string { return acc + strconv.Itoa(n) })
+	if joined != "12" {
+		t.Errorf("joined = %q", joined)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	fruits := slices.Values([]string{"peach", "banana", "kiwi", "plum", "apple"})
+	got := GroupBy(fruits, func(s string) int { return len(s) })
+	want := map[int][]string{5: {"peach", "apple"}, 6: {"banana"}, 4: {"kiwi", "plum"}}
+	if !maps.EqualFunc(got, want, slices.Equal[[]string]) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestChunkWindow(t *testing.T) {
+	var tests = []struct {
+		name string
+		fn   func(iter.Seq[int], int) iter.Seq[[]int]
+		in   []int
+		size int
+		want string
+	}{
+		{"Chunk", Chunk[int], []int{1, 2, 3, 4, 5}, 2, "[[1 2] [3 4] [5]]"},
+		{"Chunk", Chunk[int], []int{1, 2, 3, 4}, 2, "[[1 2] [3 4]]"},
+		{"Chunk", Chunk[int], nil, 3, "[]"},
+		{"Window", Window[int], []int{1, 2, 3, 4}, 2, "[[1 2] [2 3] [3 4]]"},
+		{"Window", Window[int], []int{1, 2, 3}, 3, "[[1 2 3]]"},
+		{"Window", Window[int], []int{1, 2}, 3, "[]"},
+		{"Window", Window[int], []int{1, 2, 3}, 1, "[[1] [2] [3]]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s(%v,%d)", tt.name, tt.in, tt.size), func(t *testing.T) {
+			got := [][]int{}
+			for c := range tt.fn(slices.Values(tt.in), tt.size) {
+				got = append(got, c)
+			}
+			if s := fmt.Sprint(got); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowsAreCopies(t *testing.T) {
+	var windows [][]int
+	for w := range Window(slices.Values([]int{1, 2, 3, 4, 5}), 2) {
+		windows = append(windows, w)
+	}
+	if fmt.Sprint(windows) != "[[1 2] [2 3] [3 4] [4 5]]" {
+		t.Errorf("windows changed after being yielded: %v", windows)
+	}
+}
+
+func TestZip(t *testing.T) {
+	var got []string
+	for n, s := range Zip(slices.Values([]int{1, 2, 3}), slices.Values([]string{"a", "b"})) {
+		got = append(got, fmt.Sprintf("%d %s", n, s))
+	}
+	if !slices.Equal(got, []string{"1 a", "2 b"}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	got := slices.Collect(Distinct(slices.Values([]int{3, 1, 3, 2, 1})))
+	if !slices.Equal(got, []int{3, 1, 2}) {
+		t.Errorf("Distinct = %v", got)
+	}
+	got2 := slices.Collect(DistinctBy(slices.Values([]string{"Go", "go", "Rust", "GO"}), strings.ToLower))
+	if !slices.Equal(got2, []string{"Go", "Rust"}) {
+		t.Errorf("DistinctBy = %q", got2)
+	}
+}
+
+func TestEarlyStop(t *testing.T) {
+	// Counting how many source values get read shows each stage stops as
+	// soon as the consumer does.
+	read := 0
+	src := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			read++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	pipeline := Map(Filter(src, func(n int) bool { return n%2 == 0 }), func(n int) int { return n * 10 })
+	var got []int
+	for v := range pipeline {
+		got = append(got, v)
+		if len(got) == 3 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{0, 20, 40}) || read != 5 {
+		t.Errorf("got %v after reading %d values", got, read)
+	}
+}
+
+func TestChannels(t *testing.T) {
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	out := ToChan(context.Background(), Map(FromChan(in), func(n int) int { return n * n }), 0)
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 4, 9}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestToChanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	endless := func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}
+	ch := ToChan(ctx, endless, 0)
+	<-ch
+	cancel()
+	// The channel is closed once the goroutine notices; drain until then.
+	for range ch {
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	nums := make([]int, 1000)
+	for i := range nums {
+		nums[i] = i
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		even := Filter(slices.Values(nums), func(n int) bool { return n%2 == 0 })
+		Reduce(Map(even, func(n int) int { return n * n }), 0, func(a, n int) int { return a + n })
+	}
+}
+
+func BenchmarkLoop(b *testing.B) {
+	nums := make([]int, 1000)
+	for i := range nums {
+		nums[i] = i
+	}
+	for i := 0; i < b.N; i++ {
+		sum := 0
+		for _, n := range nums {
+			if n%2 == 0 {
+				sum += n * n
+			}
+		}
+	}
+}
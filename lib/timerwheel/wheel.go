@@ -0,0 +1,340 @@
+// Package timerwheel schedules large numbers of timeouts cheaply with a
+// hierarchical timing wheel.
+//
+// Timers.go and Timeouts.go give every wait its own time.Timer, which is
+// fine for a handful but costs a runtime timer each when a server tracks a
+// deadline per connection or session. A Wheel keeps its timers in rings of
+// slots instead, one ring per level, each slot of a level covering a whole
+// turn of the level below. Adding, stopping and resetting a timer are O(1),
+// and a tick only touches the slot it lands on, so hundreds of thousands of
+// pending timers cost little more than a few.
+//
+// The price is resolution: deadlines are rounded up to the wheel's tick, and
+// callbacks run one after another on the wheel's goroutine, so they must be
+// quick, or start a goroutine for slow work.
+package timerwheel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/clock"
+)
+
+const (
+	slotBits  = 6
+	numSlots  = 1 << slotBits // slots per level
+	slotMask  = numSlots - 1
+	numLevels = 6 // 64^6 ticks: over two years at 1ms
+)
+
+// Wheel is a hierarchical timing wheel. Create one with New and drive it
+// with Start, or with Advance in tests.
+type Wheel struct {
+	// Clock drives the wheel once started. New sets it to clock.Real;
+	// change it before Start.
+	Clock clock.Clock
+
+	tick time.Duration
+
+	mu      sync.Mutex
+	levels  [numLevels][numSlots]bucket
+	current uint64    // ticks processed so far
+	start   time.Time // wall time of tick 0, while running
+	running bool
+	stop    chan struct{}
+	done    chan struct{}
+	pending int
+}
+
+// bucket is a doubly-linked list of timers, with a sentinel for its head.
+type bucket struct {
+	root Timer
+}
+
+func (b *bucket) init() {
+	if b.root.next == nil {
+		b.root.next = &b.root
+		b.root.prev = &b.root
+	}
+}
+
+func (b *bucket) push(t *Timer) {
+	b.init()
+	t.bucket = b
+	t.prev = b.root.prev
+	t.next = &b.root
+	b.root.prev.next = t
+	b.root.prev = t
+}
+
+func (b *bucket) remove(t *Timer) {
+	t.prev.next = t.next
+	t.next.prev = t.prev
+	t.next, t.prev, t.bucket = nil, nil, nil
+}
+
+// take empties the bucket and returns its timers in the order added.
+func (b *bucket) take() []*Timer {
+	var ts []*Timer
+	if b.root.next == nil {
+		return nil
+	}
+	for t := b.root.next; t != &b.root; {
+		next := t.next
+		t.next, t.prev, t.bucket = nil, nil, nil
+		ts = append(ts, t)
+		t = next
+	}
+	b.root.next = &b.root
+	b.root.prev = &b.root
+	return ts
+}
+
+// New returns a stopped wheel with the given tick, which is both the
+// resolution of its deadlines and how often it wakes up. 1ms to 100ms suits
+// network timeouts.
+func New(tick time.Duration) *Wheel {
+	if tick <= 0 {
+		panic("timerwheel: tick must be positive")
+	}
+	return &Wheel{Clock: clock.Real, tick: tick}
+}
+
+// Tick returns the wheel's tick.
+func (w *Wheel) Tick() time.Duration { return w.tick }
+
+// Len returns the number of pending timers.
+func (w *Wheel) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pending
+}
+
+// Start starts a goroutine that advances the wheel in step with the wall
+// clock. It does nothing if the wheel is already running.
+func (w *Wheel) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.running {
+		return
+	}
+	w.running = true
+	w.start = w.Clock.Now().Add(-time.Duration(w.current) * w.tick)
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.run(w.stop, w.done)
+}
+
+// Stop stops the goroutine Start started and waits for it to finish. Pending
+// timers stay pending, and fire late if the wheel is started again.
+func (w *Wheel) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = false
+	stop, done := w.stop, w.done
+	w.mu.Unlock()
+	close(stop)
+	<-done
+}
+
+func (w *Wheel) run(stop, done chan struct{}) {
+	defer close(done)
+	ticker := w.Clock.NewTicker(w.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			// Go by the clock rather than the tick's time: a late tick
+			// catches up on every tick it missed, including ones the
+			// ticker dropped.
+			now := w.Clock.Now()
+			w.mu.Lock()
+			target := uint64(now.Sub(w.start) / w.tick)
+			w.mu.Unlock()
+			w.advanceTo(target)
+		}
+	}
+}
+
+// Advance moves a stopped wheel forward by d, firing timers on the way as
+// Start's goroutine would. It is for tests and simulations; don't mix it with
+// Start.
+func (w *Wheel) Advance(d time.Duration) {
+	w.mu.Lock()
+	target := w.current + uint64(d/w.tick)
+	w.mu.Unlock()
+	w.advanceTo(target)
+}
+
+func (w *Wheel) advanceTo(target uint64) {
+	for {
+		w.mu.Lock()
+		if w.current >= target {
+			w.mu.Unlock()
+			return
+		}
+		expired := w.step()
+		w.mu.Unlock()
+		for _, t := range expired {
+			t.fire()
+		}
+	}
+}
+
+// step processes one tick: it cascades the higher levels whose slot turns
+// over on this tick down towards level 0, then takes level 0's slot. w.mu
+// must be held.
+func (w *Wheel) step() []*Timer {
+	w.current++
+	now := w.current
+	for level := numLevels - 1; level > 0; level-- {
+		if now&(1<<(slotBits*level)-1) != 0 {
+			continue
+		}
+		slot := (now >> (slotBits * level)) & slotMask
+		for _, t := range w.levels[level][slot].take() {
+			w.place(t)
+		}
+	}
+	expired := w.levels[0][now&slotMask].take()
+	w.pending -= len(expired)
+	for _, t := range expired {
+		t.active = false
+	}
+	return expired
+}
+
+// place files t in the slot for its deadline, which must not be before the
+// current tick. A deadline of the current tick only happens while cascading,
+// and lands in the level 0 slot step is about to take. w.mu must be held.
+func (w *Wheel) place(t *Timer) {
+	delta := t.expires - w.current
+	for level := 0; level < numLevels; level++ {
+		if delta < 1<<(slotBits*(level+1)) {
+			slot := (t.expires >> (slotBits * level)) & slotMask
+			w.levels[level][slot].push(t)
+			return
+		}
+	}
+	// Beyond the top level: park it in the top level's furthest slot and
+	// let cascading place it again when that comes round.
+	top := numLevels - 1
+	slot := ((w.current >> (slotBits * top)) + slotMask) & slotMask
+	w.levels[top][slot].push(t)
+}
+
+// deadline converts a duration from now into an absolute tick, rounding up
+// so a timer never fires before d has passed. w.mu must be held.
+func (w *Wheel) deadline(d time.Duration) uint64 {
+	elapsed := time.Duration(w.current) * w.tick
+	if w.running {
+		elapsed = w.Clock.Since(w.start)
+	}
+	at := elapsed + max(d, 0)
+	ticks := uint64(at / w.tick)
+	if at%w.tick != 0 {
+		ticks++
+	}
+	// The current tick's slot has already been taken.
+	return max(ticks, w.current+1)
+}
+
+// Timer is a pending call or channel send on a Wheel.
+type Timer struct {
+	// C receives the time when a timer made by NewTimer fires. It is nil
+	// for timers made by AfterFunc.
+	C <-chan time.Time
+
+	w       *Wheel
+	f       func()
+	c       chan time.Time
+	expires uint64
+	active  bool
+
+	next, prev *Timer
+	bucket     *bucket
+}
+
+// AfterFunc calls f on the wheel's goroutine once d has passed, rounded up to
+// the wheel's tick.
+func (w *Wheel) AfterFunc(d time.Duration, f func()) *Timer {
+	t := &Timer{w: w, f: f}
+	w.schedule(t, d)
+	return t
+}
+
+// NewTimer returns a timer that sends the current time on its C once d has
+// passed, rounded up to the wheel's tick.
+func (w *Wheel) NewTimer(d time.Duration) *Timer {
+	c := make(chan time.Time, 1)
+	t := &Timer{w: w, C: c, c: c}
+	w.schedule(t, d)
+	return t
+}
+
+// After is NewTimer(d).C.
+func (w *Wheel) After(d time.Duration) <-chan time.Time {
+	return w.NewTimer(d).C
+}
+
+func (w *Wheel) schedule(t *Timer, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	t.expires = w.deadline(d)
+	t.active = true
+	w.pending++
+	w.place(t)
+}
+
+func (t *Timer) fire() {
+	if t.f != nil {
+		t.f()
+		return
+	}
+	select {
+	case t.c <- t.w.Clock.Now():
+	default:
+	}
+}
+
+// Stop prevents the timer from firing. It reports whether it did so, false
+// meaning the timer had already fired or been stopped.
+func (t *Timer) Stop() bool {
+	w := t.w
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.unschedule(t)
+}
+
+func (w *Wheel) unschedule(t *Timer) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	w.pending--
+	// A timer in the middle of a cascade has no bucket for a moment, but
+	// cascades run under w.mu, so that is never seen here.
+	t.bucket.remove(t)
+	return true
+}
+
+// Reset changes the timer to fire once d has passed from now, whether or not
+// it was still pending, and reports whether it was. As with time.Timer, a
+// value a NewTimer timer already sent may still be waiting in C.
+func (t *Timer) Reset(d time.Duration) bool {
+	w := t.w
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	was := w.unschedule(t)
+	t.expires = w.deadline(d)
+	t.active = true
+	w.pending++
+	w.place(t)
+	return was
+}
@@ -0,0 +1,333 @@
+package timerwheel
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prashant1k99/GoLearn/lib/clock"
+)
+
+func TestAfterFuncFiresOnItsTick(t *testing.T) {
+	var tests = []struct {
+		d        time.Duration
+		wantTick uint64
+	}{
+		{0, 1},
+		{time.Millisecond, 1},
+		{1500 * time.Microsecond, 2}, // rounded up
+		{63 * time.Millisecond, 63},
+		{64 * time.Millisecond, 64}, // first tick on level 1
+		{65 * time.Millisecond, 65},
+		{4096 * time.Millisecond, 4096}, // first tick on level 2
+		{5000 * time.Millisecond, 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			w := New(time.Millisecond)
+			var firedAt uint64
+			w.AfterFunc(tt.d, func() { firedAt = w.current })
+			w.Advance(time.Duration(tt.wantTick-1) * time.Millisecond)
+			if firedAt != 0 {
+				t.Fatalf("fired early, on tick %d", firedAt)
+			}
+			w.Advance(time.Millisecond)
+			if firedAt != tt.wantTick {
+				t.Errorf("fired on tick %d, want %d", firedAt, tt.wantTick)
+			}
+			if w.Len() != 0 {
+				t.Errorf("Len() = %d after firing", w.Len())
+			}
+		})
+	}
+}
+
+// TestRandomDeadlines schedules timers at random ticks, including ones that
+// start after the wheel has moved and ones that cascade through several
+// levels, and checks each fires exactly on its tick and in order.
+func TestRandomDeadlines(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	w := New(time.Millisecond)
+	const horizon = 3 * numSlots * numSlots * numSlots // into level 3
+
+	var fired, late int
+	var last uint64
+	schedule := func() {
+		d := time.Duration(r.Intn(horizon/2)) * time.Millisecond
+		want := w.current + uint64(d/time.Millisecond)
+		if d == 0 {
+			want++
+		}
+		w.AfterFunc(d, func() {
+			fired++
+			if w.current != want {
+				late++
+			}
+			if w.current < last {
+				t.Errorf("tick %d fired after tick %d", w.current, last)
+			}
+			last = w.current
+		})
+	}
+	for i := 0; i < 5000; i++ {
+		schedule()
+	}
+	// Move part way and schedule more from an odd offset.
+	w.Advance(12345 * time.Millisecond)
+	for i := 0; i < 5000; i++ {
+		schedule()
+	}
+	w.Advance(horizon * time.Millisecond)
+	if fired != 10000 || late != 0 {
+		t.Errorf("fired %d of 10000 timers, %d on the wrong tick", fired, late)
+	}
+}
+
+func TestStop(t *testing.T) {
+	w := New(time.Millisecond)
+	fired := false
+	tm := w.AfterFunc(100*time.Millisecond, func() { fired = true })
+	w.Advance(50 * time.Millisecond)
+	if !tm.Stop() {
+		t.Error("Stop on a pending timer returned false")
+	}
+	if tm.Stop() {
+		t.Error("second Stop returned true")
+	}
+	w.Advance(time.Second)
+	if fired || w.Len() != 0 {
+		t.Errorf("fired=%v, Len()=%d after Stop", fired, w.Len())
+	}
+}
+
+func TestStopAfterCascade(t *testing.T) {
+	// The timer moves from level 2 to 1 to 0 before it is stopped.
+	w := New(time.Millisecond)
+	fired := false
+	tm := w.AfterFunc(10000*time.Millisecond, func() { fired = true })
+	w.Advance(9990 * time.Millisecond)
+	if !tm.Stop() {
+		t.Error("Stop returned false")
+	}
+	w.Advance(time.Minute)
+	if fired {
+		t.Error("stopped timer fired")
+	}
+}
+
+func TestReset(t *testing.T) {
+	w := New(time.Millisecond)
+	var firedAt uint64
+	tm := w.AfterFunc(10*time.Millisecond, func() { firedAt = w.current })
+	w.Advance(5 * time.Millisecond)
+	if !tm.Reset(100 * time.Millisecond) {
+		t.Error("Reset of a pending timer returned false")
+	}
+	w.Advance(time.Second)
+	if firedAt != 105 {
+		t.Errorf("fired on tick %d, want 105", firedAt)
+	}
+	if tm.Reset(time.Millisecond) {
+		t.Error("Reset of a fired timer returned true")
+	}
+	w.Advance(time.Millisecond)
+	if firedAt != 1006 {
+		t.Errorf("fired again on tick %d, want 1006", firedAt)
+	}
+}
+
+func TestNewTimer(t *testing.T) {
+	w := New(time.Millisecond)
+	tm := w.NewTimer(3 * time.Millisecond)
+	after := w.After(5 * time.Millisecond)
+	w.Advance(3 * time.Millisecond)
+	select {
+	case <-tm.C:
+	default:
+		t.Error("NewTimer didn't send on C")
+	}
+	select {
+	case <-after:
+		t.Error("After sent early")
+	default:
+	}
+	w.Advance(2 * time.Millisecond)
+	select {
+	case <-after:
+	default:
+		t.Error("After didn't send")
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	w := New(time.Millisecond)
+	w.Start()
+	defer w.Stop()
+
+	start := time.Now()
+	var n atomic.Int32
+	done := make(chan time.Duration, 1)
+	for i := 0; i < 1000; i++ {
+		w.AfterFunc(20*time.Millisecond, func() {
+			if n.Add(1) == 1000 {
+				done <- time.Since(start)
+			}
+		})
+	}
+	select {
+	case elapsed := <-done:
+		if elapsed < 20*time.Millisecond {
+			t.Errorf("fired after %v, before the 20ms deadline", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("only %d of 1000 timers fired", n.Load())
+	}
+
+	select {
+	case <-w.After(5 * time.Millisecond):
+	case <-time.After(5 * time.Second):
+		t.Fatal("After never fired")
+	}
+}
+
+// The benchmarks compare the wheel with time.Timer for the usual timeout
+// pattern: arm a timer per request and stop it when the request finishes,
+// with many other timers pending.
+
+func BenchmarkWheelAfterFuncStop(b *testing.B) {
+	w := New(time.Millisecond)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.AfterFunc(time.Minute, func() {}).Stop()
+	}
+}
+
+func BenchmarkTimeAfterFuncStop(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		time.AfterFunc(time.Minute, func() {}).Stop()
+	}
+}
+
+func BenchmarkWheelReset(b *testing.B) {
+	w := New(time.Millisecond)
+	tm := w.AfterFunc(time.Minute, func() {})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tm.Reset(time.Minute)
+	}
+}
+
+func BenchmarkTimeReset(b *testing.B) {
+	tm := time.AfterFunc(time.Minute, func() {})
+	defer tm.Stop()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tm.Reset(time.Minute)
+	}
+}
+
+const pending = 200000
+
+func BenchmarkWheel200kPending(b *testing.B) {
+	w := New(time.Millisecond)
+	w.Start()
+	defer w.Stop()
+	for i := 0; i < pending; i++ {
+		w.AfterFunc(time.Duration(10+i%50)*time.Second, func() {})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.AfterFunc(30*time.Second, func() {}).Stop()
+	}
+}
+
+func BenchmarkTime200kPending(b *testing.B) {
+	timers := make([]*time.Timer, pending)
+	for i := range timers {
+		timers[i] = time.AfterFunc(time.Duration(10+i%50)*time.Second, func() {})
+	}
+	defer func() {
+		for _, t := range timers {
+			t.Stop()
+		}
+	}()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		time.AfterFunc(30*time.Second, func() {}).Stop()
+	}
+}
+
+// BenchmarkWheelSchedule200k and BenchmarkTimeSchedule200k measure arming
+// 200k timeouts at once, as a server does when a burst of connections
+// arrives.
+func BenchmarkWheelSchedule200k(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w := New(time.Millisecond)
+		for j := 0; j < pending; j++ {
+			w.AfterFunc(time.Duration(10+j%50)*time.Second, func() {})
+		}
+	}
+}
+
+func BenchmarkTimeSchedule200k(b *testing.B) {
+	b.ReportAllocs()
+	timers := make([]*time.Timer, pending)
+	for i := 0; i < b.N; i++ {
+		for j := range timers {
+			timers[j] = time.AfterFunc(time.Duration(10+j%50)*time.Second, func() {})
+		}
+		b.StopTimer()
+		for _, t := range timers {
+			t.Stop()
+		}
+		b.StartTimer()
+	}
+}
+
+func BenchmarkWheelParallel(b *testing.B) {
+	w := New(time.Millisecond)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			w.AfterFunc(time.Minute, func() {}).Stop()
+		}
+	})
+}
+
+func BenchmarkTimeParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			time.AfterFunc(time.Minute, func() {}).Stop()
+		}
+	})
+}
+
+func TestStartWithFakeClock(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 8, 18, 0, 0, 0, 0, time.UTC))
+	w := New(10 * time.Millisecond)
+	w.Clock = c
+	w.Start()
+	defer w.Stop()
+	c.BlockUntil(1) // the wheel's ticker
+
+	start := c.Now()
+	timer := w.NewTimer(25 * time.Millisecond) // rounded up to tick 3
+	for i := 0; i < 3; i++ {
+		c.Advance(10 * time.Millisecond)
+	}
+	select {
+	case got := <-timer.C:
+		if want := start.Add(30 * time.Millisecond); !got.Equal(want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer never fired")
+	}
+}
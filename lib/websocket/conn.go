@@ -0,0 +1,514 @@
+// Package websocket is an RFC 6455 WebSocket implementation on top of
+// net/http, with no third-party dependencies.
+//
+// HTTPServer.go answers one request with one response. Upgrade takes over the
+// connection behind a request instead, after which both sides may send
+// messages whenever they like:
+//
+//	var up websocket.Upgrader
+//	http.HandleFunc("/ws", func(w http.ResponseWriter, req *http.Request) {
+//		conn, err := up.Upgrade(w, req)
+//		if err != nil {
+//			return
+//		}
+//		defer conn.Close(websocket.CloseNormal, "")
+//		for {
+//			typ, msg, err := conn.ReadMessage()
+//			if err != nil {
+//				return
+//			}
+//			conn.WriteMessage(typ, msg) // echo
+//		}
+//	})
+//
+// Conn handles framing, masking, fragmented messages, ping/pong and the close
+// handshake. Hub builds rooms and broadcasting on top of it.
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the kind of a data message. The values are the opcodes used
+// on the wire.
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+// Frame opcodes from section 5.2.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close codes from section 7.4.1.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005 // never sent; reported when a close frame has no code
+	CloseAbnormal        = 1006 // never sent; reported when the connection drops
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+// maxControlPayload is the largest payload a ping, pong or close frame may carry.
+const maxControlPayload = 125
+
+// DefaultReadLimit is the message size limit of a Conn whose ReadLimit is
+// zero. Frame lengths come from the peer, so without a limit one header
+// claiming a terabyte would make the reader try to allocate it.
+const DefaultReadLimit = 32 << 20
+
+// readChunk is the most readFrame allocates ahead of the data arriving: a
+// longer payload is read into a buffer that grows as it comes in, so a peer
+// has to send the bytes it claims before they cost memory.
+const readChunk = 64 << 10
+
+// CloseError is returned by ReadMessage once the connection is closed. Code
+// is the status the peer sent, or the one this side failed the connection with.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Text)
+}
+
+// ErrClosed is returned by writes after the close frame has been sent.
+var ErrClosed = errors.New("websocket: connection closed")
+
+// Conn is a WebSocket connection. One goroutine may read while others write;
+// writes are serialized internally.
+type Conn struct {
+	conn     net.Conn
+	br       *bufio.Reader
+	isServer bool
+
+	// ReadLimit is the largest message ReadMessage accepts, summed over all of
+	// its fragments. Larger messages fail the connection with 1009, before
+	// their payload is read. Zero means DefaultReadLimit and a negative
+	// value means no limit, which is only safe with a trusted peer.
+	ReadLimit int64
+	// MaxFrameSize splits outgoing messages into fragments of at most this
+	// many bytes. Zero sends every message as a single frame.
+	MaxFrameSize int
+
+	writeMu   sync.Mutex
+	closeSent bool
+	readErr   error
+
+	pingHandler func(data []byte) error
+	pongHandler func(data []byte) error
+}
+
+func newConn(c net.Conn, br *bufio.Reader, isServer bool) *Conn {
+	if br == nil {
+		br = bufio.NewReader(c)
+	}
+	ws := &Conn{conn: c, br: br, isServer: isServer}
+	ws.pingHandler = func(data []byte) error {
+		// A failed pong is not worth failing the read over; if the transport
+		// is gone the next read reports it.
+		ws.writeFrame(true, opPong, data)
+		return nil
+	}
+	return ws
+}
+
+// SetPingHandler replaces the default reply to a ping, which is a pong with
+// the same payload. The handler runs on the reading goroutine.
+func (c *Conn) SetPingHandler(h func(data []byte) error) {
+	c.pingHandler = h
+}
+
+// SetPongHandler sets a func called for each pong received, typically to push
+// the read deadline forward.
+func (c *Conn) SetPongHandler(h func(data []byte) error) {
+	c.pongHandler = h
+}
+
+// SetReadDeadline sets the deadline for the underlying connection's reads.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for the underlying connection's writes.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// RemoteAddr returns the peer's network address.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// WriteMessage sends one text or binary message, fragmented according to
+// MaxFrameSize.
+func (c *Conn) WriteMessage(typ MessageType, data []byte) error {
+	if typ != TextMessage && typ != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", typ)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	op := byte(typ)
+	for {
+		n := len(data)
+		if c.MaxFrameSize > 0 && n > c.MaxFrameSize {
+			n = c.MaxFrameSize
+		}
+		fin := n == len(data)
+		if err := c.writeFrameLocked(fin, op, data[:n]); err != nil {
+			return err
+		}
+		if fin {
+			return nil
+		}
+		data = data[n:]
+		op = opContinuation
+	}
+}
+
+// WritePing sends a ping; the peer answers with a pong carrying the same data.
+func (c *Conn) WritePing(data []byte) error {
+	if len(data) > maxControlPayload {
+		return errors.New("websocket: ping payload too long")
+	}
+	return c.writeFrame(true, opPing, data)
+}
+
+// Close starts the closing handshake with the given status code and reason
+// and then closes the underlying connection. It is safe to call more than once.
+// CloseAbnormal drops the connection without sending a close frame.
+func (c *Conn) Close(code int, reason string) error {
+	var err error
+	if code != CloseAbnormal {
+		err = c.writeClose(code, reason)
+	}
+	if cerr := c.conn.Close(); err == nil && !errors.Is(cerr, net.ErrClosed) {
+		err = cerr
+	}
+	if errors.Is(err, ErrClosed) {
+		err = nil
+	}
+	return err
+}
+
+func (c *Conn) writeClose(code int, reason string) error {
+	var payload []byte
+	if code != CloseNoStatus {
+		payload = make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+		if len(payload) > maxControlPayload {
+			payload = payload[:maxControlPayload]
+		}
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.writeFrameLocked(true, opClose, payload); err != nil {
+		return err
+	}
+	c.closeSent = true
+	return nil
+}
+
+func (c *Conn) writeFrame(fin bool, op byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.writeFrameLocked(fin, op, payload)
+}
+
+// writeFrameLocked encodes one frame. Frames sent by a client are masked with
+// a fresh random key, as section 5.3 requires; server frames never are.
+func (c *Conn) writeFrameLocked(fin bool, op byte, payload []byte) error {
+	if c.closeSent {
+		return ErrClosed
+	}
+	header := make([]byte, 0, 14+len(payload))
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	header = append(header, b0)
+
+	var maskBit byte
+	if !c.isServer {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, maskBit|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, maskBit|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, maskBit|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if !c.isServer {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		header = append(header, key[:]...)
+		masked := make([]byte, len(payload))
+		copy(masked, payload)
+		maskBytes(key, masked)
+		payload = masked
+	}
+
+	_, err := c.conn.Write(append(header, payload...))
+	return err
+}
+
+// ReadMessage returns the next complete data message, reassembling fragments
+// and answering control frames that arrive in between. Once the connection
+// is closed, by either side, it returns a *CloseError.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+	typ, data, err := c.readMessage()
+	if err != nil {
+		c.readErr = err
+	}
+	return typ, data, err
+}
+
+// readLimit returns the effective ReadLimit, or -1 for none.
+func (c *Conn) readLimit() int64 {
+	switch {
+	case c.ReadLimit == 0:
+		return DefaultReadLimit
+	case c.ReadLimit < 0:
+		return -1
+	}
+	return c.ReadLimit
+}
+
+func (c *Conn) readMessage() (MessageType, []byte, error) {
+	var (
+		typ     MessageType
+		buf     []byte
+		started bool
+	)
+	limit := c.readLimit()
+	for {
+		// What is left of the limit for this message's next fragment.
+		room := int64(-1)
+		if limit >= 0 {
+			room = limit - int64(len(buf))
+		}
+		fin, op, payload, err := c.readFrame(room)
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case opPing, opPong, opClose:
+			if err := c.handleControl(op, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opContinuation:
+			if !started {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			if started {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			typ, started = MessageType(op), true
+		}
+		buf = append(buf, payload...)
+		if fin {
+			if typ == TextMessage && !utf8.Valid(buf) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid UTF-8 in text message")
+			}
+			if buf == nil {
+				buf = []byte{}
+			}
+			return typ, buf, nil
+		}
+	}
+}
+
+// readFrame reads and validates one frame header and its unmasked payload.
+// A data frame longer than room, unless room is negative, fails the
+// connection with 1009 before any of its payload is read.
+func (c *Conn) readFrame(room int64) (fin bool, op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, c.dropped(err)
+	}
+	fin = head[0]&0x80 != 0
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	op = head[0] & 0x0F
+	control := op&0x8 != 0
+	switch op {
+	case opContinuation, opText, opBinary, opClose, opPing, opPong:
+	default:
+		return false, 0, nil, c.fail(CloseProtocolError, fmt.Sprintf("unknown opcode %#x", op))
+	}
+
+	masked := head[1]&0x80 != 0
+	if masked != c.isServer {
+		// Clients must mask every frame and servers must never mask.
+		return false, 0, nil, c.fail(CloseProtocolError, "bad masking")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, c.dropped(err)
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, c.dropped(err)
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+		if length>>63 != 0 {
+			return false, 0, nil, c.fail(CloseProtocolError, "invalid frame length")
+		}
+	}
+	if control && (!fin || length > maxControlPayload) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if !control && room >= 0 && length > uint64(room) {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, key[:]); err != nil {
+			return false, 0, nil, c.dropped(err)
+		}
+	}
+	if length <= readChunk {
+		payload = make([]byte, length)
+		if _, err := io.ReadFull(c.br, payload); err != nil {
+			return false, 0, nil, c.dropped(err)
+		}
+	} else {
+		var b bytes.Buffer
+		b.Grow(readChunk)
+		if _, err := io.CopyN(&b, c.br, int64(length)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return false, 0, nil, c.dropped(err)
+		}
+		payload = b.Bytes()
+	}
+	if masked {
+		maskBytes(key, payload)
+	}
+	return fin, op, payload, nil
+}
+
+func (c *Conn) handleControl(op byte, payload []byte) error {
+	switch op {
+	case opPing:
+		if c.pingHandler != nil {
+			return c.pingHandler(payload)
+		}
+	case opPong:
+		if c.pongHandler != nil {
+			return c.pongHandler(payload)
+		}
+	case opClose:
+		code, text := CloseNoStatus, ""
+		switch {
+		case len(payload) == 1:
+			return c.fail(CloseProtocolError, "invalid close payload")
+		case len(payload) >= 2:
+			code = int(binary.BigEndian.Uint16(payload))
+			text = string(payload[2:])
+			if !validCloseCode(code) {
+				return c.fail(CloseProtocolError, "invalid close code")
+			}
+			if !utf8.ValidString(text) {
+				return c.fail(CloseInvalidPayload, "invalid UTF-8 in close reason")
+			}
+		}
+		// Echo the close and drop the connection: the handshake is done.
+		echo := code
+		if echo == CloseNoStatus {
+			echo = CloseNormal
+		}
+		c.writeClose(echo, "")
+		c.conn.Close()
+		return &CloseError{Code: code, Text: text}
+	}
+	return nil
+}
+
+// fail sends a close frame with code and closes the connection, as section
+// 7.1.7 asks of an endpoint that sees a protocol violation.
+func (c *Conn) fail(code int, reason string) error {
+	c.writeClose(code, reason)
+	c.conn.Close()
+	return &CloseError{Code: code, Text: reason}
+}
+
+// dropped reports a read error on the transport. A connection that vanishes
+// without a close frame is an abnormal closure.
+func (c *Conn) dropped(err error) error {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return err
+	}
+	c.conn.Close()
+	return &CloseError{Code: CloseAbnormal, Text: err.Error()}
+}
+
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003:
+		return true
+	case code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+// maskBytes applies (or removes, since XOR is its own inverse) the masking
+// key from section 5.3.
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i&3]
+	}
+}
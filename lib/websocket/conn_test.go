@@ -0,0 +1,307 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pair returns a server Conn and the raw client end of a loopback TCP
+// connection, for tests that write frames by hand.
+func pair(t *testing.T) (*Conn, net.Conn) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- c
+	}()
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := <-accepted
+	if server == nil {
+		t.Fatal("accept failed")
+	}
+	t.Cleanup(func() { client.Close(); server.Close() })
+	client.SetDeadline(time.Now().Add(10 * time.Second))
+	return newConn(server, nil, true), client
+}
+
+// frame encodes a frame as a client sends it, masked unless mask is false.
+// length, unless it is -1, is the length the header claims, whatever the
+// payload.
+func frame(fin bool, op byte, payload []byte, mask bool, length int64) []byte {
+	if length < 0 {
+		length = int64(len(payload))
+	}
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	var maskBit byte
+	if mask {
+		maskBit = 0x80
+	}
+	out := []byte{b0}
+	switch {
+	case length <= 125:
+		out = append(out, maskBit|byte(length))
+	case length <= 0xFFFF:
+		out = append(out, maskBit|126)
+		out = binary.BigEndian.AppendUint16(out, uint16(length))
+	default:
+		out = append(out, maskBit|127)
+		out = binary.BigEndian.AppendUint64(out, uint64(length))
+	}
+	p := bytes.Clone(payload)
+	if mask {
+		key := [4]byte{1, 2, 3, 4}
+		out = append(out, key[:]...)
+		maskBytes(key, p)
+	}
+	return append(out, p...)
+}
+
+func closePayload(code int, reason string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...)
+}
+
+// readServerFrame reads one unmasked frame from the server.
+func readServerFrame(t *testing.T, c net.Conn) (op byte, payload []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(c, head[:]); err != nil {
+		t.Fatalf("reading server frame: %v", err)
+	}
+	n := int(head[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(c, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(c, ext[:])
+		n = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+// closeCode returns the code of the close frame the server sent.
+func closeCode(t *testing.T, c net.Conn) int {
+	t.Helper()
+	op, p := readServerFrame(t, c)
+	if op != opClose || len(p) < 2 {
+		t.Fatalf("got opcode %#x payload %q, want a close frame", op, p)
+	}
+	return int(binary.BigEndian.Uint16(p))
+}
+
+func TestReadMessage(t *testing.T) {
+	var tests = []struct {
+		name   string
+		frames [][]byte
+		typ    MessageType
+		want   string
+	}{
+		{"text", [][]byte{frame(true, opText, []byte("hello"), true, -1)}, TextMessage, "hello"},
+		{"binary", [][]byte{frame(true, opBinary, []byte{0, 1, 2}, true, -1)}, BinaryMessage, "\x00\x01\x02"},
+		{"empty", [][]byte{frame(true, opText, nil, true, -1)}, TextMessage, ""},
+		{"fragmented", [][]byte{
+			frame(false, opText, []byte("hel"), true, -1),
+			frame(false, opContinuation, []byte("l"), true, -1),
+			frame(true, opContinuation, []byte("o"), true, -1),
+		}, TextMessage, "hello"},
+		{"ping between fragments", [][]byte{
+			frame(false, opBinary, []byte("ab"), true, -1),
+			frame(true, opPing, []byte("p"), true, -1),
+			frame(true, opContinuation, []byte("cd"), true, -1),
+		}, BinaryMessage, "abcd"},
+		{"16-bit length", [][]byte{frame(true, opBinary, bytes.Repeat([]byte("x"), 300), true, -1)}, BinaryMessage, strings.Repeat("x", 300)},
+		{"64-bit length", [][]byte{frame(true, opBinary, bytes.Repeat([]byte("y"), 70000), true, -1)}, BinaryMessage, strings.Repeat("y", 70000)},
+	}
+	for _, tt := range tests {
+		server, client := pair(t)
+		for _, f := range tt.frames {
+			client.Write(f)
+		}
+		typ, msg, err := server.ReadMessage()
+		if err != nil || typ != tt.typ || string(msg) != tt.want {
+			t.Errorf("%s: got %d %.20q %v, want %d %.20q", tt.name, typ, msg, err, tt.typ, tt.want)
+		}
+	}
+}
+
+func TestPingPong(t *testing.T) {
+	server, client := pair(t)
+	client.Write(frame(true, opPing, []byte("are you there"), true, -1))
+	client.Write(frame(true, opText, []byte("x"), true, -1))
+	if _, _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if op, p := readServerFrame(t, client); op != opPong || string(p) != "are you there" {
+		t.Errorf("got opcode %#x %q, want the pong", op, p)
+	}
+
+	var pongs []string
+	server.SetPongHandler(func(data []byte) error {
+		pongs = append(pongs, string(data))
+		return nil
+	})
+	client.Write(frame(true, opPong, []byte("late"), true, -1))
+	client.Write(frame(true, opText, []byte("x"), true, -1))
+	server.ReadMessage()
+	if len(pongs) != 1 || pongs[0] != "late" {
+		t.Errorf("pong handler saw %q", pongs)
+	}
+}
+
+// TestProtocolErrors checks that each violation fails the connection with
+// the close code section 7.4.1 gives for it.
+func TestProtocolErrors(t *testing.T) {
+	var tests = []struct {
+		name   string
+		limit  int64
+		frames [][]byte
+		code   int
+	}{
+		{"unmasked client frame", 0, [][]byte{frame(true, opText, []byte("hi"), false, -1)}, CloseProtocolError},
+		{"reserved bits", 0, [][]byte{append([]byte{0x80 | 0x40 | opText}, frame(true, opText, []byte("x"), true, -1)[1:]...)}, CloseProtocolError},
+		{"unknown opcode", 0, [][]byte{frame(true, 0x3, nil, true, -1)}, CloseProtocolError},
+		{"continuation first", 0, [][]byte{frame(true, opContinuation, []byte("x"), true, -1)}, CloseProtocolError},
+		{"new message mid-fragment", 0, [][]byte{
+			frame(false, opText, []byte("a"), true, -1),
+			frame(true, opText, []byte("b"), true, -1),
+		}, CloseProtocolError},
+		{"fragmented ping", 0, [][]byte{frame(false, opPing, nil, true, -1)}, CloseProtocolError},
+		{"long ping", 0, [][]byte{frame(true, opPing, bytes.Repeat([]byte("p"), 126), true, -1)}, CloseProtocolError},
+		{"invalid UTF-8", 0, [][]byte{frame(true, opText, []byte{0xff, 0xfe}, true, -1)}, CloseInvalidPayload},
+		{"one-byte close", 0, [][]byte{frame(true, opClose, []byte{3}, true, -1)}, CloseProtocolError},
+		{"bad close code", 0, [][]byte{frame(true, opClose, closePayload(999, ""), true, -1)}, CloseProtocolError},
+		{"reserved close code", 0, [][]byte{frame(true, opClose, closePayload(CloseAbnormal, ""), true, -1)}, CloseProtocolError},
+		{"close reason not UTF-8", 0, [][]byte{frame(true, opClose, closePayload(CloseNormal, "\xff"), true, -1)}, CloseInvalidPayload},
+
+		// Sizes. Only the header is sent: the server must refuse before
+		// reading, or allocating, the payload.
+		{"2^40 bytes with the default limit", 0, [][]byte{frame(true, opBinary, nil, true, 1<<40)}, CloseMessageTooBig},
+		{"over DefaultReadLimit", 0, [][]byte{frame(true, opBinary, nil, true, DefaultReadLimit+1)}, CloseMessageTooBig},
+		{"over ReadLimit", 10, [][]byte{frame(true, opBinary, nil, true, 11)}, CloseMessageTooBig},
+		{"fragments over ReadLimit", 10, [][]byte{
+			frame(false, opBinary, []byte("123456"), true, -1),
+			frame(false, opContinuation, []byte("7890"), true, -1),
+			frame(true, opContinuation, nil, true, 1),
+		}, CloseMessageTooBig},
+		{"length with the top bit set", 0, [][]byte{{0x80 | opBinary, 0x80 | 127, 0x80, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3, 4}}, CloseProtocolError},
+	}
+	for _, tt := range tests {
+		server, client := pair(t)
+		server.ReadLimit = tt.limit
+		for _, f := range tt.frames {
+			client.Write(f)
+		}
+		_, _, err := server.ReadMessage()
+		var ce *CloseError
+		if !errors.As(err, &ce) || ce.Code != tt.code {
+			t.Errorf("%s: got %v, want close %d", tt.name, err, tt.code)
+			continue
+		}
+		if got := closeCode(t, client); got != tt.code {
+			t.Errorf("%s: server sent close %d, want %d", tt.name, got, tt.code)
+		}
+		// Later reads keep reporting the failure.
+		if _, _, err2 := server.ReadMessage(); err2 != err {
+			t.Errorf("%s: second read got %v", tt.name, err2)
+		}
+	}
+}
+
+func TestReadLimitExact(t *testing.T) {
+	server, client := pair(t)
+	server.ReadLimit = 10
+	client.Write(frame(false, opBinary, []byte("12345"), true, -1))
+	client.Write(frame(true, opContinuation, []byte("67890"), true, -1))
+	if _, msg, err := server.ReadMessage(); err != nil || string(msg) != "1234567890" {
+		t.Errorf("got %q %v", msg, err)
+	}
+}
+
+func TestCloseHandshake(t *testing.T) {
+	var tests = []struct {
+		payload []byte
+		code    int
+		text    string
+		echo    int
+	}{
+		{closePayload(CloseNormal, "bye"), CloseNormal, "bye", CloseNormal},
+		{closePayload(CloseGoingAway, ""), CloseGoingAway, "", CloseGoingAway},
+		{closePayload(4000, "app"), 4000, "app", 4000},
+		{nil, CloseNoStatus, "", CloseNormal},
+	}
+	for _, tt := range tests {
+		server, client := pair(t)
+		client.Write(frame(true, opClose, tt.payload, true, -1))
+		_, _, err := server.ReadMessage()
+		var ce *CloseError
+		if !errors.As(err, &ce) || ce.Code != tt.code || ce.Text != tt.text {
+			t.Errorf("%q: got %v, want close %d %q", tt.payload, err, tt.code, tt.text)
+			continue
+		}
+		if got := closeCode(t, client); got != tt.echo {
+			t.Errorf("%q: echoed %d, want %d", tt.payload, got, tt.echo)
+		}
+		if err := server.WriteMessage(TextMessage, []byte("late")); err != ErrClosed {
+			t.Errorf("write after close: got %v, want ErrClosed", err)
+		}
+	}
+}
+
+func TestDropped(t *testing.T) {
+	server, client := pair(t)
+	client.Write(frame(false, opText, []byte("half"), true, -1))
+	client.Close()
+	_, _, err := server.ReadMessage()
+	var ce *CloseError
+	if !errors.As(err, &ce) || ce.Code != CloseAbnormal {
+		t.Errorf("got %v, want close %d", err, CloseAbnormal)
+	}
+}
+
+func TestWriteMessageFragments(t *testing.T) {
+	server, client := pair(t)
+	server.MaxFrameSize = 4
+	if err := server.WriteMessage(TextMessage, []byte("abcdefghij")); err != nil {
+		t.Fatal(err)
+	}
+	var ops []byte
+	var got []byte
+	for len(got) < 10 {
+		op, p := readServerFrame(t, client)
+		ops = append(ops, op)
+		got = append(got, p...)
+	}
+	if string(got) != "abcdefghij" || !bytes.Equal(ops, []byte{opText, opContinuation, opContinuation}) {
+		t.Errorf("got %q in frames %v", got, ops)
+	}
+	if err := server.WriteMessage(MessageType(opPing), nil); err == nil {
+		t.Error("WriteMessage accepted a control opcode")
+	}
+}
@@ -0,0 +1,235 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// acceptGUID is the fixed string section 1.3 appends to the client's key.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// acceptKey computes the Sec-WebSocket-Accept value for a client key.
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// HandshakeError is returned by Upgrade when the request is not a valid
+// WebSocket opening handshake. Upgrade has already answered the request with
+// Status by then.
+type HandshakeError struct {
+	Status int
+	Reason string
+}
+
+func (e *HandshakeError) Error() string {
+	return "websocket: handshake: " + e.Reason
+}
+
+// Upgrader turns HTTP requests into WebSocket connections.
+type Upgrader struct {
+	// CheckOrigin decides whether a browser on another origin may connect.
+	// When nil, only requests without an Origin header or whose Origin host
+	// matches the Host header are accepted.
+	CheckOrigin func(req *http.Request) bool
+	// Subprotocols lists the protocols the server speaks, in preference
+	// order. The first one the client also offers is selected.
+	Subprotocols []string
+	// ReadLimit is copied to every Conn this Upgrader creates. Zero means
+	// DefaultReadLimit; see Conn.ReadLimit.
+	ReadLimit int64
+}
+
+// Upgrade validates the opening handshake, writes the 101 response and
+// returns the hijacked connection. On failure it has already replied with an
+// HTTP error.
+func (u *Upgrader) Upgrade(w http.ResponseWriter, req *http.Request) (*Conn, error) {
+	reject := func(status int, reason string) (*Conn, error) {
+		http.Error(w, http.StatusText(status), status)
+		return nil, &HandshakeError{Status: status, Reason: reason}
+	}
+	if req.Method != http.MethodGet {
+		return reject(http.StatusMethodNotAllowed, "method is not GET")
+	}
+	if !headerHasToken(req.Header, "Connection", "upgrade") {
+		return reject(http.StatusBadRequest, "Connection header does not contain upgrade")
+	}
+	if !headerHasToken(req.Header, "Upgrade", "websocket") {
+		return reject(http.StatusBadRequest, "Upgrade header is not websocket")
+	}
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return reject(http.StatusUpgradeRequired, "unsupported version")
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 16 {
+		return reject(http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(req) {
+		return reject(http.StatusForbidden, "origin not allowed")
+	}
+
+	var protocol string
+	if offered := headerTokens(req.Header, "Sec-WebSocket-Protocol"); len(offered) > 0 {
+		for _, p := range u.Subprotocols {
+			if slices.Contains(offered, p) {
+				protocol = p
+				break
+			}
+		}
+	}
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return reject(http.StatusInternalServerError, "hijack: "+err.Error())
+	}
+	if brw.Reader.Buffered() > 0 {
+		// The client must wait for our 101 before sending frames.
+		netConn.Close()
+		return nil, &HandshakeError{Status: http.StatusBadRequest, Reason: "client sent data before handshake completed"}
+	}
+
+	var resp strings.Builder
+	resp.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	resp.WriteString("Upgrade: websocket\r\n")
+	resp.WriteString("Connection: Upgrade\r\n")
+	resp.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n")
+	if protocol != "" {
+		resp.WriteString("Sec-WebSocket-Protocol: " + protocol + "\r\n")
+	}
+	resp.WriteString("\r\n")
+	if _, err := netConn.Write([]byte(resp.String())); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	c := newConn(netConn, brw.Reader, true)
+	c.ReadLimit = u.ReadLimit
+	return c, nil
+}
+
+// Dial opens a client connection to a ws:// or wss:// URL. It exists mostly
+// so Go programs and tests can talk to an Upgrader-based server.
+func Dial(rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var useTLS bool
+	switch u.Scheme {
+	case "ws":
+	case "wss":
+		useTLS = true
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		if useTLS {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var netConn net.Conn
+	if useTLS {
+		netConn, err = tls.Dial("tcp", host, &tls.Config{ServerName: u.Hostname()})
+	} else {
+		netConn, err = net.Dial("tcp", host)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(raw[:])
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!headerHasToken(resp.Header, "Upgrade", "websocket") ||
+		!headerHasToken(resp.Header, "Connection", "upgrade") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		netConn.Close()
+		return nil, resp, errors.New("websocket: bad handshake response: " + resp.Status)
+	}
+	return newConn(netConn, br, false), resp, nil
+}
+
+func sameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, req.Host)
+}
+
+// headerTokens splits a comma-separated header into its trimmed tokens.
+func headerTokens(h http.Header, name string) []string {
+	var tokens []string
+	for _, line := range h.Values(name) {
+		for _, t := range strings.Split(line, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, t := range headerTokens(h, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echoServer upgrades every request with u and echoes messages back.
+func echoServer(t *testing.T, u *Upgrader) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := u.Upgrade(w, req)
+		if err != nil {
+			return
+		}
+		defer conn.Close(CloseNormal, "")
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(typ, msg)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example from section 1.3.
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDialEcho(t *testing.T) {
+	srv := echoServer(t, &Upgrader{Subprotocols: []string{"chat.v2", "chat.v1"}})
+	h := http.Header{"Sec-Websocket-Protocol": {"chat.v1, chat.v2"}}
+	conn, resp, err := Dial(wsURL(srv), h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(CloseNormal, "")
+	if p := resp.Header.Get("Sec-WebSocket-Protocol"); p != "chat.v2" {
+		t.Errorf("subprotocol %q, want the server's first choice chat.v2", p)
+	}
+	conn.MaxFrameSize = 3
+	for _, msg := range []string{"hello", "", strings.Repeat("z", 70000)} {
+		if err := conn.WriteMessage(TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		typ, got, err := conn.ReadMessage()
+		if err != nil || typ != TextMessage || string(got) != msg {
+			t.Errorf("echo of %.10q: got %d %.10q %v", msg, typ, got, err)
+		}
+	}
+	if err := conn.WritePing(make([]byte, 126)); err == nil {
+		t.Error("WritePing accepted 126 bytes")
+	}
+}
+
+func TestUpgradeRejects(t *testing.T) {
+	good := func() http.Header {
+		return http.Header{
+			"Connection":            {"keep-alive, Upgrade"},
+			"Upgrade":               {"websocket"},
+			"Sec-Websocket-Version": {"13"},
+			"Sec-Websocket-Key":     {"dGhlIHNhbXBsZSBub25jZQ=="},
+		}
+	}
+	var tests = []struct {
+		name   string
+		method string
+		edit   func(h http.Header)
+		status int
+	}{
+		{"POST", http.MethodPost, func(http.Header) {}, http.StatusMethodNotAllowed},
+		{"no Connection upgrade", "", func(h http.Header) { h.Set("Connection", "keep-alive") }, http.StatusBadRequest},
+		{"no Upgrade", "", func(h http.Header) { h.Del("Upgrade") }, http.StatusBadRequest},
+		{"old version", "", func(h http.Header) { h.Set("Sec-Websocket-Version", "8") }, http.StatusUpgradeRequired},
+		{"short key", "", func(h http.Header) { h.Set("Sec-Websocket-Key", "c2hvcnQ=") }, http.StatusBadRequest},
+		{"other origin", "", func(h http.Header) { h.Set("Origin", "http://evil.example") }, http.StatusForbidden},
+	}
+	var errs []error
+	u := &Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, err := u.Upgrade(w, req); err != nil {
+			errs = append(errs, err)
+		}
+	}))
+	defer srv.Close()
+	for _, tt := range tests {
+		h := good()
+		tt.edit(h)
+		method := tt.method
+		if method == "" {
+			method = http.MethodGet
+		}
+		req, _ := http.NewRequest(method, srv.URL, nil)
+		req.Header = h
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: got %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+	}
+	for _, err := range errs {
+		var he *HandshakeError
+		if !errors.As(err, &he) {
+			t.Errorf("got %T, want *HandshakeError", err)
+		}
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	srv := echoServer(t, &Upgrader{CheckOrigin: func(*http.Request) bool { return true }})
+	conn, _, err := Dial(wsURL(srv), http.Header{"Origin": {"http://elsewhere.example"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close(CloseNormal, "")
+}
@@ -0,0 +1,254 @@
+package websocket
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Client is a connection registered with a Hub. Messages sent to it are
+// queued and written by a dedicated goroutine, so a broadcast never waits on
+// a slow peer.
+type Client struct {
+	Conn *Conn
+
+	hub   *Hub
+	send  chan outgoing
+	done  chan struct{}
+	once  sync.Once
+	rooms map[string]struct{} // guarded by hub.mu
+}
+
+type outgoing struct {
+	typ  MessageType
+	data []byte
+}
+
+// Send queues a message for the client. It reports false, and disconnects
+// the client, when its queue is full.
+func (c *Client) Send(typ MessageType, data []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.send <- outgoing{typ, data}:
+		return true
+	default:
+		c.hub.remove(c, CloseGoingAway, "client too slow")
+		return false
+	}
+}
+
+// Rooms returns the rooms the client is currently in.
+func (c *Client) Rooms() []string {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for r := range c.rooms {
+		rooms = append(rooms, r)
+	}
+	return rooms
+}
+
+// Hub groups clients into named rooms and broadcasts messages to them.
+type Hub struct {
+	// OnMessage handles every data message a client sends. By default the
+	// message is broadcast to all the rooms the sender is in.
+	OnMessage func(c *Client, typ MessageType, data []byte)
+	// PingInterval is how often each client is pinged. A client that doesn't
+	// answer within two intervals is disconnected. Zero disables pings.
+	PingInterval time.Duration
+	// SendBuffer is the size of each client's outgoing queue.
+	SendBuffer int
+
+	mu      sync.Mutex
+	rooms   map[string]map[*Client]struct{}
+	clients map[*Client]struct{}
+}
+
+// NewHub returns an empty hub that pings its clients every 30 seconds.
+func NewHub() *Hub {
+	return &Hub{
+		PingInterval: 30 * time.Second,
+		SendBuffer:   32,
+		rooms:        make(map[string]map[*Client]struct{}),
+		clients:      make(map[*Client]struct{}),
+	}
+}
+
+// Register adds conn to the hub and starts its writer goroutine. Call Serve
+// afterwards to read from it.
+func (h *Hub) Register(conn *Conn) *Client {
+	c := &Client{
+		Conn:  conn,
+		hub:   h,
+		send:  make(chan outgoing, max(h.SendBuffer, 1)),
+		done:  make(chan struct{}),
+		rooms: make(map[string]struct{}),
+	}
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+	go h.writeLoop(c)
+	return c
+}
+
+// Join puts c in room.
+func (h *Hub) Join(c *Client, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	members := h.rooms[room]
+	if members == nil {
+		members = make(map[*Client]struct{})
+		h.rooms[room] = members
+	}
+	members[c] = struct{}{}
+	c.rooms[room] = struct{}{}
+}
+
+// Leave takes c out of room. Empty rooms are forgotten.
+func (h *Hub) Leave(c *Client, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leaveLocked(c, room)
+}
+
+func (h *Hub) leaveLocked(c *Client, room string) {
+	delete(c.rooms, room)
+	if members, ok := h.rooms[room]; ok {
+		delete(members, c)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Broadcast sends a message to everyone in room and returns how many clients
+// it was queued for.
+func (h *Hub) Broadcast(room string, typ MessageType, data []byte) int {
+	h.mu.Lock()
+	members := make([]*Client, 0, len(h.rooms[room]))
+	for c := range h.rooms[room] {
+		members = append(members, c)
+	}
+	h.mu.Unlock()
+
+	n := 0
+	for _, c := range members {
+		if c.Send(typ, data) {
+			n++
+		}
+	}
+	return n
+}
+
+// RoomSize returns the number of clients in room.
+func (h *Hub) RoomSize(room string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.rooms[room])
+}
+
+// Serve reads from c until the connection closes, passing each message to
+// OnMessage, and then removes c from the hub.
+func (h *Hub) Serve(c *Client) {
+	defer h.remove(c, CloseNormal, "")
+	if h.PingInterval > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(2 * h.PingInterval))
+		c.Conn.SetPongHandler(func([]byte) error {
+			return c.Conn.SetReadDeadline(time.Now().Add(2 * h.PingInterval))
+		})
+	}
+	for {
+		typ, data, err := c.Conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if h.OnMessage != nil {
+			h.OnMessage(c, typ, data)
+			continue
+		}
+		for _, room := range c.Rooms() {
+			h.Broadcast(room, typ, data)
+		}
+	}
+}
+
+// Handler upgrades each request and serves it as a hub client in the room
+// chosen by room (for example from a query parameter).
+func (h *Hub) Handler(u *Upgrader, room func(req *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := u.Upgrade(w, req)
+		if err != nil {
+			return
+		}
+		c := h.Register(conn)
+		if r := room(req); r != "" {
+			h.Join(c, r)
+		}
+		h.Serve(c)
+	})
+}
+
+// Close disconnects every client with 1001 Going Away.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+	for _, c := range clients {
+		h.remove(c, CloseGoingAway, "server shutting down")
+	}
+}
+
+// remove takes c out of every room, stops its writer and sends the close
+// frame from a goroutine of its own, so the caller doesn't wait on a
+// stalled peer. A one-second write deadline bounds that goroutine.
+func (h *Hub) remove(c *Client, code int, reason string) {
+	c.once.Do(func() {
+		h.mu.Lock()
+		for room := range c.rooms {
+			h.leaveLocked(c, room)
+		}
+		delete(h.clients, c)
+		h.mu.Unlock()
+
+		close(c.done)
+		go func() {
+			c.Conn.SetWriteDeadline(time.Now().Add(time.Second))
+			c.Conn.Close(code, reason)
+		}()
+	})
+}
+
+func (h *Hub) writeLoop(c *Client) {
+	var ping <-chan time.Time
+	if h.PingInterval > 0 {
+		t := time.NewTicker(h.PingInterval)
+		defer t.Stop()
+		ping = t.C
+	}
+	for {
+		select {
+		case m := <-c.send:
+			if err := c.Conn.WriteMessage(m.typ, m.data); err != nil {
+				h.remove(c, CloseAbnormal, "")
+				return
+			}
+		case <-ping:
+			if err := c.Conn.WritePing(nil); err != nil {
+				h.remove(c, CloseAbnormal, "")
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}